@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"task_scheduler/internal/services"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+// runBackup выполняет подкоманду "backup": создает резервную копию БД в файле,
+// указанном флагом -o.
+func runBackup(args []string) {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "", "path to the backup file (default scheduler-<timestamp>.tar.gz)")
+	flags.Parse(args)
+
+	path := *output
+	if path == "" {
+		path = fmt.Sprintf("scheduler-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	backupService := services.GetBackupService(storage.NewDatabaseConection(db))
+	if err := backupService.CreateBackup(path); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Backup created: %s\n", path)
+}
+
+// runRestore выполняет подкоманду "restore": заменяет содержимое БД данными
+// из резервной копии, переданной аргументом.
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: main restore <backup file>")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	backupService := services.GetBackupService(storage.NewDatabaseConection(db))
+	if err := backupService.RestoreBackup(flags.Arg(0)); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Backup restored: %s\n", flags.Arg(0))
+}
@@ -1,6 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"task_scheduler/internal/config"
 	"task_scheduler/internal/entities"
 	"task_scheduler/internal/handlers"
@@ -8,38 +17,178 @@ import (
 	"task_scheduler/internal/storage"
 	"time"
 
+	"github.com/jmoiron/sqlx"
+
 	"log"
 	"net/http"
 )
 
+const (
+	// leaderLockKey является ключом рекомендательной блокировки PostgreSQL для выбора лидера.
+	leaderLockKey = 0x7461736b
+	// eventReplaySize ограничивает количество последних событий, по которым можно возобновить поток событий.
+	eventReplaySize = 1000
+	// leaderCheckInterval задает периодичность попыток стать лидером и проверок блокировки лидера.
+	leaderCheckInterval = 5 * time.Second
+)
+
 func main() {
-	var store *storage.Storage
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backup":
+			runBackup(os.Args[2:])
+			return
+		case "restore":
+			runRestore(os.Args[2:])
+			return
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		default:
+			log.Fatalf("unknown command %q\n", os.Args[1])
+		}
+	}
 
-	switch config.Mode {
-	case "sqlite":
-		db, err := storage.NewSqliteStore(entities.DbFile)
+	db, err := openDatabase()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	store := storage.NewDatabaseConection(db)
+
+	// Уведомители taskNotifiers получают события изменения задач.
+	webhookService := services.GetWebhookService(store)
+	eventBroker := services.GetEventBroker(eventReplaySize)
+
+	// В режиме "postgres" события изменения задач пересылаются всем экземплярам через
+	// LISTEN/NOTIFY, а в режиме "sqlite" сразу публикуются в брокере экземпляра.
+	var (
+		eventNotifier services.Notifier = eventBroker
+		eventBridge   *services.EventBridge
+	)
+	if config.Mode == "postgres" {
+		eventBridge = services.GetEventBridge(store, eventBroker)
+		eventNotifier = eventBridge
+	}
+
+	taskNotifiers := []services.Notifier{webhookService, eventNotifier}
+
+	var chatNotifier *services.ChatNotifier
+	if config.ChatChannelsFile != "" {
+		chatNotifier = newChatNotifier()
+		taskNotifiers = append(taskNotifiers, chatNotifier)
+	}
+
+	taskService := services.GetTaskService(store, taskNotifiers...)
+	authService := services.GetAuthService()
+
+	// Фоновые задачи останавливаются по сигналу завершения, после чего main ожидает их окончания.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var background sync.WaitGroup
+	runBackground := func(run func(ctx context.Context)) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			run(ctx)
+		}()
+	}
+
+	// Фоновая работа по расписанию выполняется только экземпляром-лидером, чтобы при
+	// нескольких экземплярах, работающих с одной БД, она не выполнялась повторно.
+	var leaderWork []func(ctx context.Context)
+	runLeader := func(run func(ctx context.Context)) {
+		leaderWork = append(leaderWork, run)
+	}
+
+	if eventBridge != nil {
+		runBackground(func(ctx context.Context) {
+			eventBridge.Run(ctx, 5*time.Second)
+		})
+	}
+
+	trashRetention, err := parsePositive(config.TrashRetention, 30)
+	if err != nil {
+		log.Fatalf("invalid TRASH_RETENTION_DAYS value: %q\n", config.TrashRetention)
+	}
+	runLeader(func(ctx context.Context) {
+		taskService.RunTrashPurge(ctx, time.Duration(trashRetention)*24*time.Hour, time.Hour)
+	})
+
+	dispatchInterval, err := parsePositive(config.DispatchInterval, 60)
+	if err != nil {
+		log.Fatalf("invalid DISPATCH_INTERVAL_SECONDS value: %q\n", config.DispatchInterval)
+	}
+
+	runLeader(func(ctx context.Context) {
+		webhookService.Run(ctx, 10*time.Second)
+	})
+
+	// Уведомители notifiers получают события наступления дат задач и напоминания.
+	notifiers := []services.Notifier{services.LogNotifier{}, webhookService}
+
+	if chatNotifier != nil {
+		notifiers = append(notifiers, chatNotifier)
+		runBackground(chatNotifier.Run)
+	}
+
+	if config.SMTPHost != "" {
+		emailNotifier := newEmailNotifier()
+		notifiers = append(notifiers, emailNotifier)
+
+		if config.DigestTime != "" {
+			digestTime, err := time.Parse("15:04", config.DigestTime)
+			if err != nil {
+				log.Fatalf("invalid DIGEST_TIME value: %q\n", config.DigestTime)
+			}
+
+			runLeader(func(ctx context.Context) {
+				emailNotifier.RunDigest(ctx, taskService, time.Duration(digestTime.Hour())*time.Hour+time.Duration(digestTime.Minute())*time.Minute)
+			})
+		}
+	}
+
+	// Действия задач выполняются, только если это разрешено переменной JOBS_ENABLED.
+	jobConfig := newJobConfig()
+	jobService := services.GetJobService(store, taskService, jobConfig)
+	if jobConfig.Enabled {
+		notifiers = append(notifiers, jobService)
+		runBackground(jobService.Run)
+	}
+
+	dispatcher := services.GetDispatcher(store, notifiers...)
+	runLeader(func(ctx context.Context) {
+		dispatcher.Run(ctx, time.Duration(dispatchInterval)*time.Second)
+	})
+
+	reminderScheduler := services.GetReminderScheduler(store, notifiers...)
+	runLeader(func(ctx context.Context) {
+		reminderScheduler.Run(ctx, time.Second, 30*time.Second)
+	})
+
+	if config.BackupDir != "" {
+		backupInterval, err := parsePositive(config.BackupInterval, 24)
 		if err != nil {
-			log.Fatal(err.Error())
+			log.Fatalf("invalid BACKUP_INTERVAL_HOURS value: %q\n", config.BackupInterval)
 		}
-		defer db.Close()
 
-		store = storage.NewDatabaseConection(db)
-		log.Println("Using SQLite storage")
-	case "postgres":
-		db, err := storage.NewPostgresStore(config.PsqlUrl)
+		backupKeep, err := parsePositive(config.BackupKeep, 7)
 		if err != nil {
-			log.Fatal(err.Error())
+			log.Fatalf("invalid BACKUP_KEEP value: %q\n", config.BackupKeep)
 		}
-		defer db.Close()
 
-		store = storage.NewDatabaseConection(db)
-		log.Println("Using PostgreSQL store")
-	default:
-		log.Fatalf("config.Mode is empty in /internal/config/setting.go")
+		backupService := services.GetBackupService(store)
+		runLeader(func(ctx context.Context) {
+			backupService.RunScheduledBackup(ctx, config.BackupDir, time.Duration(backupInterval)*time.Hour, backupKeep)
+		})
 	}
 
-	taskService := services.GetTaskService(store)
-	authService := services.GetAuthService()
+	elector := services.GetLeaderElector(newLeaderLock(db), leaderCheckInterval)
+	runBackground(func(ctx context.Context) {
+		elector.Run(ctx, leaderWork...)
+	})
 
 	mux := http.NewServeMux()
 
@@ -48,7 +197,41 @@ func main() {
 	mux.HandleFunc("GET /api/nextdate", handlers.GetNextDate(taskService))
 	mux.HandleFunc("/api/task", services.CheckJWTMiddleware(handlers.UpdateTasks(taskService)))
 	mux.HandleFunc("GET /api/tasks", services.CheckJWTMiddleware(handlers.GetTasks(taskService)))
+	mux.HandleFunc("GET /api/tasks/overdue", services.CheckJWTMiddleware(handlers.GetOverdueTasks(taskService)))
+	mux.HandleFunc("GET /api/agenda", services.CheckJWTMiddleware(handlers.GetAgenda(taskService)))
+	mux.HandleFunc("GET /api/calendar", services.CheckJWTMiddleware(handlers.GetCalendar(taskService)))
+	mux.HandleFunc("GET /api/calendar.ics", services.CheckFeedTokenMiddleware(taskService, handlers.GetCalendarFeed(taskService)))
+	mux.HandleFunc("POST /api/calendar/token", services.CheckJWTMiddleware(handlers.CreateFeedToken(taskService)))
 	mux.HandleFunc("POST /api/task/done", services.CheckJWTMiddleware(handlers.DoneTask(taskService)))
+	mux.HandleFunc("GET /api/task/history", services.CheckJWTMiddleware(handlers.GetHistory(taskService)))
+	mux.HandleFunc("POST /api/task/revert", services.CheckJWTMiddleware(handlers.RevertTask(taskService)))
+	mux.HandleFunc("GET /api/task/reminders", services.CheckJWTMiddleware(handlers.GetReminders(taskService)))
+	mux.HandleFunc("PUT /api/task/reminders", services.CheckJWTMiddleware(handlers.SetReminders(taskService)))
+	mux.HandleFunc("GET /api/reminders", services.CheckJWTMiddleware(handlers.GetPendingReminders(taskService)))
+	mux.HandleFunc("GET /api/webhooks", services.CheckJWTMiddleware(handlers.GetWebhooks(webhookService)))
+	mux.HandleFunc("POST /api/webhooks", services.CheckJWTMiddleware(handlers.AddWebhook(webhookService)))
+	mux.HandleFunc("DELETE /api/webhooks", services.CheckJWTMiddleware(handlers.DeleteWebhook(webhookService)))
+	mux.HandleFunc("GET /api/webhooks/deliveries", services.CheckJWTMiddleware(handlers.GetDeliveries(webhookService)))
+	mux.HandleFunc("POST /api/webhooks/redeliver", services.CheckJWTMiddleware(handlers.Redeliver(webhookService)))
+	mux.HandleFunc("GET /api/task/job", services.CheckJWTMiddleware(handlers.GetJob(jobService)))
+	mux.HandleFunc("PUT /api/task/job", services.CheckJWTMiddleware(handlers.SetJob(jobService)))
+	mux.HandleFunc("DELETE /api/task/job", services.CheckJWTMiddleware(handlers.DeleteJob(jobService)))
+	mux.HandleFunc("GET /api/task/job/runs", services.CheckJWTMiddleware(handlers.GetJobRuns(jobService)))
+	mux.HandleFunc("GET /api/events", services.CheckJWTMiddleware(handlers.GetEvents(eventBroker)))
+	mux.HandleFunc("POST /api/undo", services.CheckJWTMiddleware(handlers.Undo(taskService)))
+	mux.HandleFunc("GET /api/export", services.CheckJWTMiddleware(handlers.Export(taskService)))
+	mux.HandleFunc("POST /api/import", services.CheckJWTMiddleware(handlers.Import(taskService, jobService)))
+	mux.HandleFunc("POST /api/import/csv", services.CheckJWTMiddleware(handlers.ImportCSV(taskService)))
+	mux.HandleFunc("POST /api/import/ics", services.CheckJWTMiddleware(handlers.ImportICS(taskService)))
+	mux.HandleFunc("GET /api/export/{format}", services.CheckJWTMiddleware(handlers.ExportText(taskService)))
+	mux.HandleFunc("POST /api/import/{format}", services.CheckJWTMiddleware(handlers.ImportText(taskService)))
+	mux.HandleFunc("POST /api/import/from/{source}", services.CheckJWTMiddleware(handlers.ImportFromApp(taskService)))
+	mux.HandleFunc("GET /api/completed", services.CheckJWTMiddleware(handlers.GetCompletions(taskService)))
+	mux.HandleFunc("GET /api/trash", services.CheckJWTMiddleware(handlers.GetTrash(taskService)))
+	mux.HandleFunc("POST /api/trash/restore", services.CheckJWTMiddleware(handlers.RestoreTask(taskService)))
+	mux.HandleFunc("DELETE /api/trash", services.CheckJWTMiddleware(handlers.PurgeTask(taskService)))
+	mux.HandleFunc(handlers.CalDAVRoot, services.CheckBasicAuthMiddleware(handlers.CalDAV(taskService)))
+	mux.Handle("/.well-known/caldav", http.RedirectHandler(handlers.CalDAVRoot, http.StatusMovedPermanently))
 	mux.HandleFunc("POST /api/signin", handlers.Authentication(authService))
 
 	serv := &http.Server{
@@ -58,8 +241,170 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	serv.RegisterOnShutdown(eventBroker.Close)
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := serv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("failed to shut down the server: %s\n", err.Error())
+		}
+	}()
+
 	log.Println("Scheduler is running ...")
-	if err := serv.ListenAndServe(); err != nil {
+	if err := serv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("error when starting the server: %s\n", err.Error())
 	}
+
+	log.Println("Shutting down ...")
+	background.Wait()
+}
+
+// newLeaderLock возвращает блокировку выбора лидера: рекомендательную блокировку
+// PostgreSQL в режиме "postgres" или блокировку файла рядом с файлом БД в режиме "sqlite".
+func newLeaderLock(db *sqlx.DB) storage.Locker {
+	if config.Mode == "postgres" {
+		return storage.NewAdvisoryLock(db, leaderLockKey)
+	}
+
+	return storage.NewFileLock(entities.DbFile + ".lock")
+}
+
+// openDatabase открывает БД в режиме, заданном config.Mode.
+func openDatabase() (*sqlx.DB, error) {
+	return openDatabaseMode(config.Mode, entities.DbFile, config.PsqlUrl)
+}
+
+// openDatabaseMode открывает БД в режиме mode: файл sqliteFile в режиме "sqlite"
+// или БД по адресу psqlUrl в режиме "postgres".
+func openDatabaseMode(mode, sqliteFile, psqlUrl string) (*sqlx.DB, error) {
+	switch mode {
+	case "sqlite":
+		db, err := storage.NewSqliteStore(sqliteFile)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Println("Using SQLite storage")
+		return db, nil
+	case "postgres":
+		db, err := storage.NewPostgresStore(psqlUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Println("Using PostgreSQL store")
+		return db, nil
+	case "":
+		return nil, errors.New("config.Mode is empty in /internal/config/setting.go")
+	default:
+		return nil, fmt.Errorf("unknown database mode %q", mode)
+	}
+}
+
+// newChatNotifier возвращает уведомитель, отправляющий сообщения в каналы чатов
+// из файла CHAT_CHANNELS_FILE.
+func newChatNotifier() *services.ChatNotifier {
+	f, err := os.Open(config.ChatChannelsFile)
+	if err != nil {
+		log.Fatalf("failed to open chat channels file: %s\n", err.Error())
+	}
+	defer f.Close()
+
+	channels, err := services.ReadChatChannels(f)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	notifier, err := services.GetChatNotifier(channels)
+	if err != nil {
+		log.Fatalf("invalid chat channels: %s\n", err.Error())
+	}
+
+	return notifier
+}
+
+// newEmailNotifier возвращает уведомитель, отправляющий письма с параметрами из переменных окружения SMTP_*.
+func newEmailNotifier() *services.EmailNotifier {
+	port, err := parsePositive(config.SMTPPort, 587)
+	if err != nil {
+		log.Fatalf("invalid SMTP_PORT value: %q\n", config.SMTPPort)
+	}
+
+	var to []string
+	for _, addr := range strings.Split(config.SMTPTo, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+
+	notifier, err := services.GetEmailNotifier(services.SMTPConfig{
+		Host:     config.SMTPHost,
+		Port:     port,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.SMTPFrom,
+		To:       to,
+		TLS:      config.SMTPTLS,
+		Lang:     config.EmailLang,
+	})
+	if err != nil {
+		log.Fatalf("invalid SMTP configuration: %s\n", err.Error())
+	}
+
+	return notifier
+}
+
+// newJobConfig возвращает параметры выполнения действий задач из переменных окружения JOBS_*.
+func newJobConfig() services.JobConfig {
+	var (
+		cfg services.JobConfig
+		err error
+	)
+
+	if config.JobsEnabled != "" {
+		cfg.Enabled, err = strconv.ParseBool(config.JobsEnabled)
+		if err != nil {
+			log.Fatalf("invalid JOBS_ENABLED value: %q\n", config.JobsEnabled)
+		}
+
+		// Без пароля API доступно без аутентификации, поэтому действия задач
+		// позволили бы любому клиенту выполнять команды на сервере.
+		if cfg.Enabled && config.Password == "" {
+			log.Fatalf("JOBS_ENABLED requires PASSWORD to be set\n")
+		}
+	}
+
+	cfg.Concurrency, err = parsePositive(config.JobsConcurrency, 2)
+	if err != nil {
+		log.Fatalf("invalid JOBS_CONCURRENCY value: %q\n", config.JobsConcurrency)
+	}
+
+	timeout, err := parsePositive(config.JobsTimeout, 60)
+	if err != nil {
+		log.Fatalf("invalid JOBS_TIMEOUT_SECONDS value: %q\n", config.JobsTimeout)
+	}
+
+	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg.Shell = config.JobsShell
+
+	return cfg
+}
+
+// parsePositive возвращает целое положительное значение переменной окружения value
+// или def, если переменная не задана.
+func parsePositive(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, errors.New("the value must be a positive integer")
+	}
+
+	return n, nil
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"task_scheduler/internal/config"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+)
+
+// runMigrate выполняет подкоманду "migrate": переносит данные из БД режима -from
+// в БД режима -to с сохранением идентификаторов.
+func runMigrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := flags.String("from", "", `source database mode: "sqlite" or "postgres"`)
+	to := flags.String("to", "", `target database mode: "sqlite" or "postgres"`)
+	sqliteFile := flags.String("sqlite", entities.DbFile, "path to the SQLite database file")
+	psqlUrl := flags.String("postgres", config.PsqlUrl, "PostgreSQL connection URL (default DATABASE_URL)")
+	replace := flags.Bool("replace", false, "replace data in the target database if it is not empty")
+	dryRun := flags.Bool("dry-run", false, "copy and verify the data, then roll back the target database")
+	flags.Parse(args)
+
+	if *from == "" || *to == "" || *from == *to {
+		fmt.Fprintln(os.Stderr, "Usage: main migrate -from <mode> -to <mode> [flags]")
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+
+	srcDb, err := openDatabaseMode(*from, *sqliteFile, *psqlUrl)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer srcDb.Close()
+
+	dstDb, err := openDatabaseMode(*to, *sqliteFile, *psqlUrl)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer dstDb.Close()
+
+	report, err := storage.Migrate(storage.NewDatabaseConection(srcDb), storage.NewDatabaseConection(dstDb), *replace, *dryRun)
+	if err != nil {
+		log.Fatalf("migration failed: %s\n", err.Error())
+	}
+
+	tables := make([]string, 0, len(report.Source))
+	for table := range report.Source {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		log.Printf("%s: %d row(s) read, %d row(s) written\n", table, report.Source[table], report.Target[table])
+	}
+
+	if report.DryRun {
+		log.Println("Dry run: the target database was not changed")
+		return
+	}
+
+	log.Printf("Migration from %s to %s completed\n", *from, *to)
+}
@@ -3,8 +3,27 @@ package config
 import "os"
 
 var (
-	Port     = os.Getenv("PORT")
-	Mode     = os.Getenv("MODE")
-	PsqlUrl  = os.Getenv("DATABASE_URL")
-	Password = os.Getenv("PASSWORD")
+	Port             = os.Getenv("PORT")
+	Mode             = os.Getenv("MODE")
+	PsqlUrl          = os.Getenv("DATABASE_URL")
+	Password         = os.Getenv("PASSWORD")
+	TrashRetention   = os.Getenv("TRASH_RETENTION_DAYS")
+	BackupDir        = os.Getenv("BACKUP_DIR")
+	BackupInterval   = os.Getenv("BACKUP_INTERVAL_HOURS")
+	BackupKeep       = os.Getenv("BACKUP_KEEP")
+	DispatchInterval = os.Getenv("DISPATCH_INTERVAL_SECONDS")
+	SMTPHost         = os.Getenv("SMTP_HOST")
+	SMTPPort         = os.Getenv("SMTP_PORT")
+	SMTPUsername     = os.Getenv("SMTP_USERNAME")
+	SMTPPassword     = os.Getenv("SMTP_PASSWORD")
+	SMTPFrom         = os.Getenv("SMTP_FROM")
+	SMTPTo           = os.Getenv("SMTP_TO")
+	SMTPTLS          = os.Getenv("SMTP_TLS")
+	EmailLang        = os.Getenv("EMAIL_LANG")
+	DigestTime       = os.Getenv("DIGEST_TIME")
+	ChatChannelsFile = os.Getenv("CHAT_CHANNELS_FILE")
+	JobsEnabled      = os.Getenv("JOBS_ENABLED")
+	JobsConcurrency  = os.Getenv("JOBS_CONCURRENCY")
+	JobsTimeout      = os.Getenv("JOBS_TIMEOUT_SECONDS")
+	JobsShell        = os.Getenv("JOBS_SHELL")
 )
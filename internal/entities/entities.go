@@ -1,5 +1,13 @@
 package entities
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Task является структурой задачи.
 type Task struct {
 	Id      string `json:"id,omitempty" db:"id"`
@@ -7,14 +15,443 @@ type Task struct {
 	Title   string `json:"title,omitempty" db:"title"`
 	Comment string `json:"comment,omitempty" db:"comment"`
 	Repeat  string `json:"repeat,omitempty" db:"repeat"`
+	Version int    `json:"version,omitempty" db:"version"`
+}
+
+// TrashedTask является структурой задачи, находящейся в корзине.
+// DeletedAt содержит время удаления задачи в формате Unix.
+type TrashedTask struct {
+	Task
+	DeletedAt int64 `json:"deleted_at" db:"deleted_at"`
+}
+
+// Completion является структурой записи о выполнении задачи.
+// Title и Date содержат название и запланированную дату задачи на момент выполнения,
+// а CompletedAt - время выполнения в формате Unix.
+type Completion struct {
+	Id          string `json:"id" db:"id"`
+	TaskId      string `json:"task_id" db:"task_id"`
+	Title       string `json:"title" db:"title"`
+	Date        string `json:"date" db:"date"`
+	CompletedAt int64  `json:"completed_at" db:"completed_at"`
+}
+
+// Действия над задачами, сохраняемые в истории изменений.
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionComplete = "complete"
+	ActionRestore  = "restore"
+	ActionRevert   = "revert"
+	ActionUndo     = "undo"
+	ActionImport   = "import"
+)
+
+// TaskSnapshot является снимком задачи, который хранится в истории изменений в формате JSON.
+type TaskSnapshot Task
+
+// Value сериализует снимок задачи в JSON для записи в БД.
+func (t TaskSnapshot) Value() (driver.Value, error) {
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+// Scan десериализует снимок задачи из JSON, прочитанного из БД.
+func (t *TaskSnapshot) Scan(src any) error {
+	switch data := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(data), t)
+	case []byte:
+		return json.Unmarshal(data, t)
+	default:
+		return fmt.Errorf("unsupported task snapshot type %T", src)
+	}
+}
+
+// Revision является структурой неизменяемой записи истории изменений задачи.
+// Before и After содержат состояние задачи до и после изменения, Changes - список
+// измененных полей, а CreatedAt - время изменения в формате Unix.
+type Revision struct {
+	Id        string        `json:"id" db:"id"`
+	TaskId    string        `json:"task_id" db:"task_id"`
+	Action    string        `json:"action" db:"action"`
+	Actor     string        `json:"actor" db:"actor"`
+	CreatedAt int64         `json:"created_at" db:"created_at"`
+	Before    *TaskSnapshot `json:"before,omitempty" db:"before_state"`
+	After     *TaskSnapshot `json:"after,omitempty" db:"after_state"`
+	Changes   []FieldChange `json:"changes,omitempty" db:"-"`
+}
+
+// FieldChange является структурой изменения одного поля задачи.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Day является структурой задач, сгруппированных по одной дате.
+type Day struct {
+	Date  string `json:"date"`
+	Tasks []Task `json:"tasks"`
+}
+
+// Occurrence является структурой вхождения задачи в календарь.
+// Projected указывает, что вхождение вычислено по правилу повторения и не хранится в БД.
+type Occurrence struct {
+	Date      string `json:"date"`
+	Projected bool   `json:"projected"`
+	Task      Task   `json:"task"`
+}
+
+// Формат и версия документа экспорта задач.
+const (
+	ExportFormat  = "task_scheduler-export"
+	ExportVersion = 1
+)
+
+// Export является структурой документа полного экспорта задач.
+// ExportedAt содержит время экспорта в формате Unix, а Instance - идентификатор БД,
+// из которой экспортированы задачи.
+type Export struct {
+	Format      string       `json:"format"`
+	Version     int          `json:"version"`
+	ExportedAt  int64        `json:"exported_at"`
+	Instance    string       `json:"instance,omitempty"`
+	Tasks       []Task       `json:"tasks"`
+	Completions []Completion `json:"completions,omitempty"`
+	History     []Revision   `json:"history,omitempty"`
+	Reminders   []Reminder   `json:"reminders,omitempty"`
+	Jobs        []Job        `json:"jobs,omitempty"`
+}
+
+// Режимы импорта задач.
+const (
+	// ImportMerge добавляет новые задачи и обновляет задачи с совпадающим id, если документ
+	// экспортирован из этой же БД. Задачи из других БД, совпадающие с существующими
+	// по содержимому, пропускаются.
+	ImportMerge = "merge"
+	// ImportReplace перемещает все существующие задачи в корзину и добавляет задачи из документа.
+	ImportReplace = "replace"
+	// ImportSkipDuplicates добавляет только задачи, которых еще нет в БД.
+	ImportSkipDuplicates = "skip-duplicates"
+)
+
+// События задач, о которых сообщается уведомителям.
+const (
+	// EventTaskDue является событием наступления даты задачи.
+	EventTaskDue = "task.due"
+	// EventReminder является событием напоминания о задаче.
+	EventReminder = "task.reminder"
+	// EventTaskCreated является событием добавления задачи.
+	EventTaskCreated = "task.created"
+	// EventTaskUpdated является событием изменения задачи.
+	EventTaskUpdated = "task.updated"
+	// EventTaskDone является событием выполнения задачи.
+	EventTaskDone = "task.done"
+	// EventTaskDeleted является событием удаления задачи.
+	EventTaskDeleted = "task.deleted"
+)
+
+// Event является структурой события задачи, о котором сообщается уведомителям.
+// FiredAt содержит время события в формате Unix, Reminder - напоминание,
+// вызвавшее событие entities.EventReminder, а Actor - инициатора изменения задачи.
+type Event struct {
+	Type     string    `json:"type"`
+	Task     Task      `json:"task"`
+	Reminder *Reminder `json:"reminder,omitempty"`
+	Actor    string    `json:"actor,omitempty"`
+	FiredAt  int64     `json:"fired_at"`
+}
+
+// EventTypes является списком типов событий, который хранится в БД через запятую.
+type EventTypes []string
+
+// Value сериализует список типов событий для записи в БД.
+func (e EventTypes) Value() (driver.Value, error) {
+	return strings.Join(e, ","), nil
+}
+
+// Scan десериализует список типов событий, прочитанный из БД.
+func (e *EventTypes) Scan(src any) error {
+	var data string
+
+	switch value := src.(type) {
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		return fmt.Errorf("unsupported event types type %T", src)
+	}
+
+	*e = EventTypes{}
+	if data != "" {
+		*e = strings.Split(data, ",")
+	}
+
+	return nil
+}
+
+// Webhook является структурой подписки на события задач. События отправляются
+// на адрес Url в теле POST запроса, подписанного ключом Secret. Пустой список
+// Events означает подписку на все события. CreatedAt содержит время создания в формате Unix.
+type Webhook struct {
+	Id        string     `json:"id" db:"id"`
+	Url       string     `json:"url" db:"url"`
+	Secret    string     `json:"secret,omitempty" db:"secret"`
+	Events    EventTypes `json:"events" db:"events"`
+	CreatedAt int64      `json:"created_at" db:"created_at"`
+}
+
+// Payload является телом события в формате JSON, которое хранится в БД в виде текста.
+type Payload []byte
+
+// MarshalJSON возвращает тело события без изменений.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+
+	return p, nil
+}
+
+// UnmarshalJSON сохраняет копию тела события data.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	*p = append((*p)[:0], data...)
+	return nil
+}
+
+// Value возвращает тело события в виде текста для записи в БД.
+func (p Payload) Value() (driver.Value, error) {
+	return string(p), nil
+}
+
+// Scan читает тело события из БД.
+func (p *Payload) Scan(src any) error {
+	switch data := src.(type) {
+	case string:
+		*p = Payload(data)
+	case []byte:
+		*p = append(Payload{}, data...)
+	default:
+		return fmt.Errorf("unsupported payload type %T", src)
+	}
+
+	return nil
+}
+
+// Состояния доставки события подписке.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery является структурой записи журнала доставки события подписке.
+// Attempts содержит количество выполненных попыток, NextAttemptAt - время следующей
+// попытки, ResponseCode и LastError - результат последней попытки, а CreatedAt
+// и DeliveredAt - время создания и успешной доставки в формате Unix.
+type Delivery struct {
+	Id            string  `json:"id" db:"id"`
+	WebhookId     string  `json:"webhook_id" db:"webhook_id"`
+	Event         string  `json:"event" db:"event"`
+	Payload       Payload `json:"payload" db:"payload"`
+	Status        string  `json:"status" db:"status"`
+	Attempts      int     `json:"attempts" db:"attempts"`
+	NextAttemptAt int64   `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseCode  int     `json:"response_code,omitempty" db:"response_code"`
+	LastError     string  `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     int64   `json:"created_at" db:"created_at"`
+	DeliveredAt   *int64  `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// Reminder является структурой напоминания о задаче. Rule содержит правило напоминания
+// ("1 day before", "on the day at 09:00", "2 hours before"), Offset - смещение времени
+// напоминания в минутах от начала дня задачи, а FireAt и FiredAt - время срабатывания
+// и отправки напоминания в формате Unix. Title и Date заполняются в списке ожидающих напоминаний.
+type Reminder struct {
+	Id      string `json:"id" db:"id"`
+	TaskId  string `json:"task_id" db:"task_id"`
+	Rule    string `json:"rule" db:"rule"`
+	Offset  int    `json:"offset" db:"offset_minutes"`
+	FireAt  int64  `json:"fire_at" db:"fire_at"`
+	FiredAt *int64 `json:"fired_at,omitempty" db:"fired_at"`
+	Title   string `json:"title,omitempty" db:"title"`
+	Date    string `json:"date,omitempty" db:"date"`
+}
+
+// Типы действий задач.
+const (
+	// JobCommand выполняет команду оболочки.
+	JobCommand = "command"
+	// JobHTTP отправляет HTTP запрос.
+	JobHTTP = "http"
+)
+
+// Job является структурой действия, которое выполняется при наступлении даты задачи TaskId.
+// Для типа JobCommand Command содержит команду оболочки, а для типа JobHTTP Method, Url,
+// Headers и Body описывают запрос. Timeout ограничивает время выполнения в секундах
+// (0 означает ограничение по умолчанию).
+type Job struct {
+	Id      string     `json:"-" db:"id"`
+	TaskId  string     `json:"task_id" db:"task_id"`
+	Type    string     `json:"type" db:"type"`
+	Command string     `json:"command,omitempty" db:"command"`
+	Method  string     `json:"method,omitempty" db:"method"`
+	Url     string     `json:"url,omitempty" db:"url"`
+	Headers JobHeaders `json:"headers,omitempty" db:"headers"`
+	Body    string     `json:"body,omitempty" db:"body"`
+	Timeout int        `json:"timeout,omitempty" db:"timeout_seconds"`
+}
+
+// JobHeaders является набором заголовков HTTP запроса действия, который хранится в БД в формате JSON.
+type JobHeaders map[string]string
+
+// Value сериализует заголовки для записи в БД.
+func (h JobHeaders) Value() (driver.Value, error) {
+	if len(h) == 0 {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(map[string]string(h))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan десериализует заголовки, прочитанные из БД.
+func (h *JobHeaders) Scan(src any) error {
+	var data []byte
+
+	switch value := src.(type) {
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		return fmt.Errorf("unsupported job headers type %T", src)
+	}
+
+	*h = nil
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, h)
+}
+
+// Состояния запуска действия задачи.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobTimedOut  = "timed_out"
+)
+
+// JobRun является структурой записи журнала запусков действия задачи TaskId на дату Date.
+// Для команды ExitCode содержит код завершения, а Stdout и Stderr - ее вывод; для HTTP
+// запроса StatusCode содержит статус ответа, а Stdout - тело ответа. Error описывает
+// ошибку запуска, а QueuedAt, StartedAt и FinishedAt - время в формате Unix.
+type JobRun struct {
+	Id         string `json:"id" db:"id"`
+	TaskId     string `json:"task_id" db:"task_id"`
+	Date       string `json:"date" db:"date"`
+	Type       string `json:"type" db:"type"`
+	Status     string `json:"status" db:"status"`
+	ExitCode   *int   `json:"exit_code,omitempty" db:"exit_code"`
+	StatusCode int    `json:"status_code,omitempty" db:"status_code"`
+	Stdout     string `json:"stdout" db:"stdout"`
+	Stderr     string `json:"stderr" db:"stderr"`
+	Error      string `json:"error,omitempty" db:"error"`
+	QueuedAt   int64  `json:"queued_at" db:"queued_at"`
+	StartedAt  *int64 `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt *int64 `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// Форматы текстовых списков задач для экспорта и импорта.
+const (
+	// TextTodoTxt является форматом todo.txt: одна задача в строке.
+	TextTodoTxt = "todotxt"
+	// TextMarkdown является списком задач Markdown ("- [ ] задача").
+	TextMarkdown = "markdown"
+)
+
+// Приложения, файлы экспорта которых можно импортировать.
+const (
+	// SourceTodoist является файлом CSV, экспортированным из проекта Todoist.
+	SourceTodoist = "todoist"
+	// SourceTrello является файлом JSON, экспортированным из доски Trello.
+	SourceTrello = "trello"
+	// SourceMSToDo является файлом JSON с задачами Microsoft To Do в формате Microsoft Graph.
+	SourceMSToDo = "mstodo"
+)
+
+// ImportReport является структурой отчета об импорте задач.
+// При пробном импорте (DryRun) задачи не сохраняются, Created содержит количество задач,
+// которые были бы добавлены, а Preview - сами эти задачи.
+// Warnings содержит данные импортированных задач, которые не удалось перенести
+// (например, правила повторения, не выразимые правилом повторения задачи).
+// TaskIds сопоставляет id задач документа полного экспорта с id созданных из них задач
+// и в ответ не включается.
+type ImportReport struct {
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Skipped  int               `json:"skipped"`
+	Errors   []ImportError     `json:"errors"`
+	Warnings []ImportError     `json:"warnings,omitempty"`
+	DryRun   bool              `json:"dry_run,omitempty"`
+	Preview  []Task            `json:"preview,omitempty"`
+	TaskIds  map[string]string `json:"-"`
+}
+
+// ImportError является структурой ошибки импорта одной записи.
+// Index содержит номер записи в документе, начиная с нуля, а Line - номер строки
+// в файле CSV, включая строку заголовка.
+type ImportError struct {
+	Index int    `json:"index"`
+	Line  int    `json:"line,omitempty"`
+	Id    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// CalDAVResource является структурой ресурса CalDAV, созданного клиентом.
+// Name содержит имя ресурса в коллекции задач, а UID - идентификатор компонента VTODO.
+type CalDAVResource struct {
+	Id     string `json:"id" db:"id"`
+	Name   string `json:"name" db:"name"`
+	TaskId string `json:"task_id" db:"task_id"`
+	UID    string `json:"uid" db:"uid"`
+}
+
+// CalDAVObject является структурой задачи, представленной ресурсом коллекции CalDAV.
+// Data содержит задачу в формате iCalendar в виде компонента VTODO с идентификатором UID.
+type CalDAVObject struct {
+	Name string
+	UID  string
+	Task Task
+	Data string
 }
 
 // Result является структурой необходимой для сериализации http ответа сервера.
 type Result struct {
-	Tasks []Task `json:"tasks,omitempty"`
-	Id    string `json:"id,omitempty"`
-	Error string `json:"error,omitempty"`
-	Token string `json:"token,omitempty"`
+	Tasks       []Task        `json:"tasks,omitempty"`
+	Days        []Day         `json:"days,omitempty"`
+	Occurrences []Occurrence  `json:"occurrences,omitempty"`
+	Trash       []TrashedTask `json:"trash,omitempty"`
+	Completions []Completion  `json:"completions,omitempty"`
+	Revisions   []Revision    `json:"revisions,omitempty"`
+	Reminders   []Reminder    `json:"reminders,omitempty"`
+	Webhooks    []Webhook     `json:"webhooks,omitempty"`
+	Deliveries  []Delivery    `json:"deliveries,omitempty"`
+	Runs        []JobRun      `json:"runs,omitempty"`
+	Import      *ImportReport `json:"import,omitempty"`
+	Id          string        `json:"id,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Token       string        `json:"token,omitempty"`
 }
 
 var (
@@ -22,3 +459,44 @@ var (
 	UiDir     = "ui"
 	DbFile    = "scheduler.db"
 )
+
+// ErrPeriodReversed возвращается, если конец запрошенного периода раньше его начала.
+var ErrPeriodReversed = errors.New("the end of the period is earlier than its beginning")
+
+// ErrPeriodTooLong возвращается, если запрошенный период календаря длиннее допустимого.
+var ErrPeriodTooLong = errors.New("the period is too long")
+
+// ErrVersionMismatch возвращается при попытке изменить задачу, версия которой
+// не совпадает с версией, указанной в запросе.
+var ErrVersionMismatch = errors.New("the task has been modified by another request")
+
+// ErrNothingToUndo возвращается, если в сессии нет изменений, которые можно отменить.
+var ErrNothingToUndo = errors.New("there is nothing to undo")
+
+// ErrInvalidImport возвращается, если документ или режим импорта не поддерживаются.
+var ErrInvalidImport = errors.New("invalid import")
+
+// ErrResourceNotFound возвращается, если ресурс CalDAV с указанным именем не найден.
+var ErrResourceNotFound = errors.New("the resource is not found")
+
+// ErrUnknownFormat возвращается, если формат текстового списка задач не поддерживается.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ErrInvalidReminder возвращается, если правило напоминания не распознано.
+var ErrInvalidReminder = errors.New("invalid reminder")
+
+// ErrInvalidWebhook возвращается, если адрес или события подписки указаны неверно.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// ErrWebhookNotFound возвращается, если подписка или запись журнала доставки не найдены.
+var ErrWebhookNotFound = errors.New("the webhook is not found")
+
+// ErrInvalidJob возвращается, если действие задачи описано неверно.
+var ErrInvalidJob = errors.New("invalid job")
+
+// ErrJobNotFound возвращается, если у задачи нет действия.
+var ErrJobNotFound = errors.New("the task has no job")
+
+// ErrJobsDisabled возвращается при попытке задать действие задачи, если выполнение
+// действий не включено в настройках.
+var ErrJobsDisabled = errors.New("job execution is disabled")
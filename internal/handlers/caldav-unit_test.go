@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/handlers"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// multistatus является разобранным ответом WebDAV для проверки в тестах.
+type multistatus struct {
+	Responses []struct {
+		Href   string `xml:"href"`
+		Status string `xml:"status"`
+		Props  []struct {
+			ETag         string `xml:"prop>getetag"`
+			CalendarData string `xml:"prop>calendar-data"`
+			CTag         string `xml:"prop>getctag"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// TestCalDAV тестирует обработчик CalDAV.
+func TestCalDAV(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	objects := []entities.CalDAVObject{
+		{Name: "1.ics", UID: "task-1@task_scheduler", Task: entities.Task{Id: "1", Version: 2}, Data: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"},
+		{Name: "a b.ics", UID: "a-b", Task: entities.Task{Id: "2", Version: 1}, Data: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(handlers.CalDAVRoot, handlers.CalDAV(mockService))
+
+	mockService.On("GetCalDAVObjects").Return(objects, nil)
+	mockService.On("GetCalDAVObject", "1.ics").Return(objects[0], nil)
+	mockService.On("GetCalDAVObject", "missing.ics").Return(entities.CalDAVObject{}, entities.ErrResourceNotFound)
+
+	decode := func(t *testing.T, respRec *httptest.ResponseRecorder) multistatus {
+		require.Equalf(t, http.StatusMultiStatus, respRec.Code, "Ожидался статус 207, но получен %d", respRec.Code)
+
+		var response multistatus
+		require.NoError(t, xml.NewDecoder(respRec.Body).Decode(&response))
+
+		return response
+	}
+
+	t.Run("propfind collection", func(t *testing.T) {
+		req := httptest.NewRequest("PROPFIND", "/caldav/tasks/", nil)
+		req.Header.Set("Depth", "1")
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+		response := decode(t, respRec)
+
+		require.Len(t, response.Responses, 3)
+		require.Equal(t, "/caldav/tasks/", response.Responses[0].Href)
+		require.NotEmpty(t, response.Responses[0].Props[0].CTag)
+		require.Equal(t, "/caldav/tasks/1.ics", response.Responses[1].Href)
+		require.Equal(t, `"2"`, response.Responses[1].Props[0].ETag)
+		require.Equal(t, "/caldav/tasks/a%20b.ics", response.Responses[2].Href)
+	})
+
+	t.Run("multiget", func(t *testing.T) {
+		body := `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+			<D:prop><D:getetag/><C:calendar-data/></D:prop>
+			<D:href>/caldav/tasks/a%20b.ics</D:href>
+			<D:href>/caldav/tasks/missing.ics</D:href>
+		</C:calendar-multiget>`
+		req := httptest.NewRequest("REPORT", "/caldav/tasks/", bytes.NewBufferString(body))
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+		response := decode(t, respRec)
+
+		require.Len(t, response.Responses, 2)
+		require.Equal(t, objects[1].Data, response.Responses[0].Props[0].CalendarData)
+		require.Equal(t, "HTTP/1.1 404 Not Found", response.Responses[1].Status)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/caldav/tasks/1.ics", nil)
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+		require.Equal(t, `"2"`, respRec.Header().Get("ETag"))
+		require.Equal(t, objects[0].Data, respRec.Body.String())
+
+		req = httptest.NewRequest(http.MethodGet, "/caldav/tasks/missing.ics", nil)
+		respRec = httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNotFound, respRec.Code, "Ожидался статус 404, но получен %d", respRec.Code)
+	})
+
+	t.Run("put", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/caldav/tasks/new.ics", bytes.NewBufferString("BEGIN:VCALENDAR"))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("PutCalDAVObject", "new.ics", mock.Anything, 0).Return(true, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusCreated, respRec.Code, "Ожидался статус 201, но получен %d", respRec.Code)
+
+		req = httptest.NewRequest(http.MethodPut, "/caldav/tasks/1.ics", bytes.NewBufferString("BEGIN:VCALENDAR"))
+		req.Header.Set("If-Match", `"1"`)
+		respRec = httptest.NewRecorder()
+
+		mockService.On("PutCalDAVObject", "1.ics", mock.Anything, 1).Return(false, entities.ErrVersionMismatch).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionFailed, respRec.Code, "Ожидался статус 412, но получен %d", respRec.Code)
+
+		req = httptest.NewRequest(http.MethodPut, "/caldav/tasks/1.ics", bytes.NewBufferString("BEGIN:VCALENDAR"))
+		req.Header.Set("If-None-Match", "*")
+		respRec = httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionFailed, respRec.Code, "Ожидался статус 412, но получен %d", respRec.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/caldav/tasks/1.ics", nil)
+		req.Header.Set("If-Match", `"2"`)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("DeleteCalDAVObject", "1.ics", 2).Return(nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNoContent, respRec.Code, "Ожидался статус 204, но получен %d", respRec.Code)
+	})
+}
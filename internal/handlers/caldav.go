@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+)
+
+const (
+	// CalDAVRoot является корнем CalDAV сервера, который также служит принципалом
+	// и домашним каталогом календарей.
+	CalDAVRoot = "/caldav/"
+	// calDAVCollection является единственной коллекцией календаря, содержащей задачи.
+	calDAVCollection = CalDAVRoot + "tasks/"
+)
+
+// davMultistatus является ответом WebDAV со статусами нескольких ресурсов.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	XmlnsD    string        `xml:"xmlns:D,attr"`
+	XmlnsC    string        `xml:"xmlns:C,attr"`
+	XmlnsCS   string        `xml:"xmlns:CS,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href     string        `xml:"D:href"`
+	Propstat []davPropstat `xml:"D:propstat,omitempty"`
+	Status   string        `xml:"D:status,omitempty"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davProp struct {
+	ResourceType         *davResourceType `xml:"D:resourcetype,omitempty"`
+	DisplayName          string           `xml:"D:displayname,omitempty"`
+	CurrentUserPrincipal *davHref         `xml:"D:current-user-principal,omitempty"`
+	CalendarHomeSet      *davHref         `xml:"C:calendar-home-set,omitempty"`
+	SupportedComponents  *davCompSet      `xml:"C:supported-calendar-component-set,omitempty"`
+	CTag                 string           `xml:"CS:getctag,omitempty"`
+	ETag                 string           `xml:"D:getetag,omitempty"`
+	ContentType          string           `xml:"D:getcontenttype,omitempty"`
+	CalendarData         string           `xml:"C:calendar-data,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+	Calendar   *struct{} `xml:"C:calendar,omitempty"`
+}
+
+type davHref struct {
+	Href string `xml:"D:href"`
+}
+
+type davCompSet struct {
+	Comp []davComp `xml:"C:comp"`
+}
+
+type davComp struct {
+	Name string `xml:"name,attr"`
+}
+
+// davReport является телом запроса REPORT: calendar-query или calendar-multiget.
+type davReport struct {
+	XMLName xml.Name
+	Hrefs   []string `xml:"DAV: href"`
+}
+
+// CalDAV обрабатывает запросы минимального CalDAV сервера с одной коллекцией задач,
+// представленных компонентами VTODO: OPTIONS, PROPFIND, REPORT, GET, PUT и DELETE.
+// ETag ресурса соответствует версии задачи.
+func CalDAV(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		switch r.Method {
+		case http.MethodOptions:
+			w.Header().Set("DAV", "1, 3, calendar-access")
+			w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT, GET, HEAD, PUT, DELETE")
+		case "PROPFIND":
+			calDAVPropfind(s, w, r)
+		case "REPORT":
+			calDAVReport(s, w, r)
+		case http.MethodGet, http.MethodHead:
+			calDAVGet(s, w, r)
+		case http.MethodPut:
+			calDAVPut(s, w, r)
+		case http.MethodDelete:
+			calDAVDelete(s, w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// calDAVPropfind возвращает свойства корня или коллекции задач, а при Depth: 1 -
+// также свойства вложенных ресурсов.
+func calDAVPropfind(s services.TaskServiceInterface, w http.ResponseWriter, r *http.Request) {
+	depth := r.Header.Get("Depth")
+
+	switch r.URL.Path {
+	case CalDAVRoot:
+		responses := []davResponse{rootResponse()}
+
+		if depth == "1" {
+			objects, err := s.GetCalDAVObjects()
+			if err != nil {
+				calDAVError(w, err)
+				return
+			}
+
+			responses = append(responses, collectionResponse(objects))
+		}
+
+		writeMultistatus(w, responses)
+	case calDAVCollection:
+		objects, err := s.GetCalDAVObjects()
+		if err != nil {
+			calDAVError(w, err)
+			return
+		}
+
+		responses := []davResponse{collectionResponse(objects)}
+
+		if depth == "1" {
+			for _, object := range objects {
+				responses = append(responses, objectResponse(object, false))
+			}
+		}
+
+		writeMultistatus(w, responses)
+	default:
+		object, err := s.GetCalDAVObject(calDAVObjectName(r.URL.Path))
+		if err != nil {
+			calDAVError(w, err)
+			return
+		}
+
+		writeMultistatus(w, []davResponse{objectResponse(object, false)})
+	}
+}
+
+// calDAVReport обрабатывает запросы calendar-multiget, возвращая запрошенные ресурсы,
+// и calendar-query, возвращая все ресурсы коллекции.
+func calDAVReport(s services.TaskServiceInterface, w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != calDAVCollection {
+		http.Error(w, "Reports are supported only for the task collection", http.StatusForbidden)
+		return
+	}
+
+	var report davReport
+	if err := xml.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objects, err := s.GetCalDAVObjects()
+	if err != nil {
+		calDAVError(w, err)
+		return
+	}
+
+	responses := []davResponse{}
+
+	switch report.XMLName.Local {
+	case "calendar-query":
+		for _, object := range objects {
+			responses = append(responses, objectResponse(object, true))
+		}
+	case "calendar-multiget":
+		byName := make(map[string]entities.CalDAVObject, len(objects))
+		for _, object := range objects {
+			byName[object.Name] = object
+		}
+
+		for _, href := range report.Hrefs {
+			var path string
+			if u, err := url.Parse(strings.TrimSpace(href)); err == nil {
+				path = u.Path
+			}
+
+			object, ok := byName[calDAVObjectName(path)]
+			if !ok || !strings.HasPrefix(path, calDAVCollection) {
+				responses = append(responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+				continue
+			}
+
+			responses = append(responses, objectResponse(object, true))
+		}
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported report %q", report.XMLName.Local), http.StatusForbidden)
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// calDAVGet отправляет задачу ресурса в формате iCalendar.
+func calDAVGet(s services.TaskServiceInterface, w http.ResponseWriter, r *http.Request) {
+	object, err := s.GetCalDAVObject(calDAVObjectName(r.URL.Path))
+	if err != nil {
+		calDAVError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=UTF-8")
+	w.Header().Set("ETag", formatETag(object.Task.Version))
+
+	if r.Method == http.MethodGet {
+		io.WriteString(w, object.Data)
+	}
+}
+
+// calDAVPut создает или изменяет задачу ресурса. Заголовок If-Match делает изменение
+// условным, а If-None-Match: * запрещает перезапись существующего ресурса.
+func calDAVPut(s services.TaskServiceInterface, w http.ResponseWriter, r *http.Request) {
+	name := calDAVObjectName(r.URL.Path)
+	if !strings.HasPrefix(r.URL.Path, calDAVCollection) || name == "" || strings.Contains(name, "/") {
+		http.Error(w, "Resources can be created only in the task collection", http.StatusForbidden)
+		return
+	}
+
+	if strings.TrimSpace(r.Header.Get("If-None-Match")) == "*" {
+		if _, err := s.GetCalDAVObject(name); err == nil {
+			http.Error(w, "The resource already exists", http.StatusPreconditionFailed)
+			return
+		}
+	}
+
+	version, err := calDAVVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := s.PutCalDAVObject(name, http.MaxBytesReader(w, r.Body, maxImportSize), version)
+	if err != nil {
+		calDAVError(w, err)
+		return
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// calDAVDelete удаляет задачу ресурса. Заголовок If-Match делает удаление условным.
+func calDAVDelete(s services.TaskServiceInterface, w http.ResponseWriter, r *http.Request) {
+	version, err := calDAVVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.DeleteCalDAVObject(calDAVObjectName(r.URL.Path), version); err != nil {
+		calDAVError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// calDAVVersion возвращает версию задачи из заголовка If-Match или 0, если заголовок не указан.
+func calDAVVersion(r *http.Request) (int, error) {
+	if r.Header.Get("If-Match") == "" {
+		return 0, nil
+	}
+
+	return requestVersion(r, 0)
+}
+
+// calDAVObjectName возвращает имя ресурса задачи по пути запроса.
+func calDAVObjectName(path string) string {
+	return strings.TrimPrefix(path, calDAVCollection)
+}
+
+// calDAVError отправляет HTTP ответ со статусом, соответствующим ошибке err.
+func calDAVError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entities.ErrResourceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entities.ErrVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, entities.ErrInvalidImport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// rootResponse возвращает свойства корня CalDAV сервера.
+func rootResponse() davResponse {
+	return davResponse{
+		Href: CalDAVRoot,
+		Propstat: []davPropstat{{
+			Prop: davProp{
+				ResourceType:         &davResourceType{Collection: &struct{}{}},
+				DisplayName:          "Task Scheduler",
+				CurrentUserPrincipal: &davHref{Href: CalDAVRoot},
+				CalendarHomeSet:      &davHref{Href: CalDAVRoot},
+			},
+			Status: davStatus(http.StatusOK),
+		}},
+	}
+}
+
+// collectionResponse возвращает свойства коллекции задач. CTag коллекции
+// меняется при любом изменении входящих в нее задач.
+func collectionResponse(objects []entities.CalDAVObject) davResponse {
+	hash := sha256.New()
+	for _, object := range objects {
+		fmt.Fprintf(hash, "%s:%d\n", object.Name, object.Task.Version)
+	}
+
+	return davResponse{
+		Href: calDAVCollection,
+		Propstat: []davPropstat{{
+			Prop: davProp{
+				ResourceType:         &davResourceType{Collection: &struct{}{}, Calendar: &struct{}{}},
+				DisplayName:          "Tasks",
+				CurrentUserPrincipal: &davHref{Href: CalDAVRoot},
+				SupportedComponents:  &davCompSet{Comp: []davComp{{Name: "VTODO"}}},
+				CTag:                 hex.EncodeToString(hash.Sum(nil)),
+			},
+			Status: davStatus(http.StatusOK),
+		}},
+	}
+}
+
+// objectResponse возвращает свойства ресурса задачи, включая данные календаря при withData.
+func objectResponse(object entities.CalDAVObject, withData bool) davResponse {
+	prop := davProp{
+		ETag:        formatETag(object.Task.Version),
+		ContentType: "text/calendar; charset=utf-8; component=VTODO",
+	}
+
+	if withData {
+		prop.CalendarData = object.Data
+	}
+
+	return davResponse{
+		Href:     calDAVCollection + url.PathEscape(object.Name),
+		Propstat: []davPropstat{{Prop: prop, Status: davStatus(http.StatusOK)}},
+	}
+}
+
+// davStatus возвращает строку статуса HTTP для ответа WebDAV.
+func davStatus(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+// writeMultistatus отправляет HTTP ответ WebDAV со статусом 207 Multi-Status.
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+
+	io.WriteString(w, xml.Header)
+
+	err := xml.NewEncoder(w).Encode(davMultistatus{
+		XmlnsD:    "DAV:",
+		XmlnsC:    "urn:ietf:params:xml:ns:caldav",
+		XmlnsCS:   "http://calendarserver.org/ns/",
+		Responses: responses,
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/handlers"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent читает из потока событий следующее событие и возвращает его поля.
+func readEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := make(map[string]string)
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+// TestGetEvents тестирует поток событий изменения задач.
+func TestGetEvents(t *testing.T) {
+	broker := services.GetEventBroker(10)
+
+	server := httptest.NewServer(handlers.GetEvents(broker))
+	defer server.Close()
+
+	open := func(lastEventId string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		if lastEventId != "" {
+			req.Header.Set("Last-Event-ID", lastEventId)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		require.Equal(t, map[string]string{"retry": "3000"}, readEvent(t, reader))
+
+		return resp, reader
+	}
+
+	resp, reader := open("")
+
+	// Подписка создается до отправки заголовков ответа, поэтому событие не будет пропущено.
+	broker.Publish(entities.Event{Type: entities.EventTaskDone, Task: entities.Task{Id: "1", Title: "Просмотр фильма"}})
+
+	done := readEvent(t, reader)
+	resp.Body.Close()
+
+	require.Equal(t, entities.EventTaskDone, done["event"])
+	require.Contains(t, done["data"], `"title":"Просмотр фильма"`)
+
+	broker.Publish(entities.Event{Type: entities.EventTaskDeleted, Task: entities.Task{Id: "2"}})
+
+	t.Run("resume", func(t *testing.T) {
+		resp, reader := open(done["id"])
+		defer resp.Body.Close()
+
+		deleted := readEvent(t, reader)
+
+		require.Equal(t, entities.EventTaskDeleted, deleted["event"])
+		require.Contains(t, deleted["data"], `"id":"2"`)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		resp, reader := open("unknown-1")
+		defer resp.Body.Close()
+
+		reset := readEvent(t, reader)
+
+		require.Equal(t, "reset", reset["event"])
+		require.NotEmpty(t, reset["id"])
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"task_scheduler/internal/services"
+	"time"
+)
+
+const (
+	// eventsRetry задает клиентам задержку переподключения к потоку событий в миллисекундах.
+	eventsRetry = 3000
+	// eventsHeartbeat задает периодичность комментариев, не дающих прокси закрыть поток событий.
+	eventsHeartbeat = 30 * time.Second
+)
+
+// GetEvents возвращает поток Server-Sent Events с событиями изменения задач.
+// Поток возобновляется после события с id из заголовка Last-Event-ID или параметра
+// запроса lastEventId. Если возобновить поток нельзя, то первым отправляется событие
+// reset, после которого клиенту следует заново загрузить задачи.
+func GetEvents(s services.EventStreamInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+
+		// Поток событий не ограничивается временем записи ответа сервера.
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Println(err.Error())
+		}
+
+		lastEventId := r.Header.Get("Last-Event-ID")
+		if lastEventId == "" {
+			lastEventId = r.FormValue("lastEventId")
+		}
+
+		sub := s.Subscribe(lastEventId)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "retry: %d\n\n", eventsRetry)
+
+		if sub.Reset {
+			fmt.Fprintf(w, "id: %s\nevent: reset\ndata: {}\n\n", sub.LastId)
+		}
+
+		for _, event := range sub.Replay {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(eventsHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent записывает событие event в поток событий.
+func writeEvent(w io.Writer, event services.StreamEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Event.Type, data)
+
+	return err
+}
@@ -10,7 +10,10 @@ import (
 	"net/url"
 	"task_scheduler/internal/entities"
 	"task_scheduler/internal/handlers"
+	"task_scheduler/internal/ical"
+	"task_scheduler/internal/services"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -169,6 +172,201 @@ func TestGetTasks(t *testing.T) {
 	})
 }
 
+// TestGetAgenda тестирует обработчик GetAgenda.
+func TestGetAgenda(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	days := []entities.Day{
+		{
+			Date: "20231011",
+			Tasks: []entities.Task{
+				{Id: "1", Date: "20231011", Title: "Сходить в кино"},
+			},
+		},
+	}
+
+	baseURL := "/api/agenda"
+	path := url.Values{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/agenda", handlers.GetAgenda(mockService))
+
+	t.Run("successful get agenda", func(t *testing.T) {
+		path.Set("from", "20231010")
+		path.Set("to", "20231012")
+		fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+
+		respRec := httptest.NewRecorder()
+
+		from := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 10, 12, 0, 0, 0, 0, time.UTC)
+		mockService.On("GetAgenda", from, to).Return(days, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, days, response.Days)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		path.Set("from", "20231012")
+		path.Set("to", "20231010")
+		fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		path.Set("from", "isnotdate")
+		fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		path.Del("from")
+		path.Del("to")
+		fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("GetAgenda", mock.Anything, mock.Anything).Return([]entities.Day{}, errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+
+		var response entities.Result
+		expectedErrStr := "some error"
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, expectedErrStr, response.Error)
+	})
+}
+
+// TestGetOverdueTasks тестирует обработчик GetOverdueTasks.
+func TestGetOverdueTasks(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	days := []entities.Day{
+		{
+			Date: "20231011",
+			Tasks: []entities.Task{
+				{Id: "1", Date: "20231011", Title: "Сходить в кино"},
+			},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tasks/overdue", handlers.GetOverdueTasks(mockService))
+
+	t.Run("successful get overdue tasks", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/overdue", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetOverdueTasks").Return(days, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, days, response.Days)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/overdue", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("GetOverdueTasks").Return([]entities.Day{}, errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+}
+
+// TestGetCalendar тестирует обработчик GetCalendar.
+func TestGetCalendar(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	task := entities.Task{Id: "1", Date: "20231002", Title: "Планерка", Repeat: "w 1"}
+	occurrences := []entities.Occurrence{
+		{Date: "20231002", Task: task},
+		{Date: "20231009", Projected: true, Task: task},
+	}
+
+	baseURL := "/api/calendar"
+	path := url.Values{}
+	path.Add("from", "20231001")
+	path.Add("to", "20231010")
+	fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/calendar", handlers.GetCalendar(mockService))
+
+	t.Run("successful get calendar", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetCalendar", mock.Anything, mock.Anything).Return(occurrences, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, occurrences, response.Occurrences)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("GetCalendar", mock.Anything, mock.Anything).Return([]entities.Occurrence{}, errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+
+	t.Run("period too long", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fullPath, nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("GetCalendar", mock.Anything, mock.Anything).Return([]entities.Occurrence{}, entities.ErrPeriodTooLong)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+}
+
 // TestDoneTask тестирует обработчик DoneTask.
 func TestDoneTask(t *testing.T) {
 	mockService := new(handlers.MockService)
@@ -181,39 +379,368 @@ func TestDoneTask(t *testing.T) {
 		Repeat:  "",
 	}
 
-	baseURL := "/api/task/done"
-	path := url.Values{}
-	path.Add("id", task.Id)
-	fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
-	req := httptest.NewRequest(http.MethodPost, fullPath, nil)
+	baseURL := "/api/task/done"
+	path := url.Values{}
+	path.Add("id", task.Id)
+	fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
+	req := httptest.NewRequest(http.MethodPost, fullPath, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/task/done", handlers.DoneTask(mockService))
+
+	t.Run("successful completion task", func(t *testing.T) {
+		respRec := httptest.NewRecorder()
+
+		mockService.On("DoneTask", task.Id).Return(nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var actualTask entities.Task
+		expectedTask := entities.Task{}
+
+		err := json.NewDecoder(respRec.Body).Decode(&actualTask)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, expectedTask, actualTask)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("DoneTask", task.Id).Return(errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+
+		var response entities.Result
+		expectedErrStr := "some error"
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, expectedErrStr, response.Error)
+	})
+}
+
+// TestGetCompletions тестирует обработчик GetCompletions.
+func TestGetCompletions(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	completions := []entities.Completion{
+		{Id: "1", TaskId: "1", Title: "Сходить в бильярд", Date: "20231021", CompletedAt: 1697900000},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/completed", handlers.GetCompletions(mockService))
+
+	t.Run("successful get completions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/completed?search=бильярд&from=20231020", nil)
+		respRec := httptest.NewRecorder()
+
+		from := time.Date(2023, 10, 20, 0, 0, 0, 0, time.Local)
+		mockService.On("GetCompletions", "бильярд", from, time.Time{}).Return(completions, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, completions, response.Completions)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/completed?to=isnotdate", nil)
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/completed", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("GetCompletions", "", time.Time{}, time.Time{}).Return([]entities.Completion{}, errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+}
+
+// TestHistory тестирует обработчики GetHistory и RevertTask.
+func TestHistory(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	task := entities.Task{Id: "1", Date: "20231021", Title: "Сходить в боулинг", Version: 3}
+	revisions := []entities.Revision{
+		{
+			Id:      "1",
+			TaskId:  "1",
+			Action:  entities.ActionCreate,
+			Actor:   "192.0.2.1",
+			After:   (*entities.TaskSnapshot)(&task),
+			Changes: []entities.FieldChange{{Field: "title", To: task.Title}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/task/history", handlers.GetHistory(mockService))
+	mux.HandleFunc("POST /api/task/revert", handlers.RevertTask(mockService))
+
+	t.Run("successful get history", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/task/history?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetHistory", "1").Return(revisions, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, revisions, response.Revisions)
+	})
+
+	t.Run("successful revert task", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/task/revert?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("RevertTask", "1").Return(task, nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var actualTask entities.Task
+
+		err := json.NewDecoder(respRec.Body).Decode(&actualTask)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, task, actualTask)
+		require.Equal(t, `"3"`, respRec.Header().Get("ETag"))
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/task/revert?id=2", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("RevertTask", "2").Return(entities.Task{}, errors.New("some error"))
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+}
+
+// TestUndo тестирует обработчик Undo.
+func TestUndo(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	task := entities.Task{Id: "1", Date: "20231021", Title: "Сходить в боулинг", Version: 4}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/undo", handlers.Undo(mockService))
+
+	t.Run("successful undo", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/undo", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Undo").Return(task, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var actualTask entities.Task
+
+		err := json.NewDecoder(respRec.Body).Decode(&actualTask)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, task, actualTask)
+	})
+
+	t.Run("nothing to undo", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/undo", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Undo").Return(entities.Task{}, entities.ErrNothingToUndo).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNotFound, respRec.Code, "Ожидался статус 404, но получен %d", respRec.Code)
+	})
+
+	t.Run("task changed by another request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/undo", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Undo").Return(entities.Task{}, entities.ErrVersionMismatch).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionFailed, respRec.Code, "Ожидался статус 412, но получен %d", respRec.Code)
+	})
+}
+
+// TestReminders тестирует обработчики GetReminders, SetReminders и GetPendingReminders.
+func TestReminders(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	reminders := []entities.Reminder{
+		{Id: "1", TaskId: "1", Rule: "1 day before", Offset: -24 * 60, FireAt: 4074969600},
+		{Id: "2", TaskId: "1", Rule: "on the day at 09:00", Offset: 9 * 60, FireAt: 4075088400},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/task/reminders", handlers.GetReminders(mockService))
+	mux.HandleFunc("PUT /api/task/reminders", handlers.SetReminders(mockService))
+	mux.HandleFunc("GET /api/reminders", handlers.GetPendingReminders(mockService))
+
+	t.Run("successful get reminders", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/task/reminders?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetReminders", "1").Return(reminders, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, reminders, response.Reminders)
+	})
+
+	t.Run("successful set reminders", func(t *testing.T) {
+		body := `{"reminders":["1 day before","on the day at 09:00"]}`
+		req := httptest.NewRequest(http.MethodPut, "/api/task/reminders?id=1", bytes.NewBufferString(body))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("SetReminders", "1", []string{"1 day before", "on the day at 09:00"}).Return(reminders, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, reminders, response.Reminders)
+	})
+
+	t.Run("invalid reminder", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/task/reminders?id=1", bytes.NewBufferString(`{"reminders":["tomorrow"]}`))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("SetReminders", "1", []string{"tomorrow"}).Return([]entities.Reminder(nil), entities.ErrInvalidReminder).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/task/reminders?id=1", bytes.NewBufferString(`{"reminders":`))
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful get pending reminders", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/reminders", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetPendingReminders").Return(reminders[1:], nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, reminders[1:], response.Reminders)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/task/reminders?id=2", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetReminders", "2").Return([]entities.Reminder(nil), errors.New("some error")).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+}
+
+// TestTrash тестирует обработчики GetTrash, RestoreTask и PurgeTask.
+func TestTrash(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	trash := []entities.TrashedTask{
+		{
+			Task:      entities.Task{Id: "1", Date: "20231021", Title: "Сходить в боулинг", Version: 2},
+			DeletedAt: 1700000000,
+		},
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/task/done", handlers.DoneTask(mockService))
+	mux.HandleFunc("GET /api/trash", handlers.GetTrash(mockService))
+	mux.HandleFunc("POST /api/trash/restore", handlers.RestoreTask(mockService))
+	mux.HandleFunc("DELETE /api/trash", handlers.PurgeTask(mockService))
 
-	t.Run("successful completion task", func(t *testing.T) {
+	t.Run("successful get trash", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/trash", nil)
 		respRec := httptest.NewRecorder()
 
-		mockService.On("GetTask", mock.Anything).Return(task, nil)
-		mockService.On("DeleteTask", mock.Anything).Return(nil)
+		mockService.On("GetTrash").Return(trash, nil)
 		mux.ServeHTTP(respRec, req)
 
 		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
 
-		var actualTask entities.Task
-		expectedTask := entities.Task{}
+		var response entities.Result
 
-		err := json.NewDecoder(respRec.Body).Decode(&actualTask)
+		err := json.NewDecoder(respRec.Body).Decode(&response)
 		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
 
-		require.Equal(t, expectedTask, actualTask)
+		require.Equal(t, trash, response.Trash)
+	})
+
+	t.Run("successful restore task", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/trash/restore?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("RestoreTask", "1").Return(nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful purge task", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/trash?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("PurgeTask", "1").Return(nil)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
 	})
 
 	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/trash/restore?id=2", nil)
 		respRec := httptest.NewRecorder()
 
-		mockService.ExpectedCalls = nil
-		mockService.On("GetTask", mock.Anything).Return(task, nil)
-		mockService.On("DeleteTask", mock.Anything).Return(errors.New("some error"))
+		mockService.On("RestoreTask", "2").Return(errors.New("some error"))
 		mux.ServeHTTP(respRec, req)
 
 		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
@@ -228,6 +755,323 @@ func TestDoneTask(t *testing.T) {
 	})
 }
 
+// TestExportImport тестирует обработчики Export и Import.
+func TestExportImport(t *testing.T) {
+	mockService := new(handlers.MockService)
+	mockJobService := new(handlers.MockJobService)
+
+	doc := entities.Export{
+		Format:  entities.ExportFormat,
+		Version: entities.ExportVersion,
+		Tasks:   []entities.Task{{Id: "1", Date: "20231021", Title: "Сходить в боулинг", Version: 1}},
+		Jobs:    []entities.Job{{TaskId: "1", Type: entities.JobCommand, Command: "backup.sh"}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/export", handlers.Export(mockService))
+	mux.HandleFunc("POST /api/import", handlers.Import(mockService, mockJobService))
+
+	t.Run("successful export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Export").Return(doc, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+		require.Contains(t, respRec.Header().Get("Content-Disposition"), "attachment")
+
+		var actualDoc entities.Export
+
+		err := json.NewDecoder(respRec.Body).Decode(&actualDoc)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, doc, actualDoc)
+	})
+
+	t.Run("successful import", func(t *testing.T) {
+		body, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/import?mode=replace", bytes.NewReader(body))
+		respRec := httptest.NewRecorder()
+
+		report := entities.ImportReport{Created: 1, Errors: []entities.ImportError{}, TaskIds: map[string]string{"1": "2"}}
+		warnings := []entities.ImportError{{Index: 0, Id: "1", Error: "failed to import the job: jobs are disabled"}}
+
+		mockService.On("Import", doc, entities.ImportReplace).Return(report, nil).Once()
+		mockJobService.On("ImportJobs", doc.Jobs, report.TaskIds).Return(warnings).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err = json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, &entities.ImportReport{Created: 1, Errors: []entities.ImportError{}, Warnings: warnings}, response.Import)
+	})
+
+	t.Run("invalid import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewBufferString("{not json"))
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/import?mode=overwrite", bytes.NewBufferString("{}"))
+		respRec = httptest.NewRecorder()
+
+		mockService.On("Import", entities.Export{}, "overwrite").Return(entities.ImportReport{}, entities.ErrInvalidImport).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+}
+
+// TestTasksCSV тестирует экспорт задач в CSV обработчиком GetTasks и обработчик ImportCSV.
+func TestTasksCSV(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	tasks := []entities.Task{
+		{Id: "1", Date: "20231021", Title: "Сходить в боулинг", Comment: "С друзьями, вечером", Repeat: "d 7"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tasks", handlers.GetTasks(mockService))
+	mux.HandleFunc("POST /api/import/csv", handlers.ImportCSV(mockService))
+
+	t.Run("successful export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?search=боулинг&format=csv", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetTasks", "боулинг").Return(tasks, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+		require.Equal(t, "text/csv; charset=UTF-8", respRec.Header().Get("Content-Type"))
+		require.Equal(t, "id,date,title,comment,repeat\n1,20231021,Сходить в боулинг,\"С друзьями, вечером\",d 7\n", respRec.Body.String())
+	})
+
+	t.Run("successful import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/csv?dry_run=true&map=title:Задача&map=date:Срок",
+			bytes.NewBufferString("Задача,Срок\nСходить в боулинг,21.10.2099\n"))
+		respRec := httptest.NewRecorder()
+
+		report := entities.ImportReport{Created: 1, Errors: []entities.ImportError{}, DryRun: true}
+		mapping := map[string]string{"title": "Задача", "date": "Срок"}
+		mockService.On("ImportCSV", mock.Anything, mapping, true).Return(report, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, &report, response.Import)
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/csv?map=title", bytes.NewBufferString("title\n"))
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+}
+
+// TestTextLists тестирует обработчики ExportText и ImportText.
+func TestTextLists(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/export/{format}", handlers.ExportText(mockService))
+	mux.HandleFunc("POST /api/import/csv", handlers.ImportCSV(mockService))
+	mux.HandleFunc("POST /api/import/{format}", handlers.ImportText(mockService))
+
+	t.Run("successful export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/markdown", nil)
+		respRec := httptest.NewRecorder()
+
+		data := []byte("- [ ] Сходить в боулинг due:2023-10-21 rec:1w\n")
+		mockService.On("ExportText", entities.TextMarkdown).Return(data, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+		require.Equal(t, "text/markdown; charset=UTF-8", respRec.Header().Get("Content-Type"))
+		require.Equal(t, string(data), respRec.Body.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/org", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("ExportText", "org").Return([]byte(nil), entities.ErrUnknownFormat).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNotFound, respRec.Code, "Ожидался статус 404, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/todotxt?dry_run=1",
+			bytes.NewBufferString("Сходить в боулинг due:2099-10-21\n"))
+		respRec := httptest.NewRecorder()
+
+		report := entities.ImportReport{
+			Created: 1,
+			Errors:  []entities.ImportError{},
+			DryRun:  true,
+			Preview: []entities.Task{{Date: "20991021", Title: "Сходить в боулинг"}},
+		}
+		mockService.On("ImportText", mock.Anything, entities.TextTodoTxt, true).Return(report, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, &report, response.Import)
+	})
+
+	t.Run("invalid import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/org", bytes.NewBufferString("* TODO"))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("ImportText", mock.Anything, "org", false).Return(entities.ImportReport{}, entities.ErrInvalidImport).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+}
+
+// TestImportFromApp тестирует обработчик ImportFromApp.
+func TestImportFromApp(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/import/{format}", handlers.ImportText(mockService))
+	mux.HandleFunc("POST /api/import/from/{source}", handlers.ImportFromApp(mockService))
+
+	t.Run("successful import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/from/trello", bytes.NewBufferString(`{"cards": []}`))
+		respRec := httptest.NewRecorder()
+
+		report := entities.ImportReport{
+			Created:  1,
+			Errors:   []entities.ImportError{},
+			Warnings: []entities.ImportError{{Index: 0, Id: "c1", Error: `the due date "tomorrow" cannot be translated`}},
+		}
+		mockService.On("ImportFromApp", mock.Anything, entities.SourceTrello, false).Return(report, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, &report, response.Import)
+	})
+
+	t.Run("invalid import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/from/asana?dry_run=true", bytes.NewBufferString("{}"))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("ImportFromApp", mock.Anything, "asana", true).Return(entities.ImportReport{}, entities.ErrInvalidImport).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+}
+
+// TestCalendarFeed тестирует обработчики GetCalendarFeed и CreateFeedToken.
+func TestCalendarFeed(t *testing.T) {
+	mockService := new(handlers.MockService)
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/calendar.ics", services.CheckFeedTokenMiddleware(mockService, handlers.GetCalendarFeed(mockService)))
+	mux.HandleFunc("POST /api/calendar/token", handlers.CreateFeedToken(mockService))
+	mux.HandleFunc("POST /api/import/ics", handlers.ImportICS(mockService))
+
+	t.Run("successful feed with token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/calendar.ics?token=secret&component=vtodo", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("CheckFeedToken", "secret").Return(true, nil).Once()
+		mockService.On("GetCalendarFeed", true).Return(calendar, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+		require.Equal(t, "text/calendar; charset=UTF-8", respRec.Header().Get("Content-Type"))
+		require.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n", respRec.Body.String())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/calendar.ics?token=wrong", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("CheckFeedToken", "wrong").Return(false, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusUnauthorized, respRec.Code, "Ожидался статус 401, но получен %d", respRec.Code)
+	})
+
+	t.Run("import ics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/import/ics", bytes.NewBufferString("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+		respRec := httptest.NewRecorder()
+
+		report := entities.ImportReport{Created: 2, Updated: 1, Errors: []entities.ImportError{}}
+		mockService.On("ImportICS", mock.Anything).Return(report, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, &report, response.Import)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/import/ics", bytes.NewBufferString("garbage"))
+		respRec = httptest.NewRecorder()
+
+		mockService.On("ImportICS", mock.Anything).Return(entities.ImportReport{}, entities.ErrInvalidImport).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("create token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/calendar/token", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("CreateFeedToken").Return("secret", nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, "secret", response.Token)
+	})
+}
+
 // TestUpdateTasks тестирует обработчик UpdateTasks.
 func TestUpdateTasks(t *testing.T) {
 	mockService := new(handlers.MockService)
@@ -238,11 +1082,13 @@ func TestUpdateTasks(t *testing.T) {
 		Title:   "Сходить в боулинг",
 		Comment: "взять ботинки",
 		Repeat:  "",
+		Version: 2,
 	}
 
 	baseURL := "/api/task"
 	path := url.Values{}
 	path.Add("id", "1")
+	path.Add("version", "2")
 	fullPath := fmt.Sprintf("%s?%s", baseURL, path.Encode())
 	body, _ := json.Marshal(task)
 
@@ -284,6 +1130,7 @@ func TestUpdateTasks(t *testing.T) {
 		require.NoError(t, err)
 
 		require.Equal(t, task, actualTask)
+		require.Equal(t, `"2"`, respRec.Header().Get("ETag"))
 	})
 
 	t.Run("successful edit task", func(t *testing.T) {
@@ -295,19 +1142,56 @@ func TestUpdateTasks(t *testing.T) {
 
 		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
 
+		expectedTask := task
+		expectedTask.Version = 3
 		actualTask := entities.Task{}
 
 		err := json.NewDecoder(respRec.Body).Decode(&actualTask)
 		require.NoError(t, err)
 
-		require.Equal(t, task, actualTask)
+		require.Equal(t, expectedTask, actualTask)
+		require.Equal(t, `"3"`, respRec.Header().Get("ETag"))
+	})
+
+	t.Run("edit task without version", func(t *testing.T) {
+		taskWithoutVersion := task
+		taskWithoutVersion.Version = 0
+		body, _ := json.Marshal(taskWithoutVersion)
+
+		req := httptest.NewRequest(http.MethodPut, baseURL, bytes.NewReader(body))
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionRequired, respRec.Code, "Ожидался статус 428, но получен %d", respRec.Code)
+	})
+
+	t.Run("edit task with stale version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, baseURL, bytes.NewReader(body))
+		req.Header.Set("If-Match", `"1"`)
+		respRec := httptest.NewRecorder()
+
+		mockService.ExpectedCalls = nil
+		mockService.On("EditTask", mock.MatchedBy(func(task entities.Task) bool {
+			return task.Version == 1
+		})).Return(entities.ErrVersionMismatch)
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionFailed, respRec.Code, "Ожидался статус 412, но получен %d", respRec.Code)
+
+		actualRes := entities.Result{}
+
+		err := json.NewDecoder(respRec.Body).Decode(&actualRes)
+		require.NoError(t, err)
+
+		require.Equal(t, entities.ErrVersionMismatch.Error(), actualRes.Error)
 	})
 
 	t.Run("successful delete task", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodDelete, fullPath, nil)
 		respRec := httptest.NewRecorder()
 
-		mockService.On("DeleteTask", mock.Anything).Return(nil)
+		mockService.On("DeleteTask", mock.Anything, mock.Anything).Return(nil)
 		mux.ServeHTTP(respRec, req)
 
 		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
@@ -321,6 +1205,15 @@ func TestUpdateTasks(t *testing.T) {
 		require.Equal(t, expectedTask, actualTask)
 	})
 
+	t.Run("delete task without version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, baseURL+"?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusPreconditionRequired, respRec.Code, "Ожидался статус 428, но получен %d", respRec.Code)
+	})
+
 	t.Run("invalid request method", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodHead, baseURL, nil)
 		respRec := httptest.NewRecorder()
@@ -335,7 +1228,7 @@ func TestUpdateTasks(t *testing.T) {
 		respRec := httptest.NewRecorder()
 
 		mockService.ExpectedCalls = nil
-		mockService.On("DeleteTask", mock.Anything).Return(errors.New("some error"))
+		mockService.On("DeleteTask", mock.Anything, mock.Anything).Return(errors.New("some error"))
 		mux.ServeHTTP(respRec, req)
 
 		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
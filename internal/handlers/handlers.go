@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"task_scheduler/internal/entities"
+	"task_scheduler/internal/ical"
 	"task_scheduler/internal/services"
 	"time"
 )
@@ -83,29 +90,134 @@ func GetTasks(s services.TaskServiceInterface) http.HandlerFunc {
 			return
 		}
 
+		if r.FormValue("format") == "csv" {
+			writeTasksCSV(w, tasks)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		json.NewEncoder(w).Encode(entities.Result{Tasks: tasks})
 	}
 }
 
+// GetAgenda получает границы периода from и to из параметров запроса и возвращает
+// HTTP ответ, содержащий задачи за этот период, сгруппированные по дням.
+// По умолчанию период начинается с текущего дня и длится неделю.
+func GetAgenda(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := parsePeriod(r, 0, 6)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		days, err := s.GetAgenda(from, to)
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Days: days})
+	}
+}
+
+// GetOverdueTasks возвращает HTTP ответ, содержащий просроченные задачи, сгруппированные по дням.
+func GetOverdueTasks(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days, err := s.GetOverdueTasks()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Days: days})
+	}
+}
+
+// GetCalendar получает границы периода from и to из параметров запроса и возвращает
+// HTTP ответ, содержащий все вхождения задач в этот период с учетом правил повторения.
+// По умолчанию период начинается с текущего дня и длится месяц.
+func GetCalendar(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := parsePeriod(r, 1, -1)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		occurrences, err := s.GetCalendar(from, to)
+		if errors.Is(err, entities.ErrPeriodReversed) || errors.Is(err, entities.ErrPeriodTooLong) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Occurrences: occurrences})
+	}
+}
+
+// parsePeriod получает границы периода из параметров запроса from и to.
+// Если from не указан, период начинается с текущего дня, а если не указан to,
+// то период длится указанное количество месяцев и дней от начала.
+func parsePeriod(r *http.Request, months, days int) (time.Time, time.Time, error) {
+	var (
+		from, to time.Time
+		err      error
+	)
+
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if r.FormValue("from") != "" {
+		from, err = time.Parse("20060102", r.FormValue("from"))
+		if err != nil {
+			return from, to, err
+		}
+	}
+
+	to = from.AddDate(0, months, days)
+	if r.FormValue("to") != "" {
+		to, err = time.Parse("20060102", r.FormValue("to"))
+		if err != nil {
+			return from, to, err
+		}
+	}
+
+	if to.Before(from) {
+		return from, to, entities.ErrPeriodReversed
+	}
+
+	return from, to, nil
+}
+
 // DoneTask завершает или обновляет дату задачи, если поле repeat не пустое и
 // возвращает пустой JSON в случае успешной обработки.
 func DoneTask(s services.TaskServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			task entities.Task
-			id   string
-			err  error
-		)
-
 		if r.FormValue("id") == "" {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(entities.Result{Error: "id не указан или указан некорректно"})
 			return
 		}
 
-		id = r.FormValue("id")
-		task, err = s.GetTask(id)
+		s := s.As(requestActor(r))
+
+		err := s.DoneTask(r.FormValue("id"))
 		if err != nil {
 			log.Println(err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
@@ -113,49 +225,530 @@ func DoneTask(s services.TaskServiceInterface) http.HandlerFunc {
 			return
 		}
 
-		if task.Repeat != "" {
-			nextDate, err := s.GetNextDate(time.Now(), task.Date, task.Repeat)
-			if err != nil {
-				log.Println(err.Error())
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
-				return
-			}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Task{})
+	}
+}
 
-			task.Date = nextDate
+// GetCompletions возвращает HTTP ответ, содержащий журнал выполненных задач.
+// Журнал можно отфильтровать по строке search в названии задачи и по дате выполнения
+// с помощью параметров from и to.
+func GetCompletions(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			from, to time.Time
+			err      error
+		)
 
-			err = s.EditTask(task)
+		if r.FormValue("from") != "" {
+			from, err = time.ParseInLocation("20060102", r.FormValue("from"), time.Local)
 			if err != nil {
-				log.Println(err.Error())
-				w.WriteHeader(http.StatusInternalServerError)
+				w.WriteHeader(http.StatusBadRequest)
 				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
 				return
 			}
-		} else {
-			err = s.DeleteTask(id)
+		}
+
+		if r.FormValue("to") != "" {
+			to, err = time.ParseInLocation("20060102", r.FormValue("to"), time.Local)
 			if err != nil {
-				log.Println(err.Error())
-				w.WriteHeader(http.StatusInternalServerError)
+				w.WriteHeader(http.StatusBadRequest)
 				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
 				return
 			}
 		}
 
+		completions, err := s.GetCompletions(r.FormValue("search"), from, to)
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Completions: completions})
+	}
+}
+
+// GetTrash возвращает HTTP ответ, содержащий список задач в корзине.
+func GetTrash(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tasks, err := s.GetTrash()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Trash: tasks})
+	}
+}
+
+// RestoreTask восстанавливает задачу с id, полученным из параметра запроса, из корзины и
+// возвращает пустой JSON в случае успешной обработки.
+func RestoreTask(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		err := s.RestoreTask(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Task{})
+	}
+}
+
+// PurgeTask окончательно удаляет задачу с id, полученным из параметра запроса, из корзины и
+// возвращает пустой JSON в случае успешной обработки.
+func PurgeTask(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := s.PurgeTask(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		json.NewEncoder(w).Encode(entities.Task{})
 	}
 }
 
+// GetHistory возвращает HTTP ответ, содержащий историю изменений задачи с id,
+// полученным из параметра запроса.
+func GetHistory(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		revisions, err := s.GetHistory(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Revisions: revisions})
+	}
+}
+
+// RevertTask возвращает задачу в состояние из записи истории с id, полученным из
+// параметра запроса, и возвращает HTTP ответ, содержащий восстановленную задачу.
+func RevertTask(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		task, err := s.RevertTask(r.FormValue("id"))
+		if errors.Is(err, entities.ErrVersionMismatch) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("ETag", formatETag(task.Version))
+		json.NewEncoder(w).Encode(task)
+	}
+}
+
+// Undo отменяет последнее изменение, удаление или выполнение задачи в сессии запроса и
+// возвращает HTTP ответ, содержащий восстановленную задачу.
+func Undo(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		task, err := s.Undo()
+		if errors.Is(err, entities.ErrNothingToUndo) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if errors.Is(err, entities.ErrVersionMismatch) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("ETag", formatETag(task.Version))
+		json.NewEncoder(w).Encode(task)
+	}
+}
+
+// GetReminders возвращает HTTP ответ, содержащий напоминания задачи с id,
+// полученным из параметра запроса.
+func GetReminders(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reminders, err := s.GetReminders(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Reminders: reminders})
+	}
+}
+
+// SetReminders заменяет напоминания задачи с id, полученным из параметра запроса,
+// напоминаниями по правилам из тела запроса и возвращает HTTP ответ, содержащий новые напоминания.
+func SetReminders(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reminders []string `json:"reminders"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		reminders, err := s.SetReminders(r.FormValue("id"), body.Reminders)
+		if errors.Is(err, entities.ErrInvalidReminder) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Reminders: reminders})
+	}
+}
+
+// GetPendingReminders возвращает HTTP ответ, содержащий все ожидающие отправки напоминания.
+func GetPendingReminders(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reminders, err := s.GetPendingReminders()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Reminders: reminders})
+	}
+}
+
+// maxImportSize ограничивает размер тела запроса импорта задач.
+const maxImportSize = 32 << 20
+
+// Export отправляет HTTP ответ с документом полного экспорта задач в виде JSON файла.
+func Export(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := s.Export()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="scheduler-export.json"`)
+
+		if err := services.EncodeExport(w, doc); err != nil {
+			log.Printf("failed to write the export: %s\n", err.Error())
+		}
+	}
+}
+
+// Import импортирует задачи из документа экспорта, полученного из тела запроса,
+// в режиме из параметра запроса mode и отправляет HTTP ответ с отчетом об импорте.
+// Действия задач задаются созданным задачам через сервис действий js.
+func Import(s services.TaskServiceInterface, js services.JobServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		var doc entities.Export
+
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&doc); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		report, err := s.Import(doc, r.FormValue("mode"))
+		if errors.Is(err, entities.ErrInvalidImport) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		report.Warnings = append(report.Warnings, js.ImportJobs(doc.Jobs, report.TaskIds)...)
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Import: &report})
+	}
+}
+
+// ImportCSV импортирует задачи из файла CSV, полученного из тела запроса, и отправляет
+// HTTP ответ с отчетом об импорте. Параметры запроса map в формате "поле:столбец"
+// задают сопоставление полей задачи столбцам файла, а параметр dry_run включает
+// пробный импорт без сохранения задач.
+func ImportCSV(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		mapping := make(map[string]string)
+		for _, pair := range r.URL.Query()["map"] {
+			field, column, ok := strings.Cut(pair, ":")
+			if !ok || field == "" || column == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(entities.Result{Error: fmt.Sprintf("invalid column mapping %q", pair)})
+				return
+			}
+
+			mapping[strings.ToLower(field)] = column
+		}
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+		report, err := s.ImportCSV(http.MaxBytesReader(w, r.Body, maxImportSize), mapping, dryRun)
+		if errors.Is(err, entities.ErrInvalidImport) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Import: &report})
+	}
+}
+
+// ExportText отправляет HTTP ответ со всеми задачами в виде текстового списка в формате,
+// указанном в пути запроса: todotxt (файл todo.txt) или markdown (список задач Markdown).
+func ExportText(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.PathValue("format")
+
+		data, err := s.ExportText(format)
+		if errors.Is(err, entities.ErrUnknownFormat) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if format == entities.TextMarkdown {
+			w.Header().Set("Content-Type", "text/markdown; charset=UTF-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="tasks.md"`)
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="todo.txt"`)
+		}
+
+		w.Write(data)
+	}
+}
+
+// ImportText импортирует задачи из текстового списка, полученного из тела запроса,
+// в формате, указанном в пути запроса (todotxt или markdown), и отправляет HTTP ответ
+// с отчетом об импорте. Параметр запроса dry_run включает предпросмотр импорта
+// без сохранения задач.
+func ImportText(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+		report, err := s.ImportText(http.MaxBytesReader(w, r.Body, maxImportSize), r.PathValue("format"), dryRun)
+		if errors.Is(err, entities.ErrInvalidImport) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Import: &report})
+	}
+}
+
+// ImportFromApp импортирует задачи из файла экспорта приложения, указанного в пути запроса
+// (todoist, trello или mstodo), и отправляет HTTP ответ с отчетом об импорте, включающим
+// предупреждения о данных, которые не удалось перенести. Параметр запроса dry_run
+// включает пробный импорт без сохранения задач.
+func ImportFromApp(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+		report, err := s.ImportFromApp(http.MaxBytesReader(w, r.Body, maxImportSize), r.PathValue("source"), dryRun)
+		if errors.Is(err, entities.ErrInvalidImport) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Import: &report})
+	}
+}
+
+// ImportICS импортирует задачи из файла iCalendar, полученного из тела запроса,
+// и отправляет HTTP ответ с отчетом об импорте.
+func ImportICS(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := s.As(requestActor(r))
+
+		report, err := s.ImportICS(http.MaxBytesReader(w, r.Body, maxImportSize))
+		if errors.Is(err, entities.ErrInvalidImport) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Import: &report})
+	}
+}
+
+// writeTasksCSV отправляет HTTP ответ со списком задач в виде файла CSV.
+func writeTasksCSV(w http.ResponseWriter, tasks []entities.Task) {
+	w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="tasks.csv"`)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "date", "title", "comment", "repeat"})
+
+	for _, task := range tasks {
+		writer.Write([]string{task.Id, task.Date, task.Title, task.Comment, task.Repeat})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// GetCalendarFeed отправляет HTTP ответ со всеми задачами в формате iCalendar.
+// Параметр запроса component=vtodo представляет задачи компонентами VTODO вместо событий VEVENT.
+func GetCalendarFeed(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		calendar, err := s.GetCalendarFeed(strings.EqualFold(r.FormValue("component"), "vtodo"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=UTF-8")
+		w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+		if err := ical.Encode(w, calendar); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+// CreateFeedToken создает новый токен календарной подписки и отправляет HTTP ответ, содержащий его.
+// Ранее выданный токен перестает действовать.
+func CreateFeedToken(s services.TaskServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := s.CreateFeedToken()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Token: token})
+	}
+}
+
 // UpdateTasks обрабатывает несколько методов: POST, GET, PUT, DELETE.
+// Ответ на GET содержит версию задачи в заголовке ETag, а для PUT и DELETE версия
+// обязательна и передается в заголовке If-Match или в поле version.
 func UpdateTasks(s services.TaskServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
-			id   string
-			resp []byte
-			err  error
-			task = entities.Task{}
+			id      string
+			resp    []byte
+			version int
+			err     error
+			task    = entities.Task{}
 		)
 
+		s := s.As(requestActor(r))
+
 		switch r.Method {
 		case http.MethodPost:
 			err = json.NewDecoder(r.Body).Decode(&task)
@@ -168,6 +761,9 @@ func UpdateTasks(s services.TaskServiceInterface) http.HandlerFunc {
 			resp, _ = json.Marshal(entities.Result{Id: id})
 		case http.MethodGet:
 			task, err = s.GetTask(r.FormValue("id"))
+			if err == nil {
+				w.Header().Set("ETag", formatETag(task.Version))
+			}
 			resp, _ = json.Marshal(task)
 		case http.MethodPut:
 			err = json.NewDecoder(r.Body).Decode(&task)
@@ -176,16 +772,42 @@ func UpdateTasks(s services.TaskServiceInterface) http.HandlerFunc {
 				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
 				return
 			}
+
+			task.Version, err = requestVersion(r, task.Version)
+			if err != nil {
+				w.WriteHeader(http.StatusPreconditionRequired)
+				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+				return
+			}
+
 			err = s.EditTask(task)
+			if err == nil && task.Version != 0 {
+				task.Version++
+				w.Header().Set("ETag", formatETag(task.Version))
+			}
 			resp, _ = json.Marshal(task)
 		case http.MethodDelete:
-			err = s.DeleteTask(r.FormValue("id"))
+			version, _ = strconv.Atoi(r.FormValue("version"))
+			version, err = requestVersion(r, version)
+			if err != nil {
+				w.WriteHeader(http.StatusPreconditionRequired)
+				json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+				return
+			}
+
+			err = s.DeleteTask(r.FormValue("id"), version)
 			resp, _ = json.Marshal(task)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
+		if errors.Is(err, entities.ErrVersionMismatch) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
 		if err != nil {
 			log.Println(err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
@@ -197,3 +819,50 @@ func UpdateTasks(s services.TaskServiceInterface) http.HandlerFunc {
 		w.Write(resp)
 	}
 }
+
+// formatETag возвращает значение заголовка ETag для указанной версии задачи.
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// requestVersion получает версию задачи из заголовка If-Match, а если он не указан,
+// то использует версию bodyVersion из тела или параметров запроса.
+// Значение "*" в If-Match отключает проверку версии и соответствует нулевой версии.
+func requestVersion(r *http.Request, bodyVersion int) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	if ifMatch != "" {
+		version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+		if err != nil || version < 1 {
+			return 0, errors.New("the If-Match header is specified not correctly")
+		}
+
+		return version, nil
+	}
+
+	if bodyVersion < 1 {
+		return 0, errors.New("the task version is required in the If-Match header or the version field")
+	}
+
+	return bodyVersion, nil
+}
+
+// requestActor возвращает идентификатор инициатора запроса, сохраняемый в истории
+// изменений задач. Им является адрес клиента, дополненный идентификатором сессии,
+// если запрос прошел аутентификацию.
+func requestActor(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if sid := services.RequestSession(r); sid != "" {
+		return host + "/" + sid
+	}
+
+	return host
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/handlers"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobs тестирует обработчики действий задач.
+func TestJobs(t *testing.T) {
+	mockService := new(handlers.MockJobService)
+
+	job := entities.Job{TaskId: "1", Type: entities.JobCommand, Command: "backup.sh", Timeout: 600}
+	exitCode := 0
+	run := entities.JobRun{Id: "7", TaskId: "1", Date: "20990220", Type: entities.JobCommand, Status: entities.JobSucceeded, ExitCode: &exitCode, Stdout: "done\n", QueuedAt: 1700000000}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/task/job", handlers.GetJob(mockService))
+	mux.HandleFunc("PUT /api/task/job", handlers.SetJob(mockService))
+	mux.HandleFunc("DELETE /api/task/job", handlers.DeleteJob(mockService))
+	mux.HandleFunc("GET /api/task/job/runs", handlers.GetJobRuns(mockService))
+
+	t.Run("successful set job", func(t *testing.T) {
+		body := `{"type":"command","command":"backup.sh","timeout":600}`
+		req := httptest.NewRequest(http.MethodPut, "/api/task/job?id=1", bytes.NewBufferString(body))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("SetJob", "1", entities.Job{Type: entities.JobCommand, Command: "backup.sh", Timeout: 600}).Return(job, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var actual entities.Job
+
+		err := json.NewDecoder(respRec.Body).Decode(&actual)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, job, actual)
+	})
+
+	t.Run("invalid job", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/task/job?id=1", bytes.NewBufferString(`{"type":"script"}`))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("SetJob", "1", entities.Job{Type: "script"}).Return(entities.Job{}, entities.ErrInvalidJob).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("jobs disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/task/job?id=2", bytes.NewBufferString(`{"type":"command","command":"true"}`))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("SetJob", "2", entities.Job{Type: entities.JobCommand, Command: "true"}).Return(entities.Job{}, entities.ErrJobsDisabled).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusForbidden, respRec.Code, "Ожидался статус 403, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful get job", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/task/job?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetJob", "1").Return(job, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var actual entities.Job
+
+		err := json.NewDecoder(respRec.Body).Decode(&actual)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, job, actual)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/task/job?id=2", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("DeleteJob", "2").Return(entities.ErrJobNotFound).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNotFound, respRec.Code, "Ожидался статус 404, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful get job runs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/task/job/runs?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetJobRuns", "1").Return([]entities.JobRun{run}, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, []entities.JobRun{run}, response.Runs)
+	})
+
+	mockService.AssertExpectations(t)
+}
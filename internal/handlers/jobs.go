@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+)
+
+// GetJob возвращает HTTP ответ, содержащий действие задачи с id, полученным из параметра запроса.
+func GetJob(s services.JobServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := s.GetJob(r.FormValue("id"))
+		if errors.Is(err, entities.ErrJobNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+// SetJob задает задаче с id, полученным из параметра запроса, действие из тела запроса
+// и возвращает HTTP ответ, содержащий сохраненное действие.
+func SetJob(s services.JobServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var job entities.Job
+
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		job, err := s.SetJob(r.FormValue("id"), job)
+		if errors.Is(err, entities.ErrInvalidJob) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if errors.Is(err, entities.ErrJobsDisabled) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+// DeleteJob удаляет действие задачи с id, полученным из параметра запроса,
+// и возвращает пустой JSON в случае успешной обработки.
+func DeleteJob(s services.JobServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := s.DeleteJob(r.FormValue("id"))
+		if errors.Is(err, entities.ErrJobNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{})
+	}
+}
+
+// GetJobRuns возвращает HTTP ответ, содержащий последние записи журнала запусков
+// действия задачи с id, полученным из параметра запроса.
+func GetJobRuns(s services.JobServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		runs, err := s.GetJobRuns(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Runs: runs})
+	}
+}
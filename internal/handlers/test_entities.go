@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"io"
 	"task_scheduler/internal/entities"
+	"task_scheduler/internal/ical"
+	"task_scheduler/internal/services"
 	"time"
 
 	"github.com/stretchr/testify/mock"
@@ -11,11 +14,20 @@ type MockService struct {
 	mock.Mock
 }
 
+func (m *MockService) As(actor string) services.TaskServiceInterface {
+	return m
+}
+
 func (m *MockService) AddTask(newTask entities.Task) (string, error) {
 	args := m.Called(newTask)
 	return args.String(0), args.Error(1)
 }
-func (m *MockService) DeleteTask(id string) error {
+func (m *MockService) DeleteTask(id string, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockService) DoneTask(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -40,6 +52,199 @@ func (m *MockService) GetTasks(target string) ([]entities.Task, error) {
 	return args.Get(0).([]entities.Task), args.Error(1)
 }
 
+func (m *MockService) GetAgenda(from, to time.Time) ([]entities.Day, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]entities.Day), args.Error(1)
+}
+
+func (m *MockService) GetOverdueTasks() ([]entities.Day, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Day), args.Error(1)
+}
+
+func (m *MockService) GetCalendar(from, to time.Time) ([]entities.Occurrence, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]entities.Occurrence), args.Error(1)
+}
+
+func (m *MockService) GetTrash() ([]entities.TrashedTask, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.TrashedTask), args.Error(1)
+}
+
+func (m *MockService) RestoreTask(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockService) PurgeTask(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockService) GetCompletions(target string, from, to time.Time) ([]entities.Completion, error) {
+	args := m.Called(target, from, to)
+	return args.Get(0).([]entities.Completion), args.Error(1)
+}
+
+func (m *MockService) GetHistory(taskId string) ([]entities.Revision, error) {
+	args := m.Called(taskId)
+	return args.Get(0).([]entities.Revision), args.Error(1)
+}
+
+func (m *MockService) RevertTask(revisionId string) (entities.Task, error) {
+	args := m.Called(revisionId)
+	return args.Get(0).(entities.Task), args.Error(1)
+}
+
+func (m *MockService) Undo() (entities.Task, error) {
+	args := m.Called()
+	return args.Get(0).(entities.Task), args.Error(1)
+}
+
+func (m *MockService) Export() (entities.Export, error) {
+	args := m.Called()
+	return args.Get(0).(entities.Export), args.Error(1)
+}
+
+func (m *MockService) Import(doc entities.Export, mode string) (entities.ImportReport, error) {
+	args := m.Called(doc, mode)
+	return args.Get(0).(entities.ImportReport), args.Error(1)
+}
+
+func (m *MockService) ImportCSV(r io.Reader, mapping map[string]string, dryRun bool) (entities.ImportReport, error) {
+	args := m.Called(r, mapping, dryRun)
+	return args.Get(0).(entities.ImportReport), args.Error(1)
+}
+
+func (m *MockService) ExportText(format string) ([]byte, error) {
+	args := m.Called(format)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockService) ImportText(r io.Reader, format string, dryRun bool) (entities.ImportReport, error) {
+	args := m.Called(r, format, dryRun)
+	return args.Get(0).(entities.ImportReport), args.Error(1)
+}
+
+func (m *MockService) ImportFromApp(r io.Reader, source string, dryRun bool) (entities.ImportReport, error) {
+	args := m.Called(r, source, dryRun)
+	return args.Get(0).(entities.ImportReport), args.Error(1)
+}
+
+func (m *MockService) GetCalendarFeed(todo bool) (*ical.Component, error) {
+	args := m.Called(todo)
+	return args.Get(0).(*ical.Component), args.Error(1)
+}
+
+func (m *MockService) CreateFeedToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) CheckFeedToken(token string) (bool, error) {
+	args := m.Called(token)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) ImportICS(r io.Reader) (entities.ImportReport, error) {
+	args := m.Called(r)
+	return args.Get(0).(entities.ImportReport), args.Error(1)
+}
+
+func (m *MockService) GetCalDAVObjects() ([]entities.CalDAVObject, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.CalDAVObject), args.Error(1)
+}
+
+func (m *MockService) GetCalDAVObject(name string) (entities.CalDAVObject, error) {
+	args := m.Called(name)
+	return args.Get(0).(entities.CalDAVObject), args.Error(1)
+}
+
+func (m *MockService) PutCalDAVObject(name string, r io.Reader, version int) (bool, error) {
+	args := m.Called(name, r, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) DeleteCalDAVObject(name string, version int) error {
+	args := m.Called(name, version)
+	return args.Error(0)
+}
+
+func (m *MockService) GetReminders(taskId string) ([]entities.Reminder, error) {
+	args := m.Called(taskId)
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+func (m *MockService) SetReminders(taskId string, rules []string) ([]entities.Reminder, error) {
+	args := m.Called(taskId, rules)
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+func (m *MockService) GetPendingReminders() ([]entities.Reminder, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) GetWebhooks() ([]entities.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) AddWebhook(webhook entities.Webhook) (entities.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(webhookId string) ([]entities.Delivery, error) {
+	args := m.Called(webhookId)
+	return args.Get(0).([]entities.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(deliveryId string) (entities.Delivery, error) {
+	args := m.Called(deliveryId)
+	return args.Get(0).(entities.Delivery), args.Error(1)
+}
+
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) GetJob(taskId string) (entities.Job, error) {
+	args := m.Called(taskId)
+	return args.Get(0).(entities.Job), args.Error(1)
+}
+
+func (m *MockJobService) SetJob(taskId string, job entities.Job) (entities.Job, error) {
+	args := m.Called(taskId, job)
+	return args.Get(0).(entities.Job), args.Error(1)
+}
+
+func (m *MockJobService) ImportJobs(jobs []entities.Job, taskIds map[string]string) []entities.ImportError {
+	args := m.Called(jobs, taskIds)
+	return args.Get(0).([]entities.ImportError)
+}
+
+func (m *MockJobService) DeleteJob(taskId string) error {
+	args := m.Called(taskId)
+	return args.Error(0)
+}
+
+func (m *MockJobService) GetJobRuns(taskId string) ([]entities.JobRun, error) {
+	args := m.Called(taskId)
+	return args.Get(0).([]entities.JobRun), args.Error(1)
+}
+
 type AuthService struct {
 	mock.Mock
 }
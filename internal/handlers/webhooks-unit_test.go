@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/handlers"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhooks тестирует обработчики подписок на события задач.
+func TestWebhooks(t *testing.T) {
+	mockService := new(handlers.MockWebhookService)
+
+	webhook := entities.Webhook{Id: "1", Url: "https://ci.example.com/hook", Events: entities.EventTypes{entities.EventTaskDone}, CreatedAt: 1700000000}
+	delivery := entities.Delivery{Id: "5", WebhookId: "1", Event: entities.EventTaskDone, Payload: entities.Payload(`{"type":"task.done"}`), Status: entities.DeliveryPending}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/webhooks", handlers.GetWebhooks(mockService))
+	mux.HandleFunc("POST /api/webhooks", handlers.AddWebhook(mockService))
+	mux.HandleFunc("DELETE /api/webhooks", handlers.DeleteWebhook(mockService))
+	mux.HandleFunc("GET /api/webhooks/deliveries", handlers.GetDeliveries(mockService))
+	mux.HandleFunc("POST /api/webhooks/redeliver", handlers.Redeliver(mockService))
+
+	t.Run("successful add webhook", func(t *testing.T) {
+		body := `{"url":"https://ci.example.com/hook","events":["task.done"]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks", bytes.NewBufferString(body))
+		respRec := httptest.NewRecorder()
+
+		created := webhook
+		created.Secret = "s3cr3t"
+		mockService.On("AddWebhook", entities.Webhook{Url: webhook.Url, Events: webhook.Events}).Return(created, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusCreated, respRec.Code, "Ожидался статус 201, но получен %d", respRec.Code)
+
+		var actual entities.Webhook
+
+		err := json.NewDecoder(respRec.Body).Decode(&actual)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, created, actual)
+	})
+
+	t.Run("invalid webhook", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks", bytes.NewBufferString(`{"url":"/hook"}`))
+		respRec := httptest.NewRecorder()
+
+		mockService.On("AddWebhook", entities.Webhook{Url: "/hook"}).Return(entities.Webhook{}, entities.ErrInvalidWebhook).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusBadRequest, respRec.Code, "Ожидался статус 400, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful get webhooks", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/webhooks", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetWebhooks").Return([]entities.Webhook{webhook}, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, []entities.Webhook{webhook}, response.Webhooks)
+	})
+
+	t.Run("successful get deliveries", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("GetDeliveries", "1").Return([]entities.Delivery{delivery}, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+
+		var response entities.Result
+
+		err := json.NewDecoder(respRec.Body).Decode(&response)
+		require.NoErrorf(t, err, "Ошибка парсинга JSON-ответа: %v", err)
+
+		require.Equal(t, []entities.Delivery{delivery}, response.Deliveries)
+	})
+
+	t.Run("successful redeliver", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/redeliver?id=4", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Redeliver", "4").Return(delivery, nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusAccepted, respRec.Code, "Ожидался статус 202, но получен %d", respRec.Code)
+	})
+
+	t.Run("redeliver missing delivery", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/redeliver?id=6", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("Redeliver", "6").Return(entities.Delivery{}, entities.ErrWebhookNotFound).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusNotFound, respRec.Code, "Ожидался статус 404, но получен %d", respRec.Code)
+	})
+
+	t.Run("successful delete webhook", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/webhooks?id=1", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("DeleteWebhook", "1").Return(nil).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusOK, respRec.Code, "Ожидался статус 200, но получен %d", respRec.Code)
+	})
+
+	t.Run("valid error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/webhooks?id=2", nil)
+		respRec := httptest.NewRecorder()
+
+		mockService.On("DeleteWebhook", "2").Return(errors.New("some error")).Once()
+		mux.ServeHTTP(respRec, req)
+
+		require.Equalf(t, http.StatusInternalServerError, respRec.Code, "Ожидался статус 500, но получен %d", respRec.Code)
+	})
+}
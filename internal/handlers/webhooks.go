@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+)
+
+// GetWebhooks возвращает HTTP ответ, содержащий все подписки на события задач.
+func GetWebhooks(s services.WebhookServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := s.GetWebhooks()
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Webhooks: webhooks})
+	}
+}
+
+// AddWebhook добавляет подписку из тела запроса и возвращает HTTP ответ, содержащий
+// подписку вместе с ключом подписи. Ключ возвращается только при создании подписки.
+func AddWebhook(s services.WebhookServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var webhook entities.Webhook
+
+		if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		webhook, err := s.AddWebhook(webhook)
+		if errors.Is(err, entities.ErrInvalidWebhook) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(webhook)
+	}
+}
+
+// DeleteWebhook удаляет подписку с id, полученным из параметра запроса,
+// и возвращает пустой JSON в случае успешной обработки.
+func DeleteWebhook(s services.WebhookServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := s.DeleteWebhook(r.FormValue("id"))
+		if errors.Is(err, entities.ErrWebhookNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{})
+	}
+}
+
+// GetDeliveries возвращает HTTP ответ, содержащий последние записи журнала доставок
+// подписки с id, полученным из параметра запроса.
+func GetDeliveries(s services.WebhookServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveries, err := s.GetDeliveries(r.FormValue("id"))
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(w).Encode(entities.Result{Deliveries: deliveries})
+	}
+}
+
+// Redeliver повторно отправляет событие из записи журнала доставок с id, полученным
+// из параметра запроса, и возвращает HTTP ответ, содержащий новую запись журнала.
+func Redeliver(s services.WebhookServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		delivery, err := s.Redeliver(r.FormValue("id"))
+		if errors.Is(err, entities.ErrWebhookNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Println(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(entities.Result{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(delivery)
+	}
+}
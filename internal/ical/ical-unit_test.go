@@ -0,0 +1,95 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"task_scheduler/internal/ical"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncode тестирует запись компонентов в формате iCalendar.
+func TestEncode(t *testing.T) {
+	event := ical.NewComponent("VEVENT")
+	event.Add("DTSTART", "20240101", "VALUE", "DATE")
+	event.AddText("SUMMARY", "Встреча; план, бюджет\nитоги")
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0")
+	calendar.Components = append(calendar.Components, event)
+
+	var buf bytes.Buffer
+	require.NoError(t, ical.Encode(&buf, calendar))
+
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20240101\r\n" +
+		"SUMMARY:Встреча\\; план\\, бюджет\\nитоги\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	require.Equal(t, expected, buf.String())
+}
+
+// TestEncodeFolding тестирует перенос длинных строк без разрыва символов UTF-8.
+func TestEncodeFolding(t *testing.T) {
+	event := ical.NewComponent("VEVENT")
+	event.AddText("DESCRIPTION", strings.Repeat("задача ", 30))
+
+	var buf bytes.Buffer
+	require.NoError(t, ical.Encode(&buf, event))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	require.Greater(t, len(lines), 3)
+
+	var unfolded strings.Builder
+	for i, line := range lines {
+		require.LessOrEqual(t, len(line), 75)
+		require.True(t, strings.ToValidUTF8(line, "?") == line, "line %d is not valid UTF-8", i)
+
+		if i > 1 && i < len(lines)-1 {
+			require.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+
+		if i > 0 && i < len(lines)-1 {
+			unfolded.WriteString(line)
+		}
+	}
+
+	require.Equal(t, "DESCRIPTION:"+strings.Repeat("задача ", 30), unfolded.String())
+}
+
+// TestDecode тестирует чтение компонентов в формате iCalendar.
+func TestDecode(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:event-1@example.com\r\n" +
+		"DTSTART;TZID=\"Europe/Moscow\":20240101T100000\r\n" +
+		"SUMMARY:Встреча\\; план\\, бюд\r\n" +
+		" жет\\nитоги\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	calendar, err := ical.Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "VCALENDAR", calendar.Name)
+	require.Equal(t, "2.0", calendar.Get("VERSION").Value)
+	require.Len(t, calendar.Components, 1)
+
+	event := calendar.Components[0]
+	require.Equal(t, "VEVENT", event.Name)
+	require.Equal(t, "event-1@example.com", event.Get("UID").Value)
+	require.Equal(t, "20240101T100000", event.Get("DTSTART").Value)
+	require.Equal(t, "Europe/Moscow", event.Get("DTSTART").Params["TZID"])
+	require.Equal(t, "Встреча; план, бюджет\nитоги", ical.UnescapeText(event.Get("SUMMARY").Value))
+
+	_, err = ical.Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"))
+	require.Error(t, err)
+
+	_, err = ical.Decode(strings.NewReader("not a calendar"))
+	require.Error(t, err)
+}
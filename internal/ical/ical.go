@@ -0,0 +1,313 @@
+// Package ical реализует чтение и запись данных в формате iCalendar (RFC 5545).
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxLineLength ограничивает длину строки файла iCalendar в октетах без учета CRLF.
+const maxLineLength = 75
+
+// Property является свойством компонента iCalendar, например "SUMMARY:Задача"
+// или "DTSTART;VALUE=DATE:20240101".
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component является компонентом iCalendar (VCALENDAR, VEVENT, VTODO и т.д.)
+// со свойствами и вложенными компонентами.
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// NewComponent возвращает пустой компонент с именем name.
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add добавляет компоненту свойство name со значением value и параметрами
+// params, заданными парами "имя", "значение".
+func (c *Component) Add(name, value string, params ...string) {
+	property := Property{Name: name, Value: value}
+
+	if len(params) > 0 {
+		property.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			property.Params[params[i]] = params[i+1]
+		}
+	}
+
+	c.Properties = append(c.Properties, property)
+}
+
+// AddText добавляет компоненту текстовое свойство, экранируя специальные символы значения.
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// Get возвращает первое свойство компонента с именем name или nil, если его нет.
+func (c *Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+
+	return nil
+}
+
+// Encode записывает компонент c со всеми вложенными компонентами в w.
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+
+	if err := encodeComponent(bw, c); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// encodeComponent записывает компонент c в bw.
+func encodeComponent(bw *bufio.Writer, c *Component) error {
+	if err := writeLine(bw, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+
+	for _, property := range c.Properties {
+		if err := writeLine(bw, formatProperty(property)); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range c.Components {
+		if err := encodeComponent(bw, child); err != nil {
+			return err
+		}
+	}
+
+	return writeLine(bw, "END:"+c.Name)
+}
+
+// formatProperty возвращает строку свойства без переноса.
+func formatProperty(property Property) string {
+	var sb strings.Builder
+
+	sb.WriteString(property.Name)
+
+	// Параметры упорядочиваются, чтобы одинаковые данные давали одинаковый файл.
+	names := make([]string, 0, len(property.Params))
+	for name := range property.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := property.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + value + `"`
+		}
+
+		sb.WriteString(";" + name + "=" + value)
+	}
+
+	sb.WriteString(":" + property.Value)
+
+	return sb.String()
+}
+
+// writeLine записывает строку line, перенося ее части длиннее maxLineLength
+// октетов на следующие строки, начинающиеся с пробела. Перенос не разрывает
+// многобайтовые символы UTF-8.
+func writeLine(bw *bufio.Writer, line string) error {
+	limit := maxLineLength
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		if _, err := bw.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+
+		line = line[cut:]
+		// Строка продолжения начинается с пробела, который входит в ограничение длины.
+		limit = maxLineLength - 1
+	}
+
+	_, err := bw.WriteString(line + "\r\n")
+
+	return err
+}
+
+// isRuneStart проверяет, что байт b является первым байтом символа UTF-8.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// EscapeText экранирует специальные символы текстового значения свойства.
+func EscapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// UnescapeText восстанавливает специальные символы текстового значения свойства.
+func UnescapeText(value string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(value)
+}
+
+// Decode читает из r компонент iCalendar верхнего уровня со всеми вложенными компонентами.
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		property, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			component := NewComponent(strings.ToUpper(property.Value))
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root == nil {
+				root = component
+			} else {
+				return nil, fmt.Errorf("line %d: more than one top-level component", i+1)
+			}
+
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, property.Value)
+			}
+
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property outside of a component", i+1)
+			}
+
+			component := stack[len(stack)-1]
+			component.Properties = append(component.Properties, property)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no iCalendar component found")
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("component %s is not closed", stack[len(stack)-1].Name)
+	}
+
+	return root, nil
+}
+
+// unfoldLines читает строки из r, объединяя перенесенные строки.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseProperty разбирает строку свойства вида "ИМЯ;ПАРАМЕТР=ЗНАЧЕНИЕ:ЗНАЧЕНИЕ".
+// Значения параметров могут быть заключены в кавычки и содержать символы ":;,".
+func parseProperty(line string) (Property, error) {
+	var (
+		property Property
+		quoted   bool
+		start    int
+		parts    []string
+	)
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		case ':':
+			if quoted {
+				continue
+			}
+
+			parts = append(parts, line[start:i])
+			property.Name = strings.ToUpper(parts[0])
+			property.Value = line[i+1:]
+
+			for _, param := range parts[1:] {
+				name, value, ok := strings.Cut(param, "=")
+				if !ok {
+					return property, fmt.Errorf("invalid parameter %q", param)
+				}
+
+				if property.Params == nil {
+					property.Params = make(map[string]string)
+				}
+
+				property.Params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+			}
+
+			if property.Name == "" {
+				return property, errors.New("property name is empty")
+			}
+
+			return property, nil
+		}
+	}
+
+	return property, fmt.Errorf("invalid property line %q", line)
+}
@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"errors"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var tasksTableForAgenda = []entities.Task{
+	{
+		Id:    "1",
+		Date:  "20240220",
+		Title: "Просмотр фильма",
+	},
+	{
+		Id:    "2",
+		Date:  "20240220",
+		Title: "Сходить в бассейн",
+	},
+	{
+		Id:     "3",
+		Date:   "20240222",
+		Title:  "Оплатить коммуналку",
+		Repeat: "d 30",
+	},
+}
+
+// TestGetAgenda тестирует метод GetAgenda сервиса задач.
+func TestGetAgenda(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	from := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 6)
+
+	t.Run("get valid agenda", func(t *testing.T) {
+		mockStore.On("GetTasksByPeriod", "20240220", "20240226").Return(tasksTableForAgenda, nil)
+		days, err := s.GetAgenda(from, to)
+
+		expectedDays := []entities.Day{
+			{Date: "20240220", Tasks: tasksTableForAgenda[:2]},
+			{Date: "20240222", Tasks: tasksTableForAgenda[2:]},
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, expectedDays, days)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		_, err := s.GetAgenda(to, from)
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "GetTasksByPeriod", "20240226", "20240220")
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		mockStore.On("GetTasksByPeriod", mock.Anything, mock.Anything).Return([]entities.Task{}, errors.New("failed"))
+		days, err := s.GetAgenda(from, to)
+
+		require.Error(t, err)
+		require.Empty(t, days)
+	})
+}
+
+// TestGetOverdueTasks тестирует метод GetOverdueTasks сервиса задач.
+func TestGetOverdueTasks(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	t.Run("get valid overdue tasks", func(t *testing.T) {
+		today := time.Now().Format("20060102")
+
+		mockStore.On("GetTasksBefore", today).Return(tasksTableForAgenda[:2], nil)
+		days, err := s.GetOverdueTasks()
+
+		require.NoError(t, err)
+		require.Equal(t, []entities.Day{{Date: "20240220", Tasks: tasksTableForAgenda[:2]}}, days)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		mockStore.On("GetTasksBefore", mock.Anything).Return([]entities.Task{}, errors.New("failed"))
+		days, err := s.GetOverdueTasks()
+
+		require.Error(t, err)
+		require.Empty(t, days)
+	})
+}
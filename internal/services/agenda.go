@@ -0,0 +1,46 @@
+package services
+
+import (
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// GetAgenda возвращает задачи за период [from, to], сгруппированные по дням.
+func (s *TaskService) GetAgenda(from, to time.Time) ([]entities.Day, error) {
+	if to.Before(from) {
+		return nil, entities.ErrPeriodReversed
+	}
+
+	tasks, err := s.store.GetTasksByPeriod(from.Format("20060102"), to.Format("20060102"))
+	if err != nil {
+		return nil, err
+	}
+
+	return groupByDay(tasks), nil
+}
+
+// GetOverdueTasks возвращает просроченные задачи, то есть задачи с датой раньше
+// текущего дня, сгруппированные по дням.
+func (s *TaskService) GetOverdueTasks() ([]entities.Day, error) {
+	tasks, err := s.store.GetTasksBefore(time.Now().Format("20060102"))
+	if err != nil {
+		return nil, err
+	}
+
+	return groupByDay(tasks), nil
+}
+
+// groupByDay группирует задачи, упорядоченные по дате, по дням.
+func groupByDay(tasks []entities.Task) []entities.Day {
+	days := []entities.Day{}
+
+	for _, task := range tasks {
+		if len(days) == 0 || days[len(days)-1].Date != task.Date {
+			days = append(days, entities.Day{Date: task.Date})
+		}
+
+		days[len(days)-1].Tasks = append(days[len(days)-1].Tasks, task)
+	}
+
+	return days
+}
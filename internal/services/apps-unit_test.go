@@ -0,0 +1,143 @@
+package services_test
+
+import (
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportFromApp тестирует метод ImportFromApp сервиса задач.
+func TestImportFromApp(t *testing.T) {
+	t.Run("todoist", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		data := "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+			"section,Дом,,,,,,,,\n" +
+			"task,Просмотр фильма @досуг,Выбрать фильм,4,1,,,2099-01-01,en,Europe/Moscow\n" +
+			"task,Просмотр матча,,4,1,,,every 2 weeks,en,Europe/Moscow\n" +
+			"task,Оплата счетов,,4,1,,,every 1st and 15th at 9am,en,Europe/Moscow\n" +
+			"task,Прогулка,,4,1,,,every other month,en,Europe/Moscow\n" +
+			"task,Чтение книги,,4,1,,,Jan 3 2099,en,Europe/Moscow\n" +
+			"note,Комментарий,,,,,,,,\n" +
+			"task,,,4,1,,,,en,Europe/Moscow\n"
+
+		report, err := s.ImportFromApp(strings.NewReader(data), entities.SourceTodoist, true)
+
+		require.NoError(t, err)
+		require.Equal(t, 5, report.Created)
+		require.Len(t, report.Errors, 1)
+		require.Equal(t, 9, report.Errors[0].Line)
+		require.Len(t, report.Warnings, 1)
+		require.Equal(t, 6, report.Warnings[0].Line)
+		require.Contains(t, report.Warnings[0].Error, "every other month")
+
+		require.Equal(t, entities.Task{Date: "20990101", Title: "Просмотр фильма", Comment: "Выбрать фильм\n\n#досуг"}, report.Preview[0])
+		require.Equal(t, "d 14", report.Preview[1].Repeat)
+		require.Equal(t, "m 1,15", report.Preview[2].Repeat)
+		require.Equal(t, entities.Task{Date: time.Now().Format("20060102"), Title: "Прогулка"}, report.Preview[3])
+		require.Equal(t, "20990103", report.Preview[4].Date)
+	})
+
+	t.Run("trello", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		data := `{
+			"name": "Дом",
+			"lists": [{"id": "l1", "closed": false}, {"id": "l2", "closed": true}],
+			"cards": [
+				{"id": "c1", "name": "Просмотр фильма", "desc": "Выбрать фильм", "due": "2099-01-01T09:00:00.000Z",
+				 "idList": "l1", "labels": [{"name": "Важное дело", "color": "red"}, {"name": "", "color": "green"}]},
+				{"id": "c2", "name": "Просмотр матча", "due": "2099-01-02T09:00:00.000Z", "dueComplete": true, "idList": "l1"},
+				{"id": "c3", "name": "Чтение книги", "due": null, "closed": true, "idList": "l1"},
+				{"id": "c4", "name": "Прогулка", "due": null, "idList": "l2"}
+			],
+			"checklists": [
+				{"idCard": "c1", "name": "Покупки", "checkItems": [
+					{"name": "Напитки", "state": "complete", "pos": 2},
+					{"name": "Попкорн", "state": "incomplete", "pos": 1}
+				]}
+			]
+		}`
+
+		task := entities.Task{
+			Date:    time.Date(2099, 1, 1, 9, 0, 0, 0, time.UTC).Local().Format("20060102"),
+			Title:   "Просмотр фильма",
+			Comment: "Выбрать фильм\n\nПокупки\n- [ ] Попкорн\n- [x] Напитки\n\n#Важное_дело #green",
+		}
+		mockStore.On("PostTask", task).Return("1", nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionImport
+		})).Return(nil)
+
+		report, err := s.ImportFromApp(strings.NewReader(data), entities.SourceTrello, false)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, 3, report.Skipped)
+		require.Empty(t, report.Errors)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("microsoft to do", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		data := `[{
+			"displayName": "Задачи",
+			"tasks": [
+				{"id": "t1", "title": "Просмотр фильма", "status": "notStarted",
+				 "body": {"content": "<p>Выбрать &amp; купить</p>", "contentType": "html"},
+				 "dueDateTime": {"dateTime": "2099-01-05T00:00:00.0000000", "timeZone": "UTC"},
+				 "recurrence": {"pattern": {"type": "weekly", "interval": 1, "daysOfWeek": ["monday", "wednesday"]},
+				                "range": {"type": "noEnd"}},
+				 "categories": ["Досуг"]},
+				{"id": "t2", "title": "Оплата счетов", "status": "notStarted",
+				 "dueDateTime": {"dateTime": "2099-01-15T00:00:00.0000000", "timeZone": "UTC"},
+				 "recurrence": {"pattern": {"type": "absoluteMonthly", "interval": 1, "dayOfMonth": 15},
+				                "range": {"type": "numbered"}}},
+				{"id": "t3", "title": "Уборка", "status": "notStarted",
+				 "dueDateTime": {"dateTime": "2099-01-06T00:00:00.0000000", "timeZone": "UTC"},
+				 "recurrence": {"pattern": {"type": "relativeMonthly", "interval": 1, "daysOfWeek": ["tuesday"]}}},
+				{"id": "t4", "title": "Чтение книги", "status": "completed"},
+				{"id": "t5", "title": "Несуществующая дата", "status": "notStarted",
+				 "dueDateTime": {"dateTime": "2099-01-10T00:00:00.0000000", "timeZone": "UTC"},
+				 "recurrence": {"pattern": {"type": "absoluteYearly", "interval": 1, "dayOfMonth": 30, "month": 2}}}
+			]
+		}]`
+
+		report, err := s.ImportFromApp(strings.NewReader(data), entities.SourceMSToDo, true)
+
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Created)
+		require.Equal(t, 1, report.Skipped)
+		require.Equal(t, []entities.Task{
+			{Date: "20990105", Title: "Просмотр фильма", Comment: "Выбрать & купить\n\n#Досуг", Repeat: "w 1,3"},
+			{Date: "20990115", Title: "Оплата счетов", Repeat: "m 15"},
+			{Date: "20990106", Title: "Уборка"},
+			{Date: "20990110", Title: "Несуществующая дата"},
+		}, report.Preview)
+		require.Len(t, report.Warnings, 3)
+		require.Equal(t, []string{"t2", "t3", "t5"}, []string{report.Warnings[0].Id, report.Warnings[1].Id, report.Warnings[2].Id})
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		_, err := s.ImportFromApp(strings.NewReader("title,date\n"), entities.SourceTodoist, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.ImportFromApp(strings.NewReader(`{"name": "Дом"}`), entities.SourceTrello, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.ImportFromApp(strings.NewReader(`{}`), entities.SourceMSToDo, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.ImportFromApp(strings.NewReader(""), "asana", true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+	})
+}
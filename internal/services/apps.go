@@ -0,0 +1,662 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+var (
+	// todoistTime соответствует времени в конце даты Todoist, например " at 9am".
+	todoistTime = regexp.MustCompile(`\s+(at|@)\s+.*$`)
+	// todoistMonthDay соответствует числу месяца в повторении Todoist, например "15th".
+	todoistMonthDay = regexp.MustCompile(`^([0-9]{1,2})(st|nd|rd|th)?$`)
+	// htmlTag соответствует тегу HTML в описании задачи Microsoft To Do.
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// todoistDateLayouts перечисляет распознаваемые форматы дат Todoist, записанных словами.
+var todoistDateLayouts = []string{
+	"Jan 2 2006",
+	"Jan 2, 2006",
+	"January 2 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// todoistPeriods сопоставляет сокращенные повторения Todoist периодам повторения.
+var todoistPeriods = map[string]string{
+	"daily":    "day",
+	"weekly":   "week",
+	"monthly":  "month",
+	"yearly":   "year",
+	"annually": "year",
+}
+
+// weekdayNames сопоставляет английские названия дней недели порядковым номерам правила "w".
+var weekdayNames = map[string]string{
+	"mon": "1", "monday": "1",
+	"tue": "2", "tues": "2", "tuesday": "2",
+	"wed": "3", "wednesday": "3",
+	"thu": "4", "thur": "4", "thurs": "4", "thursday": "4",
+	"fri": "5", "friday": "5",
+	"sat": "6", "saturday": "6",
+	"sun": "7", "sunday": "7",
+}
+
+// appTask является задачей, прочитанной из файла экспорта другого приложения.
+// date содержит дату задачи в формате 20060102, а warnings - данные задачи,
+// которые не удалось перенести.
+type appTask struct {
+	line     int
+	id       string
+	done     bool
+	title    string
+	comment  string
+	labels   []string
+	date     string
+	repeat   string
+	warnings []string
+}
+
+// trelloBoard является структурой файла JSON, экспортированного из доски Trello.
+type trelloBoard struct {
+	Lists []struct {
+		Id     string `json:"id"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		Id          string `json:"id"`
+		Name        string `json:"name"`
+		Desc        string `json:"desc"`
+		Due         string `json:"due"`
+		DueComplete bool   `json:"dueComplete"`
+		Closed      bool   `json:"closed"`
+		IdList      string `json:"idList"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IdCard     string            `json:"idCard"`
+		Name       string            `json:"name"`
+		CheckItems []trelloCheckItem `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// trelloCheckItem является структурой пункта чек-листа Trello.
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// msToDoTask является структурой задачи Microsoft To Do в формате Microsoft Graph.
+// У списков задач заполнены только DisplayName и Tasks.
+type msToDoTask struct {
+	Id     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Body   struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	DueDateTime *struct {
+		DateTime string `json:"dateTime"`
+	} `json:"dueDateTime"`
+	Recurrence *msRecurrence `json:"recurrence"`
+	Categories []string      `json:"categories"`
+
+	DisplayName string       `json:"displayName"`
+	Tasks       []msToDoTask `json:"tasks"`
+}
+
+// msRecurrence является структурой правила повторения задачи Microsoft Graph.
+type msRecurrence struct {
+	Pattern struct {
+		Type       string   `json:"type"`
+		Interval   int      `json:"interval"`
+		Month      int      `json:"month"`
+		DayOfMonth int      `json:"dayOfMonth"`
+		DaysOfWeek []string `json:"daysOfWeek"`
+	} `json:"pattern"`
+	Range struct {
+		Type string `json:"type"`
+	} `json:"range"`
+}
+
+// ImportFromApp добавляет задачи из файла экспорта приложения source: CSV проекта Todoist
+// (entities.SourceTodoist), JSON доски Trello (entities.SourceTrello) или JSON задач
+// Microsoft To Do (entities.SourceMSToDo). Описания задач и чек-листы Trello переносятся
+// в комментарий, а метки добавляются в его конец в виде "#метка". Правила повторения
+// переводятся в правила повторения задач. Повторения и даты, которые не удалось перевести,
+// не прерывают импорт задачи, а попадают в отчет как предупреждения. Выполненные
+// и архивные задачи пропускаются. При dryRun задачи не сохраняются.
+func (s *TaskService) ImportFromApp(r io.Reader, source string, dryRun bool) (entities.ImportReport, error) {
+	report := entities.ImportReport{Errors: []entities.ImportError{}, DryRun: dryRun}
+
+	var (
+		items []appTask
+		err   error
+	)
+
+	switch source {
+	case entities.SourceTodoist:
+		items, err = readTodoist(r)
+	case entities.SourceTrello:
+		items, err = readTrello(r)
+	case entities.SourceMSToDo:
+		items, err = readMSToDo(r)
+	default:
+		return report, fmt.Errorf("%w: unknown source %q", entities.ErrInvalidImport, source)
+	}
+
+	if err != nil {
+		return report, err
+	}
+
+	for i, item := range items {
+		if item.done {
+			report.Skipped++
+			continue
+		}
+
+		task, err := s.appTaskToTask(item)
+		if err == nil && !dryRun {
+			task.Id, err = s.addTask(task, entities.ActionImport)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Line: item.line, Id: item.id, Error: err.Error()})
+			continue
+		}
+
+		for _, warning := range item.warnings {
+			report.Warnings = append(report.Warnings, entities.ImportError{Index: i, Line: item.line, Id: item.id, Error: warning})
+		}
+
+		if dryRun {
+			report.Preview = append(report.Preview, task)
+		}
+
+		report.Created++
+	}
+
+	return report, nil
+}
+
+// appTaskToTask возвращает задачу из задачи другого приложения, проверенную по тем же
+// правилам, что и в AddTask. Повторяющаяся задача без даты получает дату первого повторения.
+func (s *TaskService) appTaskToTask(item appTask) (entities.Task, error) {
+	task := entities.Task{
+		Date:    item.date,
+		Title:   strings.TrimSpace(item.title),
+		Comment: strings.TrimSpace(item.comment),
+		Repeat:  item.repeat,
+	}
+
+	if len(item.labels) > 0 {
+		tags := make([]string, len(item.labels))
+		for i, label := range item.labels {
+			tags[i] = "#" + strings.Join(strings.Fields(label), "_")
+		}
+
+		task.Comment = strings.TrimSpace(task.Comment + "\n\n" + strings.Join(tags, " "))
+	}
+
+	if task.Date == "" && (strings.HasPrefix(task.Repeat, "w ") || strings.HasPrefix(task.Repeat, "m ")) {
+		yesterday := time.Now().AddDate(0, 0, -1)
+
+		date, err := s.GetNextDate(yesterday, yesterday.Format("20060102"), task.Repeat)
+		if err != nil {
+			return task, err
+		}
+
+		task.Date = date
+	}
+
+	return s.validateImportedTask(task)
+}
+
+// readTodoist читает задачи из файла CSV, экспортированного из проекта Todoist.
+// Разделы и комментарии (строки со значением TYPE, отличным от "task") пропускаются.
+func readTodoist(r io.Reader) ([]appTask, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+
+	_, hasType := index["TYPE"]
+	_, hasContent := index["CONTENT"]
+	if !hasType || !hasContent {
+		return nil, fmt.Errorf("%w: the file is not a Todoist CSV export", entities.ErrInvalidImport)
+	}
+
+	value := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []appTask
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+		}
+
+		if !strings.EqualFold(value(record, "TYPE"), "task") {
+			continue
+		}
+
+		item := appTask{comment: value(record, "DESCRIPTION")}
+		item.line, _ = reader.FieldPos(0)
+
+		words := strings.Fields(value(record, "CONTENT"))
+		for _, word := range words {
+			if len(word) > 1 && strings.HasPrefix(word, "@") {
+				item.labels = append(item.labels, strings.TrimPrefix(word, "@"))
+			} else {
+				item.title += " " + word
+			}
+		}
+
+		if date := value(record, "DATE"); date != "" {
+			var err error
+			if item.date, item.repeat, err = todoistDate(date, time.Now()); err != nil {
+				item.warnings = append(item.warnings, err.Error())
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// todoistDate переводит дату Todoist value в дату задачи или, если value описывает
+// повторение, в правило повторения задачи. Ежемесячное повторение без числа месяца
+// переводится с числом месяца из даты now.
+func todoistDate(value string, now time.Time) (string, string, error) {
+	value = strings.ToLower(todoistTime.ReplaceAllString(strings.TrimSpace(value), ""))
+
+	if period, ok := todoistPeriods[value]; ok {
+		value = "every " + period
+	}
+
+	var rest string
+
+	switch {
+	case strings.HasPrefix(value, "every!"):
+		rest = strings.TrimPrefix(value, "every!")
+	case strings.HasPrefix(value, "every "), strings.HasPrefix(value, "after "):
+		_, rest, _ = strings.Cut(value, " ")
+	case value == "today":
+		return now.Format("20060102"), "", nil
+	case value == "tomorrow":
+		return now.AddDate(0, 0, 1).Format("20060102"), "", nil
+	default:
+		if date, err := parseCSVDate(value); err == nil {
+			return date, "", nil
+		}
+
+		for _, layout := range todoistDateLayouts {
+			if date, err := time.Parse(layout, value); err == nil {
+				return date.Format("20060102"), "", nil
+			}
+		}
+
+		return "", "", fmt.Errorf("the date %q cannot be translated", value)
+	}
+
+	repeat, ok := todoistRepeat(rest, now)
+	if !ok {
+		return "", "", fmt.Errorf("the recurrence %q cannot be translated", value)
+	}
+
+	return "", repeat, nil
+}
+
+// todoistRepeat переводит повторение Todoist без начального "every" в правило повторения задачи.
+func todoistRepeat(value string, now time.Time) (string, bool) {
+	value = strings.ReplaceAll(value, "last day", "-1")
+
+	var fields []string
+	for _, field := range strings.Fields(strings.ReplaceAll(value, ",", " ")) {
+		if field != "and" && field != "the" {
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	interval := 1
+	if len(fields) == 2 {
+		if fields[0] == "other" {
+			interval = 2
+			fields = fields[1:]
+		} else if n, err := strconv.Atoi(fields[0]); err == nil && n > 0 {
+			interval = n
+			fields = fields[1:]
+		}
+	}
+
+	if len(fields) == 1 {
+		switch strings.TrimSuffix(fields[0], "s") {
+		case "day":
+			if interval <= 366 {
+				return "d " + strconv.Itoa(interval), true
+			}
+		case "week":
+			if interval*7 <= 366 {
+				return "d " + strconv.Itoa(interval*7), true
+			}
+		case "month":
+			if interval == 1 {
+				return "m " + strconv.Itoa(now.Day()), true
+			}
+		case "year":
+			if interval == 1 {
+				return "y", true
+			}
+		case "weekday", "workday":
+			if interval == 1 {
+				return "w 1,2,3,4,5", true
+			}
+		case "weekend":
+			if interval == 1 {
+				return "w 6,7", true
+			}
+		}
+	}
+
+	if interval != 1 {
+		return "", false
+	}
+
+	if days, ok := mapFields(fields, func(field string) (string, bool) {
+		day, ok := weekdayNames[field]
+		return day, ok
+	}); ok {
+		return "w " + days, true
+	}
+
+	if days, ok := mapFields(fields, func(field string) (string, bool) {
+		if field == "-1" {
+			return field, true
+		}
+
+		match := todoistMonthDay.FindStringSubmatch(field)
+		if match == nil {
+			return "", false
+		}
+
+		day, _ := strconv.Atoi(match[1])
+		return match[1], day >= 1 && day <= 31
+	}); ok {
+		return "m " + days, true
+	}
+
+	return "", false
+}
+
+// mapFields переводит каждое из значений fields функцией convert и возвращает результаты
+// через запятую. Возвращает false, если хотя бы одно из значений не удалось перевести.
+func mapFields(fields []string, convert func(string) (string, bool)) (string, bool) {
+	values := make([]string, len(fields))
+
+	for i, field := range fields {
+		value, ok := convert(field)
+		if !ok {
+			return "", false
+		}
+
+		values[i] = value
+	}
+
+	return strings.Join(values, ","), true
+}
+
+// readTrello читает задачи из карточек файла JSON, экспортированного из доски Trello.
+// Срок карточки становится датой задачи, описание и чек-листы - комментарием.
+// Архивные и выполненные карточки, а также карточки архивных списков пропускаются.
+func readTrello(r io.Reader) ([]appTask, error) {
+	var board trelloBoard
+
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	if board.Cards == nil {
+		return nil, fmt.Errorf("%w: the file is not a Trello board export", entities.ErrInvalidImport)
+	}
+
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		closedLists[list.Id] = list.Closed
+	}
+
+	checklists := make(map[string][]string)
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		slices.SortStableFunc(items, func(a, b trelloCheckItem) int {
+			return cmp.Compare(a.Pos, b.Pos)
+		})
+
+		lines := []string{checklist.Name}
+		for _, item := range items {
+			mark := " "
+			if item.State == "complete" {
+				mark = "x"
+			}
+
+			lines = append(lines, "- ["+mark+"] "+item.Name)
+		}
+
+		checklists[checklist.IdCard] = append(checklists[checklist.IdCard], strings.Join(lines, "\n"))
+	}
+
+	items := make([]appTask, 0, len(board.Cards))
+
+	for _, card := range board.Cards {
+		item := appTask{
+			id:      card.Id,
+			done:    card.Closed || card.DueComplete || closedLists[card.IdList],
+			title:   card.Name,
+			comment: strings.Join(append([]string{card.Desc}, checklists[card.Id]...), "\n\n"),
+		}
+
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				item.labels = append(item.labels, label.Name)
+			} else if label.Color != "" {
+				item.labels = append(item.labels, label.Color)
+			}
+		}
+
+		if card.Due != "" {
+			due, err := time.Parse(time.RFC3339, card.Due)
+			if err != nil {
+				item.warnings = append(item.warnings, fmt.Sprintf("the due date %q cannot be translated", card.Due))
+			} else {
+				item.date = due.Local().Format("20060102")
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// readMSToDo читает задачи Microsoft To Do в формате Microsoft Graph. Файл может содержать
+// массив задач, ответ Graph с задачами в поле value или списки задач с задачами в поле tasks
+// (в виде массива или в поле lists). Выполненные задачи пропускаются.
+func readMSToDo(r io.Reader) ([]appTask, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []msToDoTask
+
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		var doc struct {
+			Value []msToDoTask `json:"value"`
+			Lists []msToDoTask `json:"lists"`
+		}
+
+		err = json.Unmarshal(data, &doc)
+		entries = append(doc.Value, doc.Lists...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: the file does not contain Microsoft To Do tasks", entities.ErrInvalidImport)
+	}
+
+	var tasks []msToDoTask
+	for _, entry := range entries {
+		if entry.Tasks != nil || entry.DisplayName != "" {
+			tasks = append(tasks, entry.Tasks...)
+		} else {
+			tasks = append(tasks, entry)
+		}
+	}
+
+	items := make([]appTask, 0, len(tasks))
+
+	for _, task := range tasks {
+		item := appTask{
+			id:      task.Id,
+			done:    task.Status == "completed",
+			title:   task.Title,
+			comment: task.Body.Content,
+			labels:  task.Categories,
+		}
+
+		if strings.EqualFold(task.Body.ContentType, "html") {
+			item.comment = html.UnescapeString(htmlTag.ReplaceAllString(task.Body.Content, ""))
+		}
+
+		date := time.Now()
+		if task.DueDateTime != nil {
+			due, err := time.Parse(time.DateOnly, task.DueDateTime.DateTime[:min(len(task.DueDateTime.DateTime), 10)])
+			if err != nil {
+				item.warnings = append(item.warnings, fmt.Sprintf("the due date %q cannot be translated", task.DueDateTime.DateTime))
+			} else {
+				date = due
+				item.date = due.Format("20060102")
+			}
+		}
+
+		if task.Recurrence != nil {
+			repeat, err := graphRepeat(*task.Recurrence, date, item.date != "")
+			if err != nil {
+				item.warnings = append(item.warnings, err.Error())
+			}
+
+			item.repeat = repeat
+
+			if task.Recurrence.Range.Type != "" && task.Recurrence.Range.Type != "noEnd" {
+				item.warnings = append(item.warnings, "the end of the recurrence cannot be translated")
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// graphRepeat переводит правило повторения Microsoft Graph в правило повторения задачи
+// с датой date. hasDate указывает, что дата задана в задаче, а не выбрана по умолчанию.
+func graphRepeat(recurrence msRecurrence, date time.Time, hasDate bool) (string, error) {
+	pattern := recurrence.Pattern
+	interval := max(pattern.Interval, 1)
+	unsupported := fmt.Errorf("the %s recurrence with interval %d cannot be translated", pattern.Type, interval)
+
+	switch pattern.Type {
+	case "daily":
+		if interval <= 366 {
+			return "d " + strconv.Itoa(interval), nil
+		}
+	case "weekly":
+		days, ok := mapFields(pattern.DaysOfWeek, func(field string) (string, bool) {
+			day, ok := weekdayNames[strings.ToLower(field)]
+			return day, ok
+		})
+
+		if !ok {
+			return "", unsupported
+		}
+
+		if interval == 1 && days != "" {
+			return "w " + days, nil
+		}
+
+		weekday := strconv.Itoa((int(date.Weekday())+6)%7 + 1)
+		if interval*7 <= 366 && (days == "" || (days == weekday && hasDate)) {
+			return "d " + strconv.Itoa(interval*7), nil
+		}
+	case "absoluteMonthly":
+		if interval == 1 {
+			day := pattern.DayOfMonth
+			if day == 0 {
+				day = date.Day()
+			}
+
+			return "m " + strconv.Itoa(day), nil
+		}
+	case "absoluteYearly":
+		if interval == 1 {
+			if pattern.DayOfMonth == 0 || pattern.Month == 0 ||
+				(pattern.DayOfMonth == date.Day() && pattern.Month == int(date.Month()) && hasDate) {
+				return "y", nil
+			}
+
+			day, month := strconv.Itoa(pattern.DayOfMonth), strconv.Itoa(pattern.Month)
+			if monthDaysOccur(day, month) {
+				return "m " + day + " " + month, nil
+			}
+		}
+	}
+
+	return "", unsupported
+}
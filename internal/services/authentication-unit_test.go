@@ -57,7 +57,7 @@ func TestCheckJWTMiddleware(t *testing.T) {
 	// Создание обработчика для тестирования
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		w.Write([]byte("OK " + services.RequestSession(r)))
 	})
 	handler := services.CheckJWTMiddleware(nextHandler)
 
@@ -75,6 +75,7 @@ func TestCheckJWTMiddleware(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, respRec.Code)
 		require.Contains(t, actualResponse, expectedResponse)
+		require.Len(t, actualResponse, len("OK ")+16, "Ожидался идентификатор сессии из токена")
 	})
 
 	t.Run("invalid token", func(t *testing.T) {
@@ -93,3 +94,32 @@ func TestCheckJWTMiddleware(t *testing.T) {
 		require.Contains(t, actualResponse, expectedResponse)
 	})
 }
+
+// TestCheckBasicAuthMiddleware тестирует проверку пароля по схеме Basic.
+func TestCheckBasicAuthMiddleware(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := services.CheckBasicAuthMiddleware(nextHandler)
+
+	t.Run("valid password", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("user", config.Password)
+		respRec := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRec, req)
+
+		require.Equal(t, http.StatusOK, respRec.Code)
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("user", "invalid_password")
+		respRec := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRec, req)
+
+		require.Equal(t, http.StatusUnauthorized, respRec.Code)
+		require.Contains(t, respRec.Header().Get("WWW-Authenticate"), "Basic")
+	})
+}
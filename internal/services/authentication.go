@@ -1,7 +1,11 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"log"
 	"net/http"
@@ -12,6 +16,9 @@ import (
 
 type AuthService struct{}
 
+// sessionKey является ключом идентификатора сессии в контексте запроса.
+type sessionKey struct{}
+
 func GetAuthService() *AuthService {
 	return &AuthService{}
 }
@@ -83,12 +90,45 @@ func CheckJWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if sid, ok := payLoad["sid"].(string); ok {
+			r = r.WithContext(context.WithValue(r.Context(), sessionKey{}, sid))
+		}
+
 		next(w, r)
 	}
 }
 
+// CheckBasicAuthMiddleware проверяет пароль, переданный в заголовке Authorization
+// по схеме Basic, для клиентов, которые не могут использовать JWT из cookie (например, CalDAV).
+// Имя пользователя не проверяется.
+func CheckBasicAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(config.Password) == 0 {
+			next(w, r)
+			return
+		}
+
+		_, password, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(config.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Task Scheduler", charset="UTF-8"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// RequestSession возвращает идентификатор сессии из токена запроса, прошедшего
+// проверку в CheckJWTMiddleware, или пустую строку, если сессия не определена.
+func RequestSession(r *http.Request) string {
+	sid, _ := r.Context().Value(sessionKey{}).(string)
+	return sid
+}
+
 // GetJWT генерирует JWT токен, используя PASSWORD из переменных окружения и
-// checksum пароля, вложенного в Claims токена.
+// checksum пароля, вложенного в Claims токена. Каждый токен получает
+// случайный идентификатор сессии.
 func (a *AuthService) GetJWT(password string) (string, error) {
 	var (
 		signedToken string
@@ -102,7 +142,15 @@ func (a *AuthService) GetJWT(password string) (string, error) {
 
 	secret := []byte("secret_key")
 
-	jwtToken = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sum": sha256.Sum256([]byte(password))})
+	sid := make([]byte, 8)
+	if _, err = rand.Read(sid); err != nil {
+		return "", err
+	}
+
+	jwtToken = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sum": sha256.Sum256([]byte(password)),
+		"sid": hex.EncodeToString(sid),
+	})
 
 	signedToken, err = jwtToken.SignedString(secret)
 
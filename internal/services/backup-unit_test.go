@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateBackup тестирует метод CreateBackup сервиса резервного копирования.
+func TestCreateBackup(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("backup is written to the file", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		b := services.GetBackupService(mockStore)
+
+		mockStore.On("Backup", mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(0).(io.Writer).Write([]byte("backup"))
+		}).Return(nil)
+
+		path := filepath.Join(dir, "ok.tar.gz")
+		require.NoError(t, b.CreateBackup(path))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "backup", string(data))
+	})
+
+	t.Run("failed backup leaves no file", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		b := services.GetBackupService(mockStore)
+
+		mockStore.On("Backup", mock.Anything).Return(errors.New("database is locked"))
+
+		path := filepath.Join(dir, "failed.tar.gz")
+		require.Error(t, b.CreateBackup(path))
+
+		_, err := os.Stat(path)
+		require.True(t, os.IsNotExist(err))
+		_, err = os.Stat(path + ".tmp")
+		require.True(t, os.IsNotExist(err))
+	})
+}
+
+// TestRotateBackups тестирует удаление старых резервных копий методом RotateBackups.
+func TestRotateBackups(t *testing.T) {
+	dir := t.TempDir()
+
+	old := []string{"scheduler-20240101-000000.tar.gz", "scheduler-20240102-000000.tar.gz", "scheduler-20240103-000000.tar.gz"}
+	for _, name := range old {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old"), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o644))
+
+	mockStore := new(services.MockStorage)
+	b := services.GetBackupService(mockStore)
+	mockStore.On("Backup", mock.Anything).Return(nil)
+
+	path, err := b.RotateBackups(dir, 2)
+	require.NoError(t, err)
+
+	backups, err := filepath.Glob(filepath.Join(dir, "scheduler-*.tar.gz"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, old[2]), path}, backups)
+
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	require.NoError(t, err)
+}
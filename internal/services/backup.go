@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+// backupPattern задает шаблон имен файлов резервных копий, создаваемых по расписанию.
+const backupPattern = "scheduler-*.tar.gz"
+
+type BackupService struct {
+	store storage.BackupInterface
+}
+
+func GetBackupService(store storage.BackupInterface) *BackupService {
+	return &BackupService{store: store}
+}
+
+// CreateBackup создает резервную копию БД в файле path.
+// Копия сначала записывается во временный файл, чтобы прерванное создание
+// не оставило поврежденный архив.
+func (b *BackupService) CreateBackup(path string) error {
+	tmp := path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	if err := b.store.Backup(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// RestoreBackup восстанавливает БД из резервной копии в файле path.
+func (b *BackupService) RestoreBackup(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	return b.store.Restore(file)
+}
+
+// RotateBackups создает новую резервную копию в каталоге dir и удаляет самые старые копии,
+// оставляя не более keep файлов. Возвращает путь к созданной копии.
+func (b *BackupService) RotateBackups(dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "scheduler-"+time.Now().UTC().Format("20060102-150405")+".tar.gz")
+	if err := b.CreateBackup(path); err != nil {
+		return "", err
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, backupPattern))
+	if err != nil {
+		return path, err
+	}
+
+	// Имена файлов содержат время создания, поэтому сортировка по имени
+	// упорядочивает копии от старых к новым.
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return path, err
+		}
+
+		backups = backups[1:]
+	}
+
+	return path, nil
+}
+
+// RunScheduledBackup с периодичностью interval создает резервные копии в каталоге dir,
+// храня не более keep последних копий, до отмены контекста ctx.
+func (b *BackupService) RunScheduledBackup(ctx context.Context, dir string, interval time.Duration, keep int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		path, err := b.RotateBackups(dir, keep)
+		if err != nil {
+			log.Printf("failed to create scheduled backup: %s\n", err.Error())
+			continue
+		}
+
+		log.Printf("Backup created: %s\n", path)
+	}
+}
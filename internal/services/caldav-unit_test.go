@@ -0,0 +1,140 @@
+package services_test
+
+import (
+	"database/sql"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCalDAVObjects тестирует представление задач ресурсами CalDAV.
+func TestGetCalDAVObjects(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	tasks := []entities.Task{
+		{Id: "1", Date: "20990101", Title: "Просмотр фильма", Version: 1},
+		{Id: "2", Date: "20990102", Title: "Просмотр матча", Version: 3},
+	}
+
+	mockStore.On("GetTasks").Return(tasks, nil)
+	mockStore.On("GetCalDAVResources").Return([]entities.CalDAVResource{
+		{Id: "1", Name: "a1b2.ics", TaskId: "2", UID: "a1b2"},
+		{Id: "2", Name: "stale.ics", TaskId: "9", UID: "stale"},
+	}, nil)
+
+	objects, err := s.GetCalDAVObjects()
+
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Equal(t, "1.ics", objects[0].Name)
+	require.Equal(t, "task-1@task_scheduler", objects[0].UID)
+	require.Equal(t, "a1b2.ics", objects[1].Name)
+	require.Equal(t, "a1b2", objects[1].UID)
+	require.Contains(t, objects[1].Data, "BEGIN:VTODO\r\nUID:a1b2\r\n")
+
+	mockStore.On("SearchTask", "1").Return(tasks[0], nil)
+	mockStore.On("SearchTask", "2").Return(tasks[1], nil)
+	mockStore.On("SearchTask", "9").Return(entities.Task{}, sql.ErrNoRows)
+
+	object, err := s.GetCalDAVObject("a1b2.ics")
+	require.NoError(t, err)
+	require.Equal(t, objects[1].Task, object.Task)
+	require.Equal(t, objects[1].Data, object.Data)
+
+	object, err = s.GetCalDAVObject("1.ics")
+	require.NoError(t, err)
+	require.Equal(t, objects[0].UID, object.UID)
+
+	for _, name := range []string{"stale.ics", "2.ics", "new.ics"} {
+		_, err = s.GetCalDAVObject(name)
+		require.ErrorIs(t, err, entities.ErrResourceNotFound, name)
+	}
+}
+
+// TestPutCalDAVObject тестирует создание, изменение и выполнение задач через ресурсы CalDAV.
+func TestPutCalDAVObject(t *testing.T) {
+	todo := func(extra string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:a1b2\r\n" +
+			"SUMMARY:Просмотр матча\r\nDUE;VALUE=DATE:20990105\r\n" + extra + "END:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+
+	existing := entities.Task{Id: "2", Date: "20990102", Title: "Просмотр фильма", Version: 3}
+
+	newStore := func() (*services.MockStorage, services.TaskServiceInterface) {
+		mockStore := new(services.MockStorage)
+		mockStore.On("GetTasks").Return([]entities.Task{existing}, nil)
+		mockStore.On("GetCalDAVResources").Return([]entities.CalDAVResource{{Name: "a1b2.ics", TaskId: "2", UID: "a1b2"}}, nil)
+		mockStore.On("SearchTask", "2").Return(existing, nil)
+		mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+		return mockStore, services.GetTaskService(mockStore)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		mockStore, s := newStore()
+		mockStore.On("PostTask", entities.Task{Date: "20990105", Title: "Просмотр матча"}).Return("5", nil)
+		mockStore.On("SetCalDAVResource", entities.CalDAVResource{Name: "new.ics", TaskId: "5", UID: "a1b2"}).Return(nil)
+
+		created, err := s.PutCalDAVObject("new.ics", strings.NewReader(todo("")), 0)
+
+		require.NoError(t, err)
+		require.True(t, created)
+	})
+
+	t.Run("create completed", func(t *testing.T) {
+		mockStore, s := newStore()
+
+		created, err := s.PutCalDAVObject("new.ics", strings.NewReader(todo("STATUS:COMPLETED\r\n")), 0)
+
+		require.NoError(t, err)
+		require.False(t, created)
+		mockStore.AssertNotCalled(t, "PostTask", mock.Anything)
+		mockStore.AssertNotCalled(t, "SetCalDAVResource", mock.Anything)
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		mockStore, s := newStore()
+		mockStore.On("UpdateTask", entities.Task{Id: "2", Date: "20990105", Title: "Просмотр матча", Version: 3}).Return(nil)
+		mockStore.On("GetReminders", "2").Return([]entities.Reminder{}, nil)
+
+		created, err := s.PutCalDAVObject("a1b2.ics", strings.NewReader(todo("")), 3)
+
+		require.NoError(t, err)
+		require.False(t, created)
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		_, s := newStore()
+
+		_, err := s.PutCalDAVObject("a1b2.ics", strings.NewReader(todo("")), 2)
+
+		require.ErrorIs(t, err, entities.ErrVersionMismatch)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		mockStore, s := newStore()
+		mockStore.On("DeleteTask", "2", 3).Return(nil)
+		mockStore.On("DeleteCalDAVResource", "2").Return(nil)
+		mockStore.On("AddCompletion", mock.Anything).Return(nil)
+
+		created, err := s.PutCalDAVObject("a1b2.ics", strings.NewReader(todo("STATUS:COMPLETED\r\n")), 3)
+
+		require.NoError(t, err)
+		require.False(t, created)
+		mockStore.AssertCalled(t, "AddCompletion", mock.Anything)
+		mockStore.AssertCalled(t, "DeleteCalDAVResource", "2")
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		_, s := newStore()
+
+		_, err := s.PutCalDAVObject("a1b2.ics", strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), 0)
+
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+	})
+}
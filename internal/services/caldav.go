@@ -0,0 +1,225 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/ical"
+	"time"
+)
+
+// GetCalDAVObjects возвращает все задачи в виде ресурсов коллекции CalDAV.
+// Задачи, созданные клиентами CalDAV, сохраняют выбранные клиентом имя ресурса и UID,
+// а остальные задачи представляются ресурсами "<id>.ics".
+func (s *TaskService) GetCalDAVObjects() ([]entities.CalDAVObject, error) {
+	tasks, err := s.store.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := s.store.GetCalDAVResources()
+	if err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[string]entities.CalDAVResource, len(resources))
+	for _, resource := range resources {
+		byTask[resource.TaskId] = resource
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	objects := make([]entities.CalDAVObject, 0, len(tasks))
+
+	for _, task := range tasks {
+		object := entities.CalDAVObject{Name: task.Id + ".ics", UID: taskUID(task.Id), Task: task}
+		if resource, ok := byTask[task.Id]; ok {
+			object.Name = resource.Name
+			if resource.UID != "" {
+				object.UID = resource.UID
+			}
+		}
+
+		// Задача, которую не удалось представить ресурсом, пропускается, чтобы клиенты
+		// могли синхронизировать остальные задачи коллекции.
+		if object.Data, err = renderCalDAVObject(object, stamp); err != nil {
+			log.Printf("failed to render the CalDAV resource of task %s: %s\n", task.Id, err.Error())
+			continue
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// GetCalDAVObject возвращает задачу, представленную ресурсом CalDAV с именем name.
+// Если ресурса нет, то возвращается entities.ErrResourceNotFound.
+func (s *TaskService) GetCalDAVObject(name string) (entities.CalDAVObject, error) {
+	resources, err := s.store.GetCalDAVResources()
+	if err != nil {
+		return entities.CalDAVObject{}, err
+	}
+
+	var object entities.CalDAVObject
+	mapped := make(map[string]bool, len(resources))
+
+	for _, resource := range resources {
+		mapped[resource.TaskId] = true
+
+		if resource.Name == name {
+			object = entities.CalDAVObject{Name: name, UID: resource.UID, Task: entities.Task{Id: resource.TaskId}}
+		}
+	}
+
+	// Ресурсы задач, не созданных клиентами, называются "<id>.ics".
+	if object.Name == "" {
+		id, ok := strings.CutSuffix(name, ".ics")
+		if _, err := strconv.Atoi(id); !ok || err != nil || mapped[id] {
+			return entities.CalDAVObject{}, entities.ErrResourceNotFound
+		}
+
+		object = entities.CalDAVObject{Name: name, Task: entities.Task{Id: id}}
+	}
+
+	if object.UID == "" {
+		object.UID = taskUID(object.Task.Id)
+	}
+
+	object.Task, err = s.store.SearchTask(object.Task.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.CalDAVObject{}, entities.ErrResourceNotFound
+	}
+
+	if err != nil {
+		return entities.CalDAVObject{}, err
+	}
+
+	object.Data, err = renderCalDAVObject(object, time.Now().UTC().Format("20060102T150405Z"))
+	if err != nil {
+		return entities.CalDAVObject{}, err
+	}
+
+	return object, nil
+}
+
+// PutCalDAVObject сохраняет компонент VTODO из r в ресурс CalDAV с именем name.
+// Изменение существующей задачи выполняется как EditTask, а отметка о выполнении
+// (STATUS:COMPLETED, COMPLETED или PERCENT-COMPLETE:100) - как DoneTask.
+// Если version не равна нулю, то изменение выполняется только при совпадении версии задачи.
+// Новый ресурс с уже выполненной задачей без повторения не создается.
+// Возвращает true, если ресурс был создан.
+func (s *TaskService) PutCalDAVObject(name string, r io.Reader, version int) (bool, error) {
+	calendar, err := ical.Decode(r)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	var todo *ical.Component
+	for _, component := range calendar.Components {
+		if component.Name == "VTODO" {
+			todo = component
+			break
+		}
+	}
+
+	if calendar.Name != "VCALENDAR" || todo == nil {
+		return false, fmt.Errorf("%w: the resource must contain a VTODO component", entities.ErrInvalidImport)
+	}
+
+	task, err := s.componentToTask(todo)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	var uid string
+	if property := todo.Get("UID"); property != nil {
+		uid = property.Value
+	}
+
+	object, err := s.GetCalDAVObject(name)
+	if errors.Is(err, entities.ErrResourceNotFound) {
+		// Выполненная задача без повторения сразу удалилась бы, поэтому ресурс не создается.
+		if todoCompleted(todo) && task.Repeat == "" {
+			return false, nil
+		}
+
+		id, err := s.AddTask(task)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.store.SetCalDAVResource(entities.CalDAVResource{Name: name, TaskId: id, UID: uid}); err != nil {
+			return false, err
+		}
+
+		if todoCompleted(todo) {
+			return true, s.DoneTask(id)
+		}
+
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if version != 0 && object.Task.Version != version {
+		return false, entities.ErrVersionMismatch
+	}
+
+	if todoCompleted(todo) {
+		return false, s.DoneTask(object.Task.Id)
+	}
+
+	task.Id, task.Version = object.Task.Id, version
+
+	return false, s.EditTask(task)
+}
+
+// DeleteCalDAVObject удаляет задачу, представленную ресурсом CalDAV с именем name.
+// Если version не равна нулю, то удаление выполняется только при совпадении версии задачи.
+func (s *TaskService) DeleteCalDAVObject(name string, version int) error {
+	object, err := s.GetCalDAVObject(name)
+	if err != nil {
+		return err
+	}
+
+	return s.DeleteTask(object.Task.Id, version)
+}
+
+// renderCalDAVObject возвращает задачу ресурса CalDAV в формате iCalendar.
+func renderCalDAVObject(object entities.CalDAVObject, stamp string) (string, error) {
+	todo := taskToComponent(object.Task, true, stamp)
+	todo.Get("UID").Value = object.UID
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", icalProdId)
+	calendar.Components = append(calendar.Components, todo)
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, calendar); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// todoCompleted проверяет, что компонент VTODO отмечен как выполненный.
+func todoCompleted(todo *ical.Component) bool {
+	if status := todo.Get("STATUS"); status != nil && strings.EqualFold(status.Value, "COMPLETED") {
+		return true
+	}
+
+	if percent := todo.Get("PERCENT-COMPLETE"); percent != nil && percent.Value == "100" {
+		return true
+	}
+
+	return todo.Get("COMPLETED") != nil
+}
@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"errors"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCalendar тестирует метод GetCalendar сервиса задач.
+func TestGetCalendar(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tasks := []entities.Task{
+		{Id: "1", Date: "20231225", Title: "Планерка", Repeat: "w 1"},
+		{Id: "2", Date: "20240110", Title: "Сходить в кино"},
+		{Id: "3", Date: "20240115", Title: "Оплатить коммуналку", Repeat: "d 30"},
+	}
+
+	t.Run("expand repeating tasks", func(t *testing.T) {
+		mockStore.On("GetTasksBefore", "20240201").Return(tasks, nil)
+		occurrences, err := s.GetCalendar(from, to)
+
+		expected := []entities.Occurrence{
+			{Date: "20240101", Projected: true, Task: tasks[0]},
+			{Date: "20240108", Projected: true, Task: tasks[0]},
+			{Date: "20240110", Task: tasks[1]},
+			{Date: "20240115", Projected: true, Task: tasks[0]},
+			{Date: "20240115", Task: tasks[2]},
+			{Date: "20240122", Projected: true, Task: tasks[0]},
+			{Date: "20240129", Projected: true, Task: tasks[0]},
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, expected, occurrences)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		_, err := s.GetCalendar(to, from)
+		require.ErrorIs(t, err, entities.ErrPeriodReversed)
+
+		_, err = s.GetCalendar(from, from.AddDate(2, 0, 0))
+		require.ErrorIs(t, err, entities.ErrPeriodTooLong)
+	})
+
+	t.Run("invalid repeat rules", func(t *testing.T) {
+		invalid := []entities.Task{
+			{Id: "4", Date: "20240105", Title: "Несуществующая дата", Repeat: "m 30 2"},
+			{Id: "5", Date: "20240106", Title: "Неизвестное правило", Repeat: "zzz"},
+			tasks[1],
+		}
+
+		mockStore.ExpectedCalls = nil
+		mockStore.On("GetTasksBefore", "20240201").Return(invalid, nil)
+		occurrences, err := s.GetCalendar(from, to)
+
+		require.NoError(t, err)
+		require.Equal(t, []entities.Occurrence{
+			{Date: "20240105", Task: invalid[0]},
+			{Date: "20240106", Task: invalid[1]},
+			{Date: "20240110", Task: tasks[1]},
+		}, occurrences)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		mockStore.On("GetTasksBefore", mock.Anything).Return([]entities.Task{}, errors.New("failed"))
+		occurrences, err := s.GetCalendar(from, to)
+
+		require.Error(t, err)
+		require.Empty(t, occurrences)
+	})
+}
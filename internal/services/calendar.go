@@ -0,0 +1,101 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// maxCalendarDays ограничивает длину периода календаря, чтобы частые правила
+// повторения (например, "d 1") не порождали неограниченное количество вхождений.
+const maxCalendarDays = 366
+
+// GetCalendar возвращает все вхождения задач в период [from, to].
+// Задачи с правилом повторения разворачиваются с помощью GetNextDate во все даты
+// внутри периода, при этом вхождения, не совпадающие с датой задачи в БД, помечаются как Projected.
+func (s *TaskService) GetCalendar(from, to time.Time) ([]entities.Occurrence, error) {
+	if to.Before(from) {
+		return nil, entities.ErrPeriodReversed
+	}
+
+	if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		return nil, entities.ErrPeriodTooLong
+	}
+
+	fromStr := from.Format("20060102")
+
+	tasks, err := s.store.GetTasksBefore(to.AddDate(0, 0, 1).Format("20060102"))
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []entities.Occurrence{}
+
+	for _, task := range tasks {
+		if task.Date >= fromStr {
+			occurrences = append(occurrences, entities.Occurrence{Date: task.Date, Task: task})
+		}
+
+		if task.Repeat == "" {
+			continue
+		}
+
+		// Задача с некорректным правилом повторения показывается только на своей дате,
+		// чтобы она не мешала получить календарь остальных задач.
+		projected, err := s.projectTask(task, from, to)
+		if err != nil {
+			log.Printf("failed to expand the repeat rule of task %s: %s\n", task.Id, err.Error())
+			continue
+		}
+
+		occurrences = append(occurrences, projected...)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date < occurrences[j].Date
+	})
+
+	return occurrences, nil
+}
+
+// projectTask возвращает вхождения задачи task с правилом повторения в период [from, to],
+// следующие за ее датой в БД.
+func (s *TaskService) projectTask(task entities.Task, from, to time.Time) ([]entities.Occurrence, error) {
+	var (
+		occurrences    []entities.Occurrence
+		err            error
+		fromStr, toStr = from.Format("20060102"), to.Format("20060102")
+	)
+
+	date := task.Date
+	// Для задач, дата которых раньше начала периода, сразу переходим к его началу.
+	if date < fromStr {
+		date, err = s.GetNextDate(from.AddDate(0, 0, -1), date, task.Repeat)
+		if err != nil {
+			return nil, err
+		}
+
+		if date >= fromStr && date <= toStr {
+			occurrences = append(occurrences, entities.Occurrence{Date: date, Projected: true, Task: task})
+		}
+	}
+
+	for date <= toStr {
+		dateTime, err := time.Parse("20060102", date)
+		if err != nil {
+			return nil, err
+		}
+
+		date, err = s.GetNextDate(dateTime, date, task.Repeat)
+		if err != nil {
+			return nil, err
+		}
+
+		if date >= fromStr && date <= toStr {
+			occurrences = append(occurrences, entities.Occurrence{Date: date, Projected: true, Task: task})
+		}
+	}
+
+	return occurrences, nil
+}
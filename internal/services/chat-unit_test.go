@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestChatNotifier тестирует отправку сообщений о событиях задач в каналы чатов.
+func TestChatNotifier(t *testing.T) {
+	type message struct {
+		Path    string
+		Text    string `json:"text"`
+		Channel string `json:"channel"`
+	}
+
+	messages := make(chan message, 8)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg message
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		msg.Path = r.URL.Path
+		messages <- msg
+	}))
+	defer server.Close()
+
+	channels, err := services.ReadChatChannels(strings.NewReader(`[
+		{"name": "all", "url": "` + server.URL + `/all", "channel": "town-square"},
+		{"name": "ops", "url": "` + server.URL + `/ops", "events": ["task.done"], "tag": "ops",
+		 "templates": {"task.done": "{{.Task.Title}} done by {{.Actor}}"}},
+		{"name": "weekly", "url": "` + server.URL + `/weekly", "events": ["task.due"], "repeat": "w", "query": "отчет"}
+	]`))
+	require.NoError(t, err)
+
+	n, err := services.GetChatNotifier(channels)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+
+	events := []entities.Event{
+		{Type: entities.EventTaskDue, Task: entities.Task{Id: "1", Date: "20990220", Title: "Недельный отчет", Repeat: "w 1,5"}},
+		{Type: entities.EventTaskDone, Task: entities.Task{Id: "2", Date: "20990220", Title: "Обновить сертификаты", Comment: "#ops #infra"}, Actor: "192.0.2.1"},
+		{Type: entities.EventTaskDone, Task: entities.Task{Id: "3", Date: "20990220", Title: "Купить молоко", Comment: "#opsec"}},
+		{Type: entities.EventTaskCreated, Task: entities.Task{Id: "4", Date: "20990220", Title: "Просмотр фильма"}},
+		{Type: entities.EventTaskDue, Task: entities.Task{Id: "5", Date: "20990220", Title: "<!channel> & <http://example.com|отчет>", Comment: "<@U123>"}},
+	}
+
+	for _, event := range events {
+		require.NoError(t, n.Notify(context.Background(), event))
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Уведомитель не остановился после отмены контекста")
+	}
+
+	close(messages)
+
+	received := make(map[string][]string)
+	for msg := range messages {
+		received[msg.Path] = append(received[msg.Path], msg.Text)
+
+		if msg.Path == "/all" {
+			require.Equal(t, "town-square", msg.Channel)
+		}
+	}
+
+	require.Equal(t, map[string][]string{
+		"/all": {
+			":alarm_clock: *Недельный отчет* is due today (2099-02-20)",
+			":white_check_mark: *Обновить сертификаты* has been completed",
+			":white_check_mark: *Купить молоко* has been completed",
+			":alarm_clock: *&lt;!channel&gt; &amp; &lt;http://example.com|отчет&gt;* is due today (2099-02-20)\n> &lt;@U123&gt;",
+		},
+		"/ops":    {"Обновить сертификаты done by 192.0.2.1"},
+		"/weekly": {":alarm_clock: *Недельный отчет* is due today (2099-02-20)"},
+	}, received)
+}
+
+// TestGetChatNotifier тестирует проверку настроек каналов чатов.
+func TestGetChatNotifier(t *testing.T) {
+	for _, channel := range []services.ChatChannel{
+		{Url: "hooks.slack.com/services/T000"},
+		{Url: "https://hooks.slack.com/services/T000", Events: []string{"task.archived"}},
+		{Url: "https://hooks.slack.com/services/T000", Templates: map[string]string{entities.EventTaskDue: "{{.Task.Title"}},
+	} {
+		_, err := services.GetChatNotifier([]services.ChatChannel{channel})
+
+		require.Error(t, err)
+	}
+
+	_, err := services.ReadChatChannels(strings.NewReader(`[{"url": "https://hooks.slack.com/services/T000", "filter": "ops"}]`))
+	require.Error(t, err)
+}
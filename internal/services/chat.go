@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"task_scheduler/internal/entities"
+	"text/template"
+	"time"
+)
+
+// chatQueueSize ограничивает количество сообщений, ожидающих отправки в чаты.
+const chatQueueSize = 256
+
+// chatTemplates задает шаблоны сообщений по умолчанию для событий задач. Название
+// и комментарий экранируются, чтобы они не превращались в упоминания и ссылки.
+var chatTemplates = map[string]string{
+	entities.EventTaskDue:     `:alarm_clock: *{{escape .Task.Title}}* is due today ({{date .Task.Date}}){{if .Task.Comment}}` + "\n" + `> {{escape .Task.Comment}}{{end}}`,
+	entities.EventTaskDone:    `:white_check_mark: *{{escape .Task.Title}}* has been completed{{if .Task.Repeat}}, next date {{date .Task.Date}}{{end}}`,
+	entities.EventReminder:    `:bell: Reminder: *{{escape .Task.Title}}* on {{date .Task.Date}}`,
+	entities.EventTaskCreated: `:memo: New task *{{escape .Task.Title}}* on {{date .Task.Date}}`,
+	entities.EventTaskUpdated: `:pencil2: Task *{{escape .Task.Title}}* has been updated`,
+	entities.EventTaskDeleted: `:wastebasket: Task *{{escape .Task.Title}}* has been deleted`,
+}
+
+// chatEscaper экранирует управляющие символы разметки Slack и Mattermost: "<!channel>"
+// в тексте задачи иначе стал бы упоминанием всего канала, а "<url|текст>" - ссылкой.
+var chatEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ChatChannel является структурой канала Slack или Mattermost, в который сообщения
+// отправляются через входящий вебхук Url. Events задает события канала (по умолчанию
+// наступление даты и выполнение задачи), а Tag, Query и Repeat - фильтры задач:
+// метку "#tag" в названии или комментарии, подстроку названия или комментария
+// и правило повторения ("d" соответствует всем правилам "d N"). Templates заменяет
+// шаблоны сообщений text/template для отдельных событий.
+type ChatChannel struct {
+	Name      string            `json:"name"`
+	Url       string            `json:"url"`
+	Channel   string            `json:"channel,omitempty"`
+	Username  string            `json:"username,omitempty"`
+	IconEmoji string            `json:"icon_emoji,omitempty"`
+	Events    []string          `json:"events,omitempty"`
+	Tag       string            `json:"tag,omitempty"`
+	Query     string            `json:"query,omitempty"`
+	Repeat    string            `json:"repeat,omitempty"`
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// chatMessage является телом запроса входящего вебхука Slack и Mattermost.
+type chatMessage struct {
+	Text      string `json:"text"`
+	Channel   string `json:"channel,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+}
+
+// chatPost является сообщением, ожидающим отправки в канал.
+type chatPost struct {
+	channel string
+	url     string
+	message chatMessage
+}
+
+// chatChannel является каналом с разобранными шаблонами сообщений.
+type chatChannel struct {
+	ChatChannel
+	templates map[string]*template.Template
+}
+
+// ChatNotifier отправляет сообщения о событиях задач в каналы Slack и Mattermost.
+// Notify только ставит сообщения в очередь, а отправляет их Run.
+type ChatNotifier struct {
+	channels []chatChannel
+	client   *http.Client
+	queue    chan chatPost
+}
+
+// ReadChatChannels читает список каналов в формате JSON.
+func ReadChatChannels(r io.Reader) ([]ChatChannel, error) {
+	var channels []ChatChannel
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&channels); err != nil {
+		return nil, fmt.Errorf("invalid chat channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+func GetChatNotifier(channels []ChatChannel) (*ChatNotifier, error) {
+	funcs := template.FuncMap{
+		"escape": chatEscaper.Replace,
+		"date": func(date string) string {
+			day, err := time.Parse("20060102", date)
+			if err != nil {
+				return date
+			}
+
+			return day.Format(time.DateOnly)
+		},
+	}
+
+	n := &ChatNotifier{
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan chatPost, chatQueueSize),
+	}
+
+	for i, channel := range channels {
+		if channel.Name == "" {
+			channel.Name = fmt.Sprintf("#%d", i+1)
+		}
+
+		target, err := url.Parse(channel.Url)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, fmt.Errorf("chat channel %s: the url must be an absolute http or https url", channel.Name)
+		}
+
+		if len(channel.Events) == 0 {
+			channel.Events = []string{entities.EventTaskDue, entities.EventTaskDone}
+		}
+
+		compiled := chatChannel{ChatChannel: channel, templates: make(map[string]*template.Template)}
+
+		for _, event := range channel.Events {
+			text, ok := channel.Templates[event]
+			if !ok {
+				text, ok = chatTemplates[event]
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("chat channel %s: unknown event %q", channel.Name, event)
+			}
+
+			tmpl, err := template.New(event).Funcs(funcs).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("chat channel %s: %w", channel.Name, err)
+			}
+
+			compiled.templates[event] = tmpl
+		}
+
+		n.channels = append(n.channels, compiled)
+	}
+
+	return n, nil
+}
+
+func (n *ChatNotifier) Name() string {
+	return "chat"
+}
+
+// Notify ставит в очередь сообщение о событии event для каждого канала,
+// события и фильтры которого соответствуют событию.
+func (n *ChatNotifier) Notify(_ context.Context, event entities.Event) error {
+	var errs []error
+
+	for _, channel := range n.channels {
+		tmpl, ok := channel.templates[event.Type]
+		if !ok || !channel.matches(event.Task) {
+			continue
+		}
+
+		var text strings.Builder
+		if err := tmpl.Execute(&text, event); err != nil {
+			errs = append(errs, fmt.Errorf("chat channel %s: %w", channel.Name, err))
+			continue
+		}
+
+		post := chatPost{
+			channel: channel.Name,
+			url:     channel.Url,
+			message: chatMessage{Text: text.String(), Channel: channel.Channel, Username: channel.Username, IconEmoji: channel.IconEmoji},
+		}
+
+		select {
+		case n.queue <- post:
+		default:
+			errs = append(errs, fmt.Errorf("chat channel %s: the queue is full", channel.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// matches проверяет, соответствует ли задача task фильтрам канала.
+func (c *chatChannel) matches(task entities.Task) bool {
+	text := strings.ToLower(task.Title + "\n" + task.Comment)
+
+	if c.Tag != "" {
+		tag := "#" + strings.ToLower(strings.TrimPrefix(c.Tag, "#"))
+		if !slices.Contains(strings.Fields(text), tag) {
+			return false
+		}
+	}
+
+	if c.Query != "" && !strings.Contains(text, strings.ToLower(c.Query)) {
+		return false
+	}
+
+	if c.Repeat != "" && task.Repeat != c.Repeat && !strings.HasPrefix(task.Repeat, c.Repeat+" ") {
+		return false
+	}
+
+	return true
+}
+
+// Run отправляет сообщения из очереди до отмены контекста ctx, после чего
+// отправляет сообщения, оставшиеся в очереди.
+func (n *ChatNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case post := <-n.queue:
+			n.send(context.WithoutCancel(ctx), post)
+		case <-ctx.Done():
+			for {
+				select {
+				case post := <-n.queue:
+					n.send(context.WithoutCancel(ctx), post)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send отправляет сообщение post и записывает ошибку отправки в журнал.
+func (n *ChatNotifier) send(ctx context.Context, post chatPost) {
+	if err := n.post(ctx, post); err != nil {
+		log.Printf("failed to post a message to chat channel %s: %s\n", post.channel, err.Error())
+	}
+}
+
+// post отправляет сообщение post во входящий вебхук канала.
+func (n *ChatNotifier) post(ctx context.Context, post chatPost) error {
+	body, err := json.Marshal(post.message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, post.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package services_test
+
+import (
+	"errors"
+	"math"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCompletions тестирует метод GetCompletions сервиса задач.
+func TestGetCompletions(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	completions := []entities.Completion{
+		{Id: "1", TaskId: "1", Title: "Просмотр фильма", Date: "20240220", CompletedAt: 1708400000},
+	}
+
+	t.Run("get all completions", func(t *testing.T) {
+		mockStore.On("GetCompletions", "", int64(0), int64(math.MaxInt64)).Return(completions, nil)
+		actual, err := s.GetCompletions("", time.Time{}, time.Time{})
+
+		require.NoError(t, err)
+		require.Equal(t, completions, actual)
+	})
+
+	t.Run("get completions for period", func(t *testing.T) {
+		from := time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)
+
+		mockStore.On("GetCompletions", "фильм", from.Unix(), to.AddDate(0, 0, 1).Unix()).Return(completions, nil)
+		actual, err := s.GetCompletions("фильм", from, to)
+
+		require.NoError(t, err)
+		require.Equal(t, completions, actual)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		mockStore.On("GetCompletions", mock.Anything, mock.Anything, mock.Anything).Return([]entities.Completion{}, errors.New("failed"))
+		actual, err := s.GetCompletions("", time.Time{}, time.Time{})
+
+		require.Error(t, err)
+		require.Empty(t, actual)
+	})
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"math"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// GetCompletions возвращает записи о выполненных задачах, содержащих строку или подстроку
+// target в названии и выполненных в промежутке [from, to]. Нулевые границы не ограничивают промежуток.
+func (s *TaskService) GetCompletions(target string, from, to time.Time) ([]entities.Completion, error) {
+	var fromUnix, toUnix int64 = 0, math.MaxInt64
+
+	if !from.IsZero() {
+		fromUnix = from.Unix()
+	}
+
+	// Граница to включает в себя весь указанный день.
+	if !to.IsZero() {
+		toUnix = to.AddDate(0, 0, 1).Unix()
+	}
+
+	return s.store.GetCompletions(target, fromUnix, toUnix)
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportCSV тестирует метод ImportCSV сервиса задач.
+func TestImportCSV(t *testing.T) {
+	t.Run("date formats and mapping", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		data := "Задача;Срок;Повтор\n" +
+			"Просмотр фильма;20990101;\n" +
+			"Просмотр матча;02.01.2099;d 7\n" +
+			"Чтение книги;2099-01-03;\n" +
+			"Прогулка;2099-01-04T10:00:00+03:00;y\n"
+
+		mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "Просмотр фильма"}).Return("1", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990102", Title: "Просмотр матча", Repeat: "d 7"}).Return("2", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990103", Title: "Чтение книги"}).Return("3", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990104", Title: "Прогулка", Repeat: "y"}).Return("4", nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionImport
+		})).Return(nil)
+
+		mapping := map[string]string{"title": "Задача", "date": "Срок", "repeat": "Повтор"}
+		report, err := s.ImportCSV(strings.NewReader(data), mapping, false)
+
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Created)
+		require.Empty(t, report.Errors)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		data := "title,date,repeat\n" +
+			"Просмотр фильма,20990101,\n" +
+			",20990102,\n" +
+			"Просмотр матча,01/02/2099,\n" +
+			"Чтение книги,20990103,x 5\n" +
+			"Несуществующая дата,20990101,m 30 2\n"
+
+		report, err := s.ImportCSV(strings.NewReader(data), nil, true)
+
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, []entities.Task{{Date: "20990101", Title: "Просмотр фильма"}}, report.Preview)
+		require.Len(t, report.Errors, 4)
+		require.Equal(t, []int{3, 4, 5, 6}, []int{report.Errors[0].Line, report.Errors[1].Line, report.Errors[2].Line, report.Errors[3].Line})
+		mockStore.AssertNotCalled(t, "PostTask", mock.Anything)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		_, err := s.ImportCSV(strings.NewReader("name,date\nПросмотр фильма,20990101\n"), nil, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.ImportCSV(strings.NewReader("title,date\n"), map[string]string{"title": "Задача"}, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.ImportCSV(strings.NewReader("title,date\n"), map[string]string{"priority": "Важность"}, true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+	})
+}
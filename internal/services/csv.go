@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// csvFields перечисляет поля задачи, которые можно импортировать из CSV.
+var csvFields = []string{"date", "title", "comment", "repeat"}
+
+// csvDateLayouts перечисляет распознаваемые форматы дат в CSV.
+var csvDateLayouts = []string{
+	"20060102",
+	"02.01.2006",
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// ImportCSV добавляет задачи из файла CSV с заголовком в первой строке.
+// mapping сопоставляет полям задачи (date, title, comment, repeat) названия столбцов файла,
+// а для полей без сопоставления используется столбец с названием поля без учета регистра.
+// Разделитель (запятая или точка с запятой) определяется по строке заголовка.
+// Каждая строка проверяется по тем же правилам, что и в AddTask, а правило повторения
+// дополнительно проверяется с помощью GetNextDate. При dryRun задачи не сохраняются,
+// а отчет содержит задачи, которые были бы добавлены, и строки с ошибками.
+func (s *TaskService) ImportCSV(r io.Reader, mapping map[string]string, dryRun bool) (entities.ImportReport, error) {
+	report := entities.ImportReport{Errors: []entities.ImportError{}, DryRun: dryRun}
+
+	for field := range mapping {
+		if !slices.Contains(csvFields, field) {
+			return report, fmt.Errorf("%w: unknown task field %q", entities.ErrInvalidImport, field)
+		}
+	}
+
+	buffered := bufio.NewReader(r)
+
+	header, err := buffered.Peek(buffered.Size())
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return report, err
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+
+	if line, _, _ := strings.Cut(string(header), "\n"); strings.Count(line, ";") > strings.Count(line, ",") {
+		reader.Comma = ';'
+	}
+
+	columns, err := reader.Read()
+	if err != nil {
+		return report, fmt.Errorf("%w: failed to read CSV header: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	index := make(map[string]int, len(csvFields))
+	for _, field := range csvFields {
+		name, ok := mapping[field]
+		if !ok {
+			name = field
+		}
+
+		for i, column := range columns {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")), name) {
+				index[field] = i
+				break
+			}
+		}
+
+		if _, found := index[field]; !found && ok {
+			return report, fmt.Errorf("%w: there is no column %q in the CSV header", entities.ErrInvalidImport, name)
+		}
+	}
+
+	if _, ok := index["title"]; !ok {
+		return report, fmt.Errorf("%w: the CSV header has no title column", entities.ErrInvalidImport)
+	}
+
+	for i := 0; ; i++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		// Ошибка разбора CSV нарушает разбиение на записи, поэтому импорт оставшихся строк прекращается.
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Line: parseErr.Line, Error: err.Error()})
+			break
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		task, err := s.parseCSVTask(record, index)
+		if err == nil && !dryRun {
+			task.Id, err = s.addTask(task, entities.ActionImport)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Line: line, Error: err.Error()})
+			continue
+		}
+
+		if dryRun {
+			report.Preview = append(report.Preview, task)
+		}
+
+		report.Created++
+	}
+
+	return report, nil
+}
+
+// parseCSVTask возвращает задачу из строки CSV record с номерами столбцов полей index,
+// проверенную по тем же правилам, что и в AddTask.
+func (s *TaskService) parseCSVTask(record []string, index map[string]int) (entities.Task, error) {
+	value := func(field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	task := entities.Task{
+		Title:   value("title"),
+		Comment: value("comment"),
+		Repeat:  value("repeat"),
+	}
+
+	if date := value("date"); date != "" {
+		parsed, err := parseCSVDate(date)
+		if err != nil {
+			return task, err
+		}
+
+		task.Date = parsed
+	}
+
+	return s.validateImportedTask(task)
+}
+
+// validateImportedTask проверяет импортируемую задачу по тем же правилам, что и в AddTask,
+// а правило повторения дополнительно проверяет с помощью GetNextDate.
+func (s *TaskService) validateImportedTask(task entities.Task) (entities.Task, error) {
+	task, err := s.prepareTask(task)
+	if err != nil {
+		return task, err
+	}
+
+	if task.Repeat != "" {
+		if _, err := s.GetNextDate(time.Now(), task.Date, task.Repeat); err != nil {
+			return task, err
+		}
+	}
+
+	return task, nil
+}
+
+// parseCSVDate приводит дату в одном из форматов csvDateLayouts к формату 20060102.
+func parseCSVDate(value string) (string, error) {
+	for _, layout := range csvDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("20060102"), nil
+		}
+	}
+
+	return "", fmt.Errorf("unrecognized date format %q", value)
+}
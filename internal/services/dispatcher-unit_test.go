@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier запоминает полученные события.
+type recordingNotifier struct {
+	events []entities.Event
+	err    error
+}
+
+func (n *recordingNotifier) Name() string {
+	return "recording"
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event entities.Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+// TestDispatch тестирует метод Dispatch диспетчера задач.
+func TestDispatch(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+
+	tasks := []entities.Task{
+		{Id: "1", Date: "20240114", Title: "Просмотр фильма"},
+		{Id: "2", Date: "20240115", Title: "Просмотр матча"},
+	}
+
+	t.Run("events are sent once", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		notifier := &recordingNotifier{}
+		failing := &recordingNotifier{err: errors.New("connection refused")}
+		d := services.GetDispatcher(mockStore, failing, notifier)
+
+		mockStore.On("GetDueTasks", "20240115", entities.EventTaskDue).Return(tasks, nil)
+		mockStore.On("MarkFired", "1", "20240114", entities.EventTaskDue, now.Unix()).Return(true, nil)
+		mockStore.On("MarkFired", "2", "20240115", entities.EventTaskDue, now.Unix()).Return(false, nil)
+
+		count, err := d.Dispatch(context.Background(), now)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, []entities.Event{{Type: entities.EventTaskDue, Task: tasks[0], FiredAt: now.Unix()}}, notifier.events)
+		require.Len(t, failing.events, 1)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		notifier := &recordingNotifier{}
+		d := services.GetDispatcher(mockStore, notifier)
+
+		mockStore.On("GetDueTasks", "20240115", entities.EventTaskDue).Return(tasks, nil)
+		mockStore.On("MarkFired", "1", "20240114", entities.EventTaskDue, now.Unix()).Return(false, errors.New("database is locked"))
+
+		_, err := d.Dispatch(context.Background(), now)
+
+		require.Error(t, err)
+		require.Empty(t, notifier.events)
+	})
+}
+
+// TestDispatcherRun тестирует остановку диспетчера задач при отмене контекста.
+func TestDispatcherRun(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	d := services.GetDispatcher(mockStore)
+
+	mockStore.On("GetDueTasks", time.Now().Format("20060102"), entities.EventTaskDue).Return([]entities.Task{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		d.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Диспетчер не остановился после отмены контекста")
+	}
+
+	mockStore.AssertCalled(t, "GetDueTasks", time.Now().Format("20060102"), entities.EventTaskDue)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"log"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+// Notifier отправляет уведомления о событиях задач.
+type Notifier interface {
+	// Name возвращает название уведомителя для журнала.
+	Name() string
+	Notify(ctx context.Context, event entities.Event) error
+}
+
+// LogNotifier записывает события задач в журнал.
+type LogNotifier struct{}
+
+func (LogNotifier) Name() string {
+	return "log"
+}
+
+func (LogNotifier) Notify(_ context.Context, event entities.Event) error {
+	log.Printf("%s: task %s %q (%s)\n", event.Type, event.Task.Id, event.Task.Title, event.Task.Date)
+	return nil
+}
+
+// Dispatcher находит задачи, дата которых наступила, и сообщает о них уведомителям.
+type Dispatcher struct {
+	store     storage.DispatchInterface
+	notifiers []Notifier
+}
+
+func GetDispatcher(store storage.DispatchInterface, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{store: store, notifiers: notifiers}
+}
+
+// Dispatch отправляет уведомителям событие entities.EventTaskDue для каждой задачи
+// с датой не позднее now, о которой еще не сообщалось, и возвращает количество событий.
+// Событие отмечается в БД до отправки, поэтому после перезапуска или при нескольких
+// запущенных экземплярах сервиса оно не отправляется повторно. Ошибки уведомителей
+// записываются в журнал и не прерывают отправку.
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	tasks, err := d.store.GetDueTasks(now.Format("20060102"), entities.EventTaskDue)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, task := range tasks {
+		fired, err := d.store.MarkFired(task.Id, task.Date, entities.EventTaskDue, now.Unix())
+		if err != nil {
+			return count, err
+		}
+
+		if !fired {
+			continue
+		}
+
+		event := entities.Event{Type: entities.EventTaskDue, Task: task, FiredAt: now.Unix()}
+		for _, notifier := range d.notifiers {
+			if err := notifier.Notify(ctx, event); err != nil {
+				log.Printf("notifier %q failed to send %s event of task %s: %s\n", notifier.Name(), event.Type, task.Id, err.Error())
+			}
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// Run с периодичностью interval отправляет события наступивших задач до отмены
+// контекста ctx. Начатая отправка завершается до возврата из Run, чтобы отмеченные
+// события не остались неотправленными.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(context.WithoutCancel(ctx), time.Now()); err != nil {
+			log.Printf("failed to dispatch due tasks: %s\n", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
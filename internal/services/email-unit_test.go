@@ -0,0 +1,218 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP является SMTP сервером для тестов, который принимает письма
+// без шифрования и аутентификации и передает их в канал messages.
+type fakeSMTP struct {
+	listener net.Listener
+	messages chan []byte
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTP{listener: listener, messages: make(chan []byte, 4)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+		case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			f.messages <- data
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 unsupported")
+		}
+	}
+}
+
+// receive возвращает тему, текстовую и HTML версии полученного письма.
+func (f *fakeSMTP) receive(t *testing.T) (string, string, string) {
+	var data []byte
+
+	select {
+	case data = <-f.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Письмо не было получено")
+	}
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(data))))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	return subject, parts["text/plain"], parts["text/html"]
+}
+
+// TestEmailNotifier тестирует отправку писем о событиях задач.
+func TestEmailNotifier(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	cfg := services.SMTPConfig{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "scheduler@example.com",
+		To:   []string{"team@example.com"},
+		TLS:  services.SMTPNone,
+		Lang: "ru",
+	}
+
+	n, err := services.GetEmailNotifier(cfg)
+	require.NoError(t, err)
+
+	t.Run("reminder email", func(t *testing.T) {
+		event := entities.Event{
+			Type:     entities.EventReminder,
+			Task:     entities.Task{Id: "1", Date: "20990220", Title: "Просмотр <фильма>"},
+			Reminder: &entities.Reminder{Id: "1", TaskId: "1", Rule: "1 day before"},
+		}
+
+		require.NoError(t, n.Notify(context.Background(), event))
+
+		subject, text, html := server.receive(t)
+
+		require.Equal(t, "Напоминание: Просмотр <фильма>", subject)
+		require.Contains(t, text, "Напоминаем о задаче.")
+		require.Contains(t, text, "Дата: 20.02.2099")
+		require.Contains(t, html, "<strong>Просмотр &lt;фильма&gt;</strong>")
+	})
+
+	t.Run("other events are skipped", func(t *testing.T) {
+		require.NoError(t, n.Notify(context.Background(), entities.Event{Type: entities.EventTaskCreated}))
+		require.Empty(t, server.messages)
+	})
+
+	t.Run("digest email", func(t *testing.T) {
+		cfg := cfg
+		cfg.Lang = "en"
+
+		n, err := services.GetEmailNotifier(cfg)
+		require.NoError(t, err)
+
+		day := time.Date(2099, 2, 20, 8, 0, 0, 0, time.Local)
+		today := []entities.Task{{Id: "1", Date: "20990220", Title: "Watch a movie", Repeat: "d 7", Comment: "with popcorn"}}
+		overdue := []entities.Day{{Date: "20990218", Tasks: []entities.Task{{Id: "2", Date: "20990218", Title: "Pay the bills"}}}}
+
+		require.NoError(t, n.SendDigest(context.Background(), day, today, overdue))
+
+		subject, text, html := server.receive(t)
+
+		require.Equal(t, "Tasks for Feb 20, 2099", subject)
+		require.Contains(t, text, "Today, Feb 20, 2099")
+		require.Contains(t, text, "Repeat: d 7")
+		require.Contains(t, text, "Comment: with popcorn")
+		require.Contains(t, text, "Overdue")
+		require.Contains(t, text, "Date: Feb 18, 2099")
+		require.Contains(t, html, "<h2>Overdue</h2>")
+		require.Contains(t, html, "<strong>Pay the bills</strong>")
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		cfg := cfg
+		cfg.Port = 1
+
+		n, err := services.GetEmailNotifier(cfg)
+		require.NoError(t, err)
+
+		require.Error(t, n.Notify(context.Background(), entities.Event{Type: entities.EventTaskDue, Task: entities.Task{Id: "1"}}))
+	})
+}
+
+// TestGetEmailNotifier тестирует проверку параметров отправки писем.
+func TestGetEmailNotifier(t *testing.T) {
+	valid := services.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "scheduler@example.com", To: []string{"team@example.com"}}
+
+	_, err := services.GetEmailNotifier(valid)
+	require.NoError(t, err)
+
+	for i, cfg := range []services.SMTPConfig{
+		{Host: "smtp.example.com", From: "scheduler@example.com"},
+		{Host: "smtp.example.com", To: []string{"team@example.com"}},
+		{Host: "smtp.example.com", From: "scheduler@example.com", To: []string{"team@example.com"}, TLS: "ssl"},
+		{Host: "smtp.example.com", From: "scheduler@example.com", To: []string{"team@example.com"}, Lang: "de"},
+	} {
+		_, err := services.GetEmailNotifier(cfg)
+		require.Error(t, err, strconv.Itoa(i))
+	}
+}
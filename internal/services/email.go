@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	htemplate "html/template"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	ttemplate "text/template"
+	"time"
+)
+
+// Режимы шифрования соединения с SMTP сервером.
+const (
+	// SMTPStartTLS переключает соединение на TLS командой STARTTLS.
+	SMTPStartTLS = "starttls"
+	// SMTPTLS устанавливает TLS соединение сразу (обычно порт 465).
+	SMTPTLS = "tls"
+	// SMTPNone отправляет письма без шифрования.
+	SMTPNone = "none"
+)
+
+// smtpTimeout ограничивает время отправки одного письма.
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig является структурой параметров отправки писем. Если Username не указан,
+// то письма отправляются без аутентификации. Lang задает язык писем ("en" или "ru").
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      string
+	Lang     string
+}
+
+// EmailNotifier отправляет письма о наступлении дат задач и напоминаниях,
+// а также утреннюю сводку задач.
+type EmailNotifier struct {
+	cfg      SMTPConfig
+	messages map[string]string
+	text     *ttemplate.Template
+	html     *htemplate.Template
+}
+
+// emailData является структурой данных шаблонов писем.
+type emailData struct {
+	Intro   string
+	Event   entities.Event
+	Date    string
+	Today   []entities.Task
+	Overdue []entities.Day
+}
+
+func GetEmailNotifier(cfg SMTPConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("the SMTP host, sender and recipients must be specified")
+	}
+
+	if cfg.TLS == "" {
+		cfg.TLS = SMTPStartTLS
+	}
+
+	if cfg.TLS != SMTPStartTLS && cfg.TLS != SMTPTLS && cfg.TLS != SMTPNone {
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", cfg.TLS)
+	}
+
+	if cfg.Lang == "" {
+		cfg.Lang = "en"
+	}
+
+	messages, ok := emailMessages[cfg.Lang]
+	if !ok {
+		return nil, fmt.Errorf("unsupported email language %q", cfg.Lang)
+	}
+
+	funcs := map[string]any{
+		"t": func(key string) string {
+			return messages[key]
+		},
+		"date": func(date string) string {
+			day, err := time.Parse("20060102", date)
+			if err != nil {
+				return date
+			}
+
+			return day.Format(emailDateLayouts[cfg.Lang])
+		},
+	}
+
+	n := &EmailNotifier{
+		cfg:      cfg,
+		messages: messages,
+		text:     ttemplate.New("email").Funcs(funcs),
+		html:     htemplate.New("email").Funcs(funcs),
+	}
+
+	ttemplate.Must(n.text.Parse(taskText))
+	ttemplate.Must(n.text.New("event").Parse(eventText))
+	ttemplate.Must(n.text.New("digest").Parse(digestText))
+	htemplate.Must(n.html.Parse(taskHTML))
+	htemplate.Must(n.html.New("event").Parse(eventHTML))
+	htemplate.Must(n.html.New("digest").Parse(digestHTML))
+
+	return n, nil
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify отправляет письмо о событии entities.EventTaskDue или entities.EventReminder.
+// Остальные события пропускаются.
+func (n *EmailNotifier) Notify(ctx context.Context, event entities.Event) error {
+	var subject, intro string
+
+	switch event.Type {
+	case entities.EventTaskDue:
+		subject, intro = n.messages["due_subject"], n.messages["due_intro"]
+	case entities.EventReminder:
+		subject, intro = n.messages["reminder_subject"], n.messages["reminder_intro"]
+	default:
+		return nil
+	}
+
+	return n.send(ctx, "event", fmt.Sprintf(subject, event.Task.Title), emailData{Intro: intro, Event: event})
+}
+
+// SendDigest отправляет сводку с задачами today на день day и просроченными задачами overdue.
+func (n *EmailNotifier) SendDigest(ctx context.Context, day time.Time, today []entities.Task, overdue []entities.Day) error {
+	date := day.Format("20060102")
+	subject := fmt.Sprintf(n.messages["digest_subject"], day.Format(emailDateLayouts[n.cfg.Lang]))
+
+	return n.send(ctx, "digest", subject, emailData{Date: date, Today: today, Overdue: overdue})
+}
+
+// RunDigest ежедневно в момент at от начала дня отправляет сводку задач сервиса s
+// до отмены контекста ctx. Сводка не отправляется, если на сегодня нет ни задач,
+// ни просроченных задач.
+func (n *EmailNotifier) RunDigest(ctx context.Context, s TaskServiceInterface, at time.Duration) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).Add(at)
+		if !next.After(now) {
+			next = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local).Add(at)
+		}
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := n.digest(context.WithoutCancel(ctx), s, time.Now()); err != nil {
+			log.Printf("failed to send the daily digest: %s\n", err.Error())
+		}
+	}
+}
+
+// digest отправляет сводку задач сервиса s на день now.
+func (n *EmailNotifier) digest(ctx context.Context, s TaskServiceInterface, now time.Time) error {
+	days, err := s.GetAgenda(now, now)
+	if err != nil {
+		return err
+	}
+
+	overdue, err := s.GetOverdueTasks()
+	if err != nil {
+		return err
+	}
+
+	var today []entities.Task
+	if len(days) > 0 {
+		today = days[0].Tasks
+	}
+
+	if len(today) == 0 && len(overdue) == 0 {
+		return nil
+	}
+
+	return n.SendDigest(ctx, now, today, overdue)
+}
+
+// send формирует письмо по шаблонам name с данными data и отправляет его получателям.
+func (n *EmailNotifier) send(ctx context.Context, name, subject string, data emailData) error {
+	var text, html bytes.Buffer
+
+	if err := n.text.ExecuteTemplate(&text, name, data); err != nil {
+		return err
+	}
+
+	if err := n.html.ExecuteTemplate(&html, name, data); err != nil {
+		return err
+	}
+
+	msg, err := buildMessage(n.cfg.From, n.cfg.To, subject, text.Bytes(), html.Bytes(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	return n.deliver(ctx, msg)
+}
+
+// deliver передает письмо msg SMTP серверу.
+func (n *EmailNotifier) deliver(ctx context.Context, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if n.cfg.TLS == SMTPTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if n.cfg.TLS == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("the SMTP server does not support STARTTLS")
+		}
+
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return err
+	}
+
+	for _, to := range n.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage возвращает письмо с текстовой и HTML версиями text и html.
+func buildMessage(from string, to []string, subject string, text, html []byte, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write(part.body); err != nil {
+			return nil, err
+		}
+
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package services
+
+// emailMessages содержит тексты писем на поддерживаемых языках.
+var emailMessages = map[string]map[string]string{
+	"en": {
+		"due_subject":      "Task due: %s",
+		"reminder_subject": "Reminder: %s",
+		"digest_subject":   "Tasks for %s",
+		"due_intro":        "The date of this task has come.",
+		"reminder_intro":   "This is a reminder about the task.",
+		"date":             "Date",
+		"repeat":           "Repeat",
+		"comment":          "Comment",
+		"today":            "Today",
+		"overdue":          "Overdue",
+		"no_tasks":         "There are no tasks for today.",
+		"footer":           "Sent by task_scheduler",
+	},
+	"ru": {
+		"due_subject":      "Наступила дата задачи: %s",
+		"reminder_subject": "Напоминание: %s",
+		"digest_subject":   "Задачи на %s",
+		"due_intro":        "Наступила дата задачи.",
+		"reminder_intro":   "Напоминаем о задаче.",
+		"date":             "Дата",
+		"repeat":           "Повторение",
+		"comment":          "Комментарий",
+		"today":            "Сегодня",
+		"overdue":          "Просрочено",
+		"no_tasks":         "На сегодня задач нет.",
+		"footer":           "Отправлено task_scheduler",
+	},
+}
+
+// emailDateLayouts задает формат дат в письмах для каждого языка.
+var emailDateLayouts = map[string]string{
+	"en": "Jan 2, 2006",
+	"ru": "02.01.2006",
+}
+
+// taskText и taskHTML являются шаблонами описания задачи, общими для всех писем.
+const taskText = `{{define "task"}}{{.Title}}
+  {{t "date"}}: {{date .Date}}{{if .Repeat}}
+  {{t "repeat"}}: {{.Repeat}}{{end}}{{if .Comment}}
+  {{t "comment"}}: {{.Comment}}{{end}}
+{{end}}`
+
+const taskHTML = `{{define "task"}}<li><strong>{{.Title}}</strong><br>
+{{t "date"}}: {{date .Date}}{{if .Repeat}}<br>
+{{t "repeat"}}: {{.Repeat}}{{end}}{{if .Comment}}<br>
+{{t "comment"}}: {{.Comment}}{{end}}</li>
+{{end}}`
+
+// eventText и eventHTML являются шаблонами письма о событии задачи.
+const (
+	eventText = `{{.Intro}}
+
+{{template "task" .Event.Task}}
+--
+{{t "footer"}}
+`
+
+	eventHTML = `<!DOCTYPE html>
+<html>
+<body>
+<p>{{.Intro}}</p>
+<ul>
+{{template "task" .Event.Task}}</ul>
+<p style="color:#888">{{t "footer"}}</p>
+</body>
+</html>
+`
+)
+
+// digestText и digestHTML являются шаблонами утренней сводки задач.
+const (
+	digestText = `{{t "today"}}, {{date .Date}}
+{{range .Today}}
+{{template "task" .}}{{else}}
+{{t "no_tasks"}}
+{{end}}{{if .Overdue}}
+{{t "overdue"}}
+{{range .Overdue}}{{range .Tasks}}
+{{template "task" .}}{{end}}{{end}}{{end}}
+--
+{{t "footer"}}
+`
+
+	digestHTML = `<!DOCTYPE html>
+<html>
+<body>
+<h2>{{t "today"}}, {{date .Date}}</h2>
+{{if .Today}}<ul>
+{{range .Today}}{{template "task" .}}{{end}}</ul>
+{{else}}<p>{{t "no_tasks"}}</p>
+{{end}}{{if .Overdue}}<h2>{{t "overdue"}}</h2>
+<ul>
+{{range .Overdue}}{{range .Tasks}}{{template "task" .}}{{end}}{{end}}</ul>
+{{end}}<p style="color:#888">{{t "footer"}}</p>
+</body>
+</html>
+`
+)
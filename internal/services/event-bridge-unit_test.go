@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventBridgeNotify тестирует публикацию событий для других экземпляров.
+func TestEventBridgeNotify(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	bridge := services.GetEventBridge(mockStore, services.GetEventBroker(10))
+
+	var published []entities.Event
+
+	mockStore.On("PublishEvent", "task_events", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		payload := args.Get(1).([]byte)
+		require.LessOrEqual(t, len(payload), 7900)
+
+		var event entities.Event
+		require.NoError(t, json.Unmarshal(payload, &event))
+
+		published = append(published, event)
+	})
+
+	task := entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Comment: "с попкорном", Version: 2}
+
+	require.NoError(t, bridge.Notify(context.Background(), entities.Event{Type: entities.EventTaskUpdated, Task: task, Actor: "192.0.2.1"}))
+
+	long := task
+	long.Comment = strings.Repeat("комментарий ", 1000)
+	require.NoError(t, bridge.Notify(context.Background(), entities.Event{Type: entities.EventTaskUpdated, Task: long}))
+
+	huge := long
+	huge.Title = strings.Repeat("название ", 1000)
+	require.NoError(t, bridge.Notify(context.Background(), entities.Event{Type: entities.EventTaskUpdated, Task: huge}))
+
+	require.Len(t, published, 3)
+	require.Equal(t, entities.Event{Type: entities.EventTaskUpdated, Task: task, Actor: "192.0.2.1"}, published[0])
+	require.Empty(t, published[1].Task.Comment)
+	require.Equal(t, task.Title, published[1].Task.Title)
+	require.Equal(t, entities.Task{Id: "1", Date: "20990220", Version: 2}, published[2].Task)
+}
+
+// TestEventBridgeRun тестирует пересылку событий других экземпляров в брокер.
+func TestEventBridgeRun(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	broker := services.GetEventBroker(10)
+	bridge := services.GetEventBridge(mockStore, broker)
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payload := func(id string) []byte {
+		data, err := json.Marshal(entities.Event{Type: entities.EventTaskDone, Task: entities.Task{Id: id}})
+		require.NoError(t, err)
+
+		return data
+	}
+
+	listen := func(args mock.Arguments) (func(), func([]byte)) {
+		require.Equal(t, "task_events", args.String(1))
+		return args.Get(2).(func()), args.Get(3).(func([]byte))
+	}
+
+	// Первое соединение разрывается после получения события и некорректного сообщения.
+	mockStore.On("ListenEvents", mock.Anything, "task_events", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Run(func(args mock.Arguments) {
+		onListen, handle := listen(args)
+
+		onListen()
+		handle(payload("1"))
+		handle([]byte("{"))
+	}).Once()
+
+	// Второе соединение получает событие и закрывается при остановке.
+	mockStore.On("ListenEvents", mock.Anything, "task_events", mock.Anything, mock.Anything).Return(context.Canceled).Run(func(args mock.Arguments) {
+		onListen, handle := listen(args)
+
+		onListen()
+		handle(payload("2"))
+		cancel()
+	}).Once()
+
+	done := make(chan struct{})
+
+	go func() {
+		bridge.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Пересылка событий не завершилась после отмены контекста")
+	}
+
+	first, ok := <-sub.Events
+	require.True(t, ok)
+	require.Equal(t, "1", first.Event.Task.Id)
+
+	// После переподключения брокер начинает новую эпоху и отключает подписчиков.
+	_, ok = <-sub.Events
+	require.False(t, ok)
+
+	resumed := broker.Subscribe(first.Id)
+	defer resumed.Close()
+
+	require.True(t, resumed.Reset)
+	require.True(t, strings.HasSuffix(resumed.LastId, "-1"))
+
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+const (
+	// eventsChannel является каналом NOTIFY, через который экземпляры обмениваются событиями.
+	eventsChannel = "task_events"
+	// notifyPayloadLimit ограничивает размер сообщения NOTIFY (PostgreSQL допускает менее 8000 байт).
+	notifyPayloadLimit = 7900
+)
+
+// EventBridge пересылает события изменения задач всем экземплярам сервиса, работающим
+// с одной БД PostgreSQL. Как уведомитель он публикует события командой NOTIFY, а Run
+// получает командой LISTEN события всех экземпляров, включая собственные, и публикует
+// их в брокере экземпляра. Поэтому все экземпляры получают события в одном порядке.
+type EventBridge struct {
+	store  storage.EventBusInterface
+	broker *EventBroker
+}
+
+func GetEventBridge(store storage.EventBusInterface, broker *EventBroker) *EventBridge {
+	return &EventBridge{store: store, broker: broker}
+}
+
+func (eb *EventBridge) Name() string {
+	return "events-bridge"
+}
+
+// Notify публикует событие event для всех экземпляров. Если событие не помещается
+// в сообщение NOTIFY, то из задачи удаляется комментарий, а затем и остальные поля,
+// кроме id, даты и версии.
+func (eb *EventBridge) Notify(_ context.Context, event entities.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > notifyPayloadLimit {
+		event.Task.Comment = ""
+
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	if len(payload) > notifyPayloadLimit {
+		event.Task = entities.Task{Id: event.Task.Id, Date: event.Task.Date, Version: event.Task.Version}
+
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	return eb.store.PublishEvent(eventsChannel, payload)
+}
+
+// Run получает события всех экземпляров и публикует их в брокере до отмены контекста ctx.
+// При разрыве соединения подписка возобновляется через retry, а брокер начинает
+// новую эпоху, так как события за время переподключения могли быть пропущены.
+func (eb *EventBridge) Run(ctx context.Context, retry time.Duration) {
+	listened := false
+
+	onListen := func() {
+		if listened {
+			eb.broker.Reset()
+		}
+
+		listened = true
+	}
+
+	handle := func(payload []byte) {
+		var event entities.Event
+
+		if err := json.Unmarshal(payload, &event); err != nil {
+			log.Printf("failed to decode an event from another instance: %s\n", err.Error())
+			return
+		}
+
+		eb.broker.Publish(event)
+	}
+
+	for {
+		err := eb.store.ListenEvents(ctx, eventsChannel, onListen, handle)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, storage.ErrListenUnsupported) {
+			log.Println(err.Error())
+			return
+		}
+
+		log.Printf("failed to listen for events of other instances: %v\n", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventBroker тестирует рассылку событий подписчикам и возобновление потока событий.
+func TestEventBroker(t *testing.T) {
+	broker := services.GetEventBroker(3)
+
+	live := broker.Subscribe("")
+	defer live.Close()
+
+	require.False(t, live.Reset)
+	require.Empty(t, live.Replay)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, broker.Notify(context.Background(), entities.Event{Type: entities.EventTaskCreated, Task: entities.Task{Id: id}}))
+	}
+
+	var received []services.StreamEvent
+	for range 4 {
+		received = append(received, <-live.Events)
+	}
+
+	require.Equal(t, "4", received[3].Event.Task.Id)
+	require.NotEqual(t, received[2].Id, received[3].Id)
+
+	t.Run("resume", func(t *testing.T) {
+		sub := broker.Subscribe(received[1].Id)
+		defer sub.Close()
+
+		require.False(t, sub.Reset)
+		require.Equal(t, received[2:], sub.Replay)
+		require.Equal(t, received[3].Id, sub.LastId)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		sub := broker.Subscribe(received[3].Id)
+		defer sub.Close()
+
+		require.False(t, sub.Reset)
+		require.Empty(t, sub.Replay)
+	})
+
+	t.Run("resume after evicted event", func(t *testing.T) {
+		sub := broker.Subscribe(received[0].Id)
+		defer sub.Close()
+
+		require.False(t, sub.Reset)
+		require.Equal(t, received[1:], sub.Replay)
+	})
+
+	t.Run("missed evicted event", func(t *testing.T) {
+		sub := broker.Subscribe(strings.TrimSuffix(received[0].Id, "1") + "0")
+		defer sub.Close()
+
+		require.True(t, sub.Reset)
+		require.Empty(t, sub.Replay)
+	})
+
+	t.Run("another broker", func(t *testing.T) {
+		sub := services.GetEventBroker(3).Subscribe(received[3].Id)
+		defer sub.Close()
+
+		require.True(t, sub.Reset)
+	})
+
+	t.Run("slow subscriber", func(t *testing.T) {
+		slow := broker.Subscribe("")
+		defer slow.Close()
+
+		for range 100 {
+			broker.Publish(entities.Event{Type: entities.EventTaskUpdated})
+		}
+
+		count := 0
+		for range slow.Events {
+			count++
+		}
+
+		require.Less(t, count, 100)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		sub := broker.Subscribe("")
+		broker.Close()
+
+		_, ok := <-sub.Events
+		require.False(t, ok)
+		sub.Close()
+	})
+}
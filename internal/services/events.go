@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// subscriberBuffer ограничивает количество событий, ожидающих отправки одному подписчику.
+// Подписчик, не успевающий получать события, отключается и может возобновить поток
+// с последнего полученного события.
+const subscriberBuffer = 64
+
+// StreamEvent является событием задачи в потоке событий. Id состоит из эпохи брокера
+// и порядкового номера события, например "lq3x9a-42".
+type StreamEvent struct {
+	Id    string
+	Event entities.Event
+}
+
+// Subscription является подпиской на поток событий. Replay содержит события из буфера,
+// опубликованные после события, с которого возобновлен поток, а Reset сообщает, что
+// поток возобновить нельзя и клиенту следует заново загрузить задачи. LastId содержит
+// id последнего опубликованного к моменту подписки события. Канал Events закрывается
+// при отключении подписчика брокером.
+type Subscription struct {
+	Replay []StreamEvent
+	Reset  bool
+	LastId string
+	Events <-chan StreamEvent
+
+	events chan StreamEvent
+	broker *EventBroker
+}
+
+// Close отменяет подписку.
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s.events)
+}
+
+// EventBroker рассылает события изменения задач подписчикам потока событий
+// и хранит последние события для возобновления потока.
+type EventBroker struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	buffer      []StreamEvent
+	size        int
+	subscribers map[chan StreamEvent]struct{}
+	closed      bool
+}
+
+// GetEventBroker возвращает брокер, хранящий не более size последних событий.
+func GetEventBroker(size int) *EventBroker {
+	return &EventBroker{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:        max(size, 1),
+		subscribers: make(map[chan StreamEvent]struct{}),
+	}
+}
+
+func (b *EventBroker) Name() string {
+	return "events"
+}
+
+// Notify публикует событие event.
+func (b *EventBroker) Notify(_ context.Context, event entities.Event) error {
+	b.Publish(event)
+	return nil
+}
+
+// Publish присваивает событию event очередной id, сохраняет его в буфер
+// и отправляет подписчикам.
+func (b *EventBroker) Publish(event entities.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	published := StreamEvent{Id: b.id(b.seq), Event: event}
+
+	if len(b.buffer) == b.size {
+		b.buffer = append(b.buffer[:0], b.buffer[1:]...)
+	}
+	b.buffer = append(b.buffer, published)
+
+	for events := range b.subscribers {
+		select {
+		case events <- published:
+		default:
+			delete(b.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Subscribe подписывает на события, опубликованные после события с id lastEventId.
+// Если lastEventId пуст, то подписка получает только новые события.
+func (b *EventBroker) Subscribe(lastEventId string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan StreamEvent, subscriberBuffer)
+	sub := &Subscription{LastId: b.id(b.seq), Events: events, events: events, broker: b}
+
+	if lastEventId != "" {
+		sub.Replay, sub.Reset = b.replay(lastEventId)
+	}
+
+	if b.closed {
+		close(events)
+		return sub
+	}
+
+	b.subscribers[events] = struct{}{}
+
+	return sub
+}
+
+// id возвращает id события с порядковым номером seq.
+func (b *EventBroker) id(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// replay возвращает события из буфера после события с id lastEventId. Если событие
+// опубликовано другим брокером или уже вытеснено из буфера, то возвращается reset.
+func (b *EventBroker) replay(lastEventId string) (events []StreamEvent, reset bool) {
+	epoch, number, ok := strings.Cut(lastEventId, "-")
+	if !ok || epoch != b.epoch {
+		return nil, true
+	}
+
+	seq, err := strconv.ParseUint(number, 10, 64)
+	if err != nil || seq > b.seq {
+		return nil, true
+	}
+
+	missed := int(b.seq - seq)
+	if missed > len(b.buffer) {
+		return nil, true
+	}
+
+	return append([]StreamEvent(nil), b.buffer[len(b.buffer)-missed:]...), false
+}
+
+// unsubscribe отключает подписчика с каналом events.
+func (b *EventBroker) unsubscribe(events chan StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[events]; ok {
+		delete(b.subscribers, events)
+		close(events)
+	}
+}
+
+// Reset начинает новую эпоху брокера: очищает буфер событий и отключает подписчиков.
+// При переподключении подписчики получат событие reset, поэтому Reset вызывается,
+// когда часть событий могла быть пропущена.
+func (b *EventBroker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.epoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+	b.seq = 0
+	b.buffer = b.buffer[:0]
+
+	for events := range b.subscribers {
+		delete(b.subscribers, events)
+		close(events)
+	}
+}
+
+// Close отключает всех подписчиков, чтобы потоки событий завершились
+// при остановке сервера.
+func (b *EventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for events := range b.subscribers {
+		delete(b.subscribers, events)
+		close(events)
+	}
+}
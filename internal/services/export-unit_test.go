@@ -0,0 +1,253 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExport тестирует метод Export сервиса задач.
+func TestExport(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	completions := []entities.Completion{{Id: "1", TaskId: "1", Title: "Просмотр фильма", Date: "20240102", CompletedAt: 1704153600}}
+	history := []entities.Revision{{Id: "1", TaskId: "1", Action: entities.ActionCreate, Actor: "system", CreatedAt: 1704067200}}
+	reminders := []entities.Reminder{{Id: "1", TaskId: "1", Rule: "1 day before", Offset: -1440, FireAt: 1703980800}}
+	jobs := []entities.Job{{Id: "1", TaskId: "1", Type: entities.JobCommand, Command: "backup.sh"}}
+
+	mockStore.On("GetTasks").Return(validTasksTableForGet, nil)
+	mockStore.On("GetCompletions", "", int64(0), int64(math.MaxInt64)).Return(completions, nil)
+	mockStore.On("GetAllRevisions").Return(history, nil)
+	mockStore.On("GetAllReminders").Return(reminders, nil)
+	mockStore.On("GetJobs").Return(jobs, nil)
+	mockStore.On("GetInstanceId").Return("local", nil)
+
+	doc, err := s.Export()
+
+	require.NoError(t, err)
+	require.Equal(t, entities.ExportFormat, doc.Format)
+	require.Equal(t, entities.ExportVersion, doc.Version)
+	require.Equal(t, "local", doc.Instance)
+	require.Equal(t, validTasksTableForGet, doc.Tasks)
+	require.Equal(t, completions, doc.Completions)
+	require.Equal(t, history, doc.History)
+	require.Equal(t, reminders, doc.Reminders)
+	require.Equal(t, jobs, doc.Jobs)
+}
+
+// TestEncodeExport тестирует запись документа полного экспорта функцией EncodeExport.
+func TestEncodeExport(t *testing.T) {
+	doc := entities.Export{
+		Format:      entities.ExportFormat,
+		Version:     entities.ExportVersion,
+		ExportedAt:  1704067200,
+		Instance:    "local",
+		Tasks:       validTasksTableForGet,
+		Completions: []entities.Completion{{Id: "1", TaskId: "1", Title: "Просмотр фильма", Date: "20240102", CompletedAt: 1704153600}},
+		Jobs:        []entities.Job{{TaskId: "1", Type: entities.JobHTTP, Method: "GET", Url: "https://example.com"}},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, services.EncodeExport(&buf, doc))
+
+	var actual entities.Export
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Equal(t, doc.Tasks, actual.Tasks)
+	require.Equal(t, doc.Completions, actual.Completions)
+	require.Equal(t, doc.Jobs, actual.Jobs)
+	require.Empty(t, actual.History)
+	require.Equal(t, "local", actual.Instance)
+	require.Equal(t, int64(1704067200), actual.ExportedAt)
+}
+
+// TestImport тестирует метод Import сервиса задач в разных режимах.
+func TestImport(t *testing.T) {
+	existing := []entities.Task{
+		{Id: "1", Date: "20990101", Title: "Просмотр фильма", Version: 2},
+	}
+
+	doc := entities.Export{
+		Format:   entities.ExportFormat,
+		Version:  entities.ExportVersion,
+		Instance: "local",
+		Tasks: []entities.Task{
+			{Id: "1", Date: "20990102", Title: "Просмотр матча", Version: 7},
+			{Id: "5", Date: "20990101", Title: "Просмотр фильма"},
+			{Id: "6", Date: "20990103", Title: ""},
+			{Id: "7", Date: "20990104", Title: "Чтение книги"},
+		},
+	}
+
+	t.Run("invalid document", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		_, err := s.Import(entities.Export{Format: "other", Version: 1}, "")
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.Import(entities.Export{Format: entities.ExportFormat, Version: entities.ExportVersion + 1}, "")
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+
+		_, err = s.Import(doc, "overwrite")
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("GetInstanceId").Return("local", nil)
+		mockStore.On("GetTasks").Return(existing, nil)
+		mockStore.On("SearchTask", "1").Return(existing[0], nil)
+		mockStore.On("UpdateTask", entities.Task{Id: "1", Date: "20990102", Title: "Просмотр матча"}).Return(nil)
+		mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "Просмотр фильма"}).Return("2", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990104", Title: "Чтение книги"}).Return("3", nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionImport
+		})).Return(nil)
+
+		report, err := s.Import(doc, entities.ImportMerge)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, 1, report.Updated)
+		require.Equal(t, 0, report.Skipped)
+		require.Equal(t, []entities.ImportError{{Index: 2, Id: "6", Error: "the task title is empty"}}, report.Errors)
+	})
+
+	t.Run("merge from another instance", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("GetInstanceId").Return("other", nil)
+		mockStore.On("GetTasks").Return(existing, nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990102", Title: "Просмотр матча"}).Return("2", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990104", Title: "Чтение книги"}).Return("3", nil)
+		mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+		report, err := s.Import(doc, entities.ImportMerge)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, 0, report.Updated)
+		require.Equal(t, 1, report.Skipped)
+		require.Len(t, report.Errors, 1)
+		mockStore.AssertNotCalled(t, "UpdateTask", mock.Anything)
+	})
+
+	t.Run("task data", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		firedAt := int64(1)
+		fireAt := time.Date(2099, 1, 4, 9, 0, 0, 0, time.Local).Unix()
+
+		data := entities.Export{
+			Format:  entities.ExportFormat,
+			Version: entities.ExportVersion,
+			Tasks: []entities.Task{
+				{Id: "1", Date: "20990101", Title: "Просмотр фильма"},
+				{Id: "7", Date: "20990104", Title: "Чтение книги"},
+			},
+			Completions: []entities.Completion{
+				{Id: "10", TaskId: "7", Title: "Чтение книги", Date: "20990103", CompletedAt: 100},
+				{Id: "11", TaskId: "1", Title: "Просмотр фильма", Date: "20981231", CompletedAt: 90},
+				{Id: "12", TaskId: "9", Title: "Удаленная задача", Date: "20981230", CompletedAt: 80},
+			},
+			History: []entities.Revision{
+				{Id: "20", TaskId: "7", Action: entities.ActionCreate, Actor: "alice", CreatedAt: 50,
+					After: &entities.TaskSnapshot{Id: "7", Date: "20990103", Title: "Чтение книги"}},
+			},
+			Reminders: []entities.Reminder{
+				{Id: "30", TaskId: "7", Rule: "On the day at 09:00", Offset: 540, FireAt: fireAt, FiredAt: &firedAt},
+				{Id: "31", TaskId: "7", Rule: "1 day before", Offset: -1440, FireAt: 1, FiredAt: &firedAt},
+			},
+		}
+
+		mockStore.On("GetInstanceId").Return("local", nil)
+		mockStore.On("GetTasks").Return(existing, nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990104", Title: "Чтение книги"}).Return("3", nil)
+		mockStore.On("SearchTask", "3").Return(entities.Task{Id: "3", Date: "20990104", Title: "Чтение книги"}, nil)
+		mockStore.On("AddCompletion", entities.Completion{TaskId: "3", Title: "Чтение книги", Date: "20990103", CompletedAt: 100}).Return(nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionImport
+		})).Return(nil)
+		mockStore.On("AddRevision", entities.Revision{TaskId: "3", Action: entities.ActionCreate, Actor: "alice", CreatedAt: 50,
+			After: &entities.TaskSnapshot{Id: "3", Date: "20990103", Title: "Чтение книги"}}).Return(errors.New("database is locked"))
+		mockStore.On("ReplaceReminders", "3", []entities.Reminder{
+			{TaskId: "3", Rule: "on the day at 09:00", Offset: 540, FireAt: fireAt, FiredAt: &firedAt},
+			{TaskId: "3", Rule: "1 day before", Offset: -1440, FireAt: time.Date(2099, 1, 3, 0, 0, 0, 0, time.Local).Unix()},
+		}).Return(nil)
+
+		report, err := s.Import(data, entities.ImportSkipDuplicates)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, map[string]string{"7": "3"}, report.TaskIds)
+		require.Equal(t, []entities.ImportError{{Index: 0, Id: "7", Error: "failed to import the revision: database is locked"}}, report.Warnings)
+		mockStore.AssertNumberOfCalls(t, "AddCompletion", 1)
+		mockStore.AssertCalled(t, "ReplaceReminders", "3", mock.Anything)
+	})
+
+	t.Run("skip duplicates", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("GetInstanceId").Return("local", nil)
+		mockStore.On("GetTasks").Return(existing, nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "Просмотр фильма"}).Return("2", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990104", Title: "Чтение книги"}).Return("3", nil)
+		mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+		report, err := s.Import(doc, entities.ImportSkipDuplicates)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, 2, report.Skipped)
+		require.Len(t, report.Errors, 1)
+		mockStore.AssertNotCalled(t, "PostTask", entities.Task{Date: "20990101", Title: "Просмотр фильма"})
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("GetInstanceId").Return("local", nil)
+		mockStore.On("GetTasks").Return(existing, nil)
+		mockStore.On("SearchTask", "1").Return(existing[0], nil)
+		mockStore.On("DeleteTask", "1", 0).Return(nil)
+		mockStore.On("DeleteCalDAVResource", "1").Return(nil)
+		mockStore.On("PostTask", mock.Anything).Return("2", nil)
+		mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+		report, err := s.Import(doc, entities.ImportReplace)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Created)
+		require.Equal(t, 0, report.Updated)
+		mockStore.AssertCalled(t, "DeleteTask", "1", 0)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("GetInstanceId").Return("local", nil)
+		mockStore.On("GetTasks").Return([]entities.Task{}, errors.New("database is locked"))
+
+		_, err := s.Import(doc, entities.ImportMerge)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, entities.ErrInvalidImport)
+	})
+}
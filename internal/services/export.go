@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// Export возвращает документ полного экспорта: все задачи, записи о выполнении задач,
+// историю изменений, напоминания и действия задач.
+func (s *TaskService) Export() (entities.Export, error) {
+	tasks, err := s.store.GetTasks()
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	completions, err := s.store.GetCompletions("", 0, math.MaxInt64)
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	history, err := s.store.GetAllRevisions()
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	reminders, err := s.store.GetAllReminders()
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	jobs, err := s.store.GetJobs()
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	instance, err := s.store.GetInstanceId()
+	if err != nil {
+		return entities.Export{}, err
+	}
+
+	return entities.Export{
+		Format:      entities.ExportFormat,
+		Version:     entities.ExportVersion,
+		ExportedAt:  time.Now().Unix(),
+		Instance:    instance,
+		Tasks:       tasks,
+		Completions: completions,
+		History:     history,
+		Reminders:   reminders,
+		Jobs:        jobs,
+	}, nil
+}
+
+// EncodeExport записывает документ полного экспорта doc в w в формате JSON.
+// Элементы списков документа кодируются по одному, поэтому документ
+// не собирается в памяти целиком.
+func EncodeExport(w io.Writer, doc entities.Export) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	fields := []struct {
+		name  string
+		value any
+	}{
+		{"format", doc.Format},
+		{"version", doc.Version},
+		{"exported_at", doc.ExportedAt},
+		{"instance", doc.Instance},
+	}
+
+	for i, field := range fields {
+		if i == 0 {
+			bw.WriteString("{")
+		} else {
+			bw.WriteString(",")
+		}
+
+		fmt.Fprintf(bw, "%q:", field.name)
+		if err := enc.Encode(field.value); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeList(bw, enc, "tasks", doc.Tasks, false); err != nil {
+		return err
+	}
+
+	if err := encodeList(bw, enc, "completions", doc.Completions, true); err != nil {
+		return err
+	}
+
+	if err := encodeList(bw, enc, "history", doc.History, true); err != nil {
+		return err
+	}
+
+	if err := encodeList(bw, enc, "reminders", doc.Reminders, true); err != nil {
+		return err
+	}
+
+	if err := encodeList(bw, enc, "jobs", doc.Jobs, true); err != nil {
+		return err
+	}
+
+	bw.WriteString("}\n")
+
+	return bw.Flush()
+}
+
+// encodeList записывает в w поле документа экспорта name со списком items,
+// кодируя элементы списка по одному. Если установлен omitEmpty, пустой список
+// пропускается, как поле структуры с тегом omitempty.
+func encodeList[T any](w *bufio.Writer, enc *json.Encoder, name string, items []T, omitEmpty bool) error {
+	if omitEmpty && len(items) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, ",%q:[", name)
+
+	for i, item := range items {
+		if i > 0 {
+			w.WriteString(",")
+		}
+
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	w.WriteString("]")
+
+	return nil
+}
+
+// Import добавляет задачи из документа экспорта doc в режиме mode.
+// Каждая задача проверяется по тем же правилам, что и в AddTask, а ошибки отдельных
+// задач не прерывают импорт и возвращаются в отчете. Записи о выполнении, история
+// изменений и напоминания переносятся на задачи, созданные при импорте, а id созданных
+// задач возвращаются в отчете для импорта действий. Сам импорт сохраняется в историю
+// как действие entities.ActionImport.
+func (s *TaskService) Import(doc entities.Export, mode string) (entities.ImportReport, error) {
+	report := entities.ImportReport{Errors: []entities.ImportError{}, TaskIds: map[string]string{}}
+
+	if doc.Format != entities.ExportFormat {
+		return report, fmt.Errorf("%w: the document is not a task scheduler export", entities.ErrInvalidImport)
+	}
+
+	if doc.Version < 1 || doc.Version > entities.ExportVersion {
+		return report, fmt.Errorf("%w: unsupported export version %d", entities.ErrInvalidImport, doc.Version)
+	}
+
+	if mode == "" {
+		mode = entities.ImportMerge
+	}
+
+	if mode != entities.ImportMerge && mode != entities.ImportReplace && mode != entities.ImportSkipDuplicates {
+		return report, fmt.Errorf("%w: unknown import mode %q", entities.ErrInvalidImport, mode)
+	}
+
+	instance, err := s.store.GetInstanceId()
+	if err != nil {
+		return report, err
+	}
+
+	// id задач документа совпадают с id существующих задач только для документа,
+	// экспортированного из этой же БД. Задачи из других БД сопоставляются по содержимому.
+	sameInstance := doc.Instance != "" && doc.Instance == instance
+
+	existing, err := s.store.GetTasks()
+	if err != nil {
+		return report, err
+	}
+
+	ids := make(map[string]bool, len(existing))
+	contents := make(map[entities.Task]bool, len(existing))
+
+	for _, task := range existing {
+		if mode == entities.ImportReplace {
+			if err := s.deleteTask(task.Id, 0, entities.ActionImport); err != nil {
+				return report, err
+			}
+
+			continue
+		}
+
+		if sameInstance {
+			ids[task.Id] = true
+		}
+
+		contents[taskContent(task)] = true
+	}
+
+	for i, task := range doc.Tasks {
+		task.Version = 0
+
+		switch {
+		case mode == entities.ImportSkipDuplicates && (ids[task.Id] || contents[taskContent(task)]):
+			report.Skipped++
+			continue
+		case mode == entities.ImportMerge && ids[task.Id]:
+			if err := s.editTask(task, entities.ActionImport); err != nil {
+				report.Errors = append(report.Errors, entities.ImportError{Index: i, Id: task.Id, Error: err.Error()})
+				continue
+			}
+
+			report.Updated++
+			continue
+		case mode == entities.ImportMerge && !sameInstance && contents[taskContent(task)]:
+			report.Skipped++
+			continue
+		}
+
+		sourceId := task.Id
+		task.Id = ""
+
+		id, err := s.addTask(task, entities.ActionImport)
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Id: sourceId, Error: err.Error()})
+			continue
+		}
+
+		// Идентификаторы созданных задач не добавляются в ids, так как id задач
+		// в документе относятся к БД, из которой они были экспортированы.
+		task.Id = id
+		contents[taskContent(task)] = true
+		report.TaskIds[sourceId] = id
+		report.Created++
+	}
+
+	s.importTaskData(doc, &report)
+
+	return report, nil
+}
+
+// importTaskData переносит записи о выполнении, историю изменений и напоминания задач
+// документа doc на задачи, созданные из них при импорте. Записи остальных задач уже есть
+// в БД или относятся к задачам, которых нет в документе, и пропускаются. Ошибки отдельных
+// записей добавляются в предупреждения отчета report.
+func (s *TaskService) importTaskData(doc entities.Export, report *entities.ImportReport) {
+	warn := func(index int, sourceId, record string, err error) {
+		report.Warnings = append(report.Warnings, entities.ImportError{
+			Index: index,
+			Id:    sourceId,
+			Error: fmt.Sprintf("failed to import the %s: %s", record, err.Error()),
+		})
+	}
+
+	for i, completion := range doc.Completions {
+		id, ok := report.TaskIds[completion.TaskId]
+		if !ok {
+			continue
+		}
+
+		sourceId := completion.TaskId
+		completion.Id, completion.TaskId = "", id
+
+		if err := s.store.AddCompletion(completion); err != nil {
+			warn(i, sourceId, "completion", err)
+		}
+	}
+
+	for i, revision := range doc.History {
+		id, ok := report.TaskIds[revision.TaskId]
+		if !ok {
+			continue
+		}
+
+		sourceId := revision.TaskId
+		revision.Id, revision.TaskId, revision.Changes = "", id, nil
+		revision.Before = importSnapshot(revision.Before, id)
+		revision.After = importSnapshot(revision.After, id)
+
+		if err := s.store.AddRevision(revision); err != nil {
+			warn(i, sourceId, "revision", err)
+		}
+	}
+
+	// Напоминания задачи заменяются целиком, поэтому они группируются по задачам
+	// в порядке первого напоминания каждой задачи в документе.
+	reminders := make(map[string][]entities.Reminder)
+	first := make(map[string]int)
+	order := []string{}
+
+	for i, reminder := range doc.Reminders {
+		if _, ok := report.TaskIds[reminder.TaskId]; !ok {
+			continue
+		}
+
+		if _, ok := reminders[reminder.TaskId]; !ok {
+			first[reminder.TaskId] = i
+			order = append(order, reminder.TaskId)
+		}
+
+		reminders[reminder.TaskId] = append(reminders[reminder.TaskId], reminder)
+	}
+
+	for _, sourceId := range order {
+		if err := s.importReminders(report.TaskIds[sourceId], reminders[sourceId]); err != nil {
+			warn(first[sourceId], sourceId, "reminders", err)
+		}
+	}
+}
+
+// importReminders задает задаче с id taskId напоминания reminders из документа экспорта,
+// пересчитывая время их срабатывания от даты задачи. Отправленное напоминание остается
+// отправленным, если время его срабатывания не изменилось.
+func (s *TaskService) importReminders(taskId string, reminders []entities.Reminder) error {
+	if len(reminders) > maxReminders {
+		return fmt.Errorf("%w: a task can have at most %d reminders", entities.ErrInvalidReminder, maxReminders)
+	}
+
+	task, err := s.store.SearchTask(taskId)
+	if err != nil {
+		return err
+	}
+
+	imported := make([]entities.Reminder, 0, len(reminders))
+	seen := make(map[int]bool, len(reminders))
+
+	for _, reminder := range reminders {
+		rule := strings.Join(strings.Fields(strings.ToLower(reminder.Rule)), " ")
+
+		offset, err := parseReminderRule(rule)
+		if err != nil {
+			return err
+		}
+
+		if seen[offset] {
+			continue
+		}
+		seen[offset] = true
+
+		fireAt, err := reminderTime(task.Date, offset)
+		if err != nil {
+			return err
+		}
+
+		firedAt := reminder.FiredAt
+		if fireAt.Unix() != reminder.FireAt {
+			firedAt = nil
+		}
+
+		imported = append(imported, entities.Reminder{TaskId: taskId, Rule: rule, Offset: offset, FireAt: fireAt.Unix(), FiredAt: firedAt})
+	}
+
+	return s.store.ReplaceReminders(taskId, imported)
+}
+
+// importSnapshot возвращает копию снимка задачи snapshot из документа экспорта с id taskId.
+func importSnapshot(snapshot *entities.TaskSnapshot, taskId string) *entities.TaskSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+
+	imported := *snapshot
+	imported.Id = taskId
+
+	return &imported
+}
+
+// taskContent возвращает задачу без id и версии для поиска задач с одинаковым содержимым.
+func taskContent(task entities.Task) entities.Task {
+	task.Id, task.Version = "", 0
+	return task
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+)
+
+// CreateFeedToken создает новый секретный токен календарной подписки и отзывает
+// ранее выданный. В БД хранится только хеш токена.
+func (s *TaskService) CreateFeedToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	tokenStr := hex.EncodeToString(token)
+
+	if err := s.store.ReplaceFeedToken(hashFeedToken(tokenStr), time.Now().Unix()); err != nil {
+		return "", err
+	}
+
+	return tokenStr, nil
+}
+
+// CheckFeedToken проверяет, что token является действующим токеном календарной подписки.
+func (s *TaskService) CheckFeedToken(token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	return s.store.FeedTokenExists(hashFeedToken(token))
+}
+
+// hashFeedToken возвращает хеш токена календарной подписки для хранения в БД.
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckFeedTokenMiddleware пропускает запросы с действующим токеном календарной подписки
+// в параметре token, чтобы календарные приложения могли подписаться на календарь без JWT.
+// Остальные запросы проверяются с помощью CheckJWTMiddleware.
+func CheckFeedTokenMiddleware(s TaskServiceInterface, next http.HandlerFunc) http.HandlerFunc {
+	checkJWT := CheckJWTMiddleware(next)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			checkJWT(w, r)
+			return
+		}
+
+		valid, err := s.CheckFeedToken(token)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if !valid {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
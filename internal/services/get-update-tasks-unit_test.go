@@ -6,6 +6,7 @@ import (
 	"task_scheduler/internal/entities"
 	"task_scheduler/internal/services"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -122,12 +123,14 @@ func TestAddTask(t *testing.T) {
 	t.Run("post valid task", func(t *testing.T) {
 		for i, newTask := range validTasksTableForUpdate {
 			mockPostTask := mockStore.On("PostTask", mock.Anything).Return(fmt.Sprint(i+1), nil)
+			mockAddRevision := mockStore.On("AddRevision", mock.Anything).Return(nil)
 			id, err := s.AddTask(newTask)
 
 			require.Equal(t, fmt.Sprint(i+1), id)
 			require.NoError(t, err)
 
 			mockPostTask.Unset()
+			mockAddRevision.Unset()
 			mockStore.AssertExpectations(t)
 		}
 	})
@@ -151,7 +154,10 @@ func TestEditTask(t *testing.T) {
 
 	t.Run("update valid task", func(t *testing.T) {
 		for _, updatedTask := range validTasksTableForUpdate {
+			mockStore.On("SearchTask", updatedTask.Id).Return(updatedTask, nil)
 			mockStore.On("UpdateTask", mock.Anything).Return(nil)
+			mockStore.On("GetReminders", updatedTask.Id).Return([]entities.Reminder{}, nil)
+			mockStore.On("AddRevision", mock.Anything).Return(nil)
 			err := s.EditTask(updatedTask)
 
 			require.NoError(t, err)
@@ -177,25 +183,123 @@ func TestDeleteTask(t *testing.T) {
 	mockStore := new(services.MockStorage)
 	s := services.GetTaskService(mockStore)
 
-	mockStore.On("DeleteTask", mock.Anything).Return(nil)
+	mockStore.On("SearchTask", mock.Anything).Return(validTasksTableForGet[0], nil)
+	mockStore.On("DeleteTask", mock.Anything, mock.Anything).Return(nil)
+	mockStore.On("DeleteCalDAVResource", mock.Anything).Return(nil)
+	mockStore.On("AddRevision", mock.Anything).Return(nil)
 	t.Run("delete valid task", func(t *testing.T) {
 		testId := "1"
 
-		err := s.DeleteTask(testId)
+		err := s.DeleteTask(testId, 1)
 
 		require.NoError(t, err)
+		mockStore.AssertCalled(t, "DeleteCalDAVResource", testId)
 	})
 
 	t.Run("delete valid task", func(t *testing.T) {
 		testId := "isnotnum"
 
-		err := s.DeleteTask(testId)
+		err := s.DeleteTask(testId, 1)
 
 		require.Error(t, err)
 		mockStore.AssertNotCalled(t, "DeleteTask")
 	})
 }
 
+// TestDoneTask тестирует метод DoneTask сервиса задач.
+func TestDoneTask(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	t.Run("done task without repeat", func(t *testing.T) {
+		task := entities.Task{Id: "1", Date: "20240220", Title: "Просмотр фильма", Version: 2}
+
+		mockStore.On("SearchTask", "1").Return(task, nil)
+		mockStore.On("DeleteTask", "1", 2).Return(nil)
+		mockStore.On("DeleteCalDAVResource", "1").Return(nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionComplete && revision.After == nil
+		})).Return(nil)
+		mockStore.On("AddCompletion", mock.MatchedBy(func(c entities.Completion) bool {
+			return c.TaskId == "1" && c.Title == task.Title && c.Date == task.Date && c.CompletedAt > 0
+		})).Return(nil)
+
+		err := s.DoneTask("1")
+
+		require.NoError(t, err)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("done task with repeat", func(t *testing.T) {
+		task := entities.Task{Id: "2", Date: "20240220", Title: "Поплавать", Repeat: "d 7", Version: 1}
+
+		mockStore.ExpectedCalls = nil
+		mockStore.On("SearchTask", "2").Return(task, nil)
+		mockStore.On("UpdateTask", mock.MatchedBy(func(updated entities.Task) bool {
+			return updated.Id == "2" && updated.Date > task.Date && updated.Version == 1
+		})).Return(nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionComplete && revision.After.Version == 2
+		})).Return(nil)
+		mockStore.On("AddCompletion", mock.MatchedBy(func(c entities.Completion) bool {
+			return c.TaskId == "2" && c.Date == task.Date
+		})).Return(nil)
+
+		firedAt := time.Date(2024, 2, 19, 22, 0, 0, 0, time.Local).Unix()
+		reminder := entities.Reminder{Id: "5", TaskId: "2", Rule: "2 hours before", Offset: -120, FireAt: firedAt, FiredAt: &firedAt}
+		mockStore.On("GetReminders", "2").Return([]entities.Reminder{reminder}, nil)
+		mockStore.On("ReplaceReminders", "2", mock.MatchedBy(func(reminders []entities.Reminder) bool {
+			return len(reminders) == 1 && reminders[0].FireAt > firedAt && reminders[0].FiredAt == nil &&
+				time.Unix(reminders[0].FireAt, 0).Hour() == 22
+		})).Return(nil)
+
+		err := s.DoneTask("2")
+
+		require.NoError(t, err)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("completion is not saved", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("SearchTask", "4").Return(entities.Task{Id: "4", Date: "20240220", Title: "Просмотр фильма", Version: 1}, nil)
+		mockStore.On("AddCompletion", mock.Anything).Return(errors.New("failed"))
+
+		err := s.DoneTask("4")
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "DeleteTask", mock.Anything, mock.Anything)
+	})
+
+	t.Run("task is not completed", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("SearchTask", "4").Return(entities.Task{Id: "4", Date: "20240220", Title: "Просмотр фильма", Version: 1}, nil)
+		mockStore.On("AddCompletion", mock.Anything).Return(nil)
+		mockStore.On("DeleteTask", "4", 1).Return(entities.ErrVersionMismatch)
+		mockStore.On("DeleteCompletion", "4", "20240220").Return(nil)
+
+		err := s.DoneTask("4")
+
+		require.ErrorIs(t, err, entities.ErrVersionMismatch)
+		mockStore.AssertCalled(t, "DeleteCompletion", "4", "20240220")
+	})
+
+	t.Run("done invalid task", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("SearchTask", "3").Return(entities.Task{}, errors.New("failed"))
+
+		err := s.DoneTask("3")
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "AddCompletion", mock.Anything)
+	})
+}
+
 // TestGetTasks тестирует метод GetTasks сервиса задач.
 func TestGetTasks(t *testing.T) {
 	mockStore := new(services.MockStorage)
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"errors"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetHistory тестирует метод GetHistory сервиса задач.
+func TestGetHistory(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	created := entities.TaskSnapshot{Id: "1", Date: "20240220", Title: "Просмотр фильма", Version: 1}
+	updated := entities.TaskSnapshot{Id: "1", Date: "20240221", Title: "Просмотр фильма", Comment: "с попкорном", Version: 2}
+
+	t.Run("get valid history", func(t *testing.T) {
+		mockStore.On("GetRevisions", "1").Return([]entities.Revision{
+			{Id: "1", TaskId: "1", Action: entities.ActionCreate, After: &created},
+			{Id: "2", TaskId: "1", Action: entities.ActionUpdate, Before: &created, After: &updated},
+			{Id: "3", TaskId: "1", Action: entities.ActionDelete, Before: &updated},
+		}, nil)
+		revisions, err := s.GetHistory("1")
+
+		require.NoError(t, err)
+		require.Len(t, revisions, 3)
+		require.Equal(t, []entities.FieldChange{
+			{Field: "date", From: "", To: "20240220"},
+			{Field: "title", From: "", To: "Просмотр фильма"},
+		}, revisions[0].Changes)
+		require.Equal(t, []entities.FieldChange{
+			{Field: "date", From: "20240220", To: "20240221"},
+			{Field: "comment", From: "", To: "с попкорном"},
+		}, revisions[1].Changes)
+		require.Len(t, revisions[2].Changes, 3)
+	})
+
+	t.Run("get invalid history", func(t *testing.T) {
+		_, err := s.GetHistory("isnotnum")
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "GetRevisions", "isnotnum")
+	})
+}
+
+// TestRevertTask тестирует метод RevertTask сервиса задач.
+func TestRevertTask(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore).As("127.0.0.1")
+
+	created := entities.TaskSnapshot{Id: "1", Date: "20240220", Title: "Просмотр фильма", Version: 1}
+	current := entities.Task{Id: "1", Date: "20240221", Title: "Просмотр матча", Version: 4}
+
+	t.Run("revert valid task", func(t *testing.T) {
+		mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil).Once()
+		mockStore.On("GetRevision", "1").Return(entities.Revision{Id: "1", TaskId: "1", After: &created}, nil)
+		mockStore.On("SearchTask", "1").Return(current, nil)
+		mockStore.On("UpdateTask", entities.Task{Id: "1", Date: "20240220", Title: "Просмотр фильма", Version: 4}).Return(nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionRevert && revision.Actor == "127.0.0.1" && revision.Before.Title == current.Title
+		})).Return(nil)
+
+		task, err := s.RevertTask("1")
+
+		require.NoError(t, err)
+		require.Equal(t, entities.Task{Id: "1", Date: "20240220", Title: "Просмотр фильма", Version: 5}, task)
+	})
+
+	t.Run("revert date reschedules reminders", func(t *testing.T) {
+		moved := entities.TaskSnapshot{Id: "1", Date: "20240225", Title: "Просмотр матча", Version: 2}
+		fired := int64(1708376400)
+
+		mockStore.On("GetRevision", "4").Return(entities.Revision{Id: "4", TaskId: "1", After: &moved}, nil)
+		mockStore.On("UpdateTask", entities.Task{Id: "1", Date: "20240225", Title: "Просмотр матча", Version: 4}).Return(nil)
+		mockStore.On("GetReminders", "1").Return([]entities.Reminder{
+			{Id: "1", TaskId: "1", Offset: 600, FireAt: 1708452000, FiredAt: &fired},
+		}, nil).Once()
+		mockStore.On("ReplaceReminders", "1", mock.MatchedBy(func(reminders []entities.Reminder) bool {
+			fireAt := time.Date(2024, 2, 25, 10, 0, 0, 0, time.Local).Unix()
+			return len(reminders) == 1 && reminders[0].FireAt == fireAt && reminders[0].FiredAt == nil
+		})).Return(nil).Once()
+
+		task, err := s.RevertTask("4")
+
+		require.NoError(t, err)
+		require.Equal(t, "20240225", task.Date)
+		mockStore.AssertCalled(t, "ReplaceReminders", "1", mock.Anything)
+	})
+
+	t.Run("revert to deletion", func(t *testing.T) {
+		mockStore.On("GetRevision", "2").Return(entities.Revision{Id: "2", TaskId: "1", Before: &created}, nil)
+
+		_, err := s.RevertTask("2")
+
+		require.Error(t, err)
+	})
+
+	t.Run("revert missing revision", func(t *testing.T) {
+		mockStore.On("GetRevision", "3").Return(entities.Revision{}, errors.New("failed"))
+
+		_, err := s.RevertTask("3")
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// addRevision сохраняет в историю изменение задачи action с ее состояниями до и после изменения.
+func (s *TaskService) addRevision(action string, before, after *entities.Task) error {
+	revision := entities.Revision{
+		Action:    action,
+		Actor:     s.actor,
+		CreatedAt: time.Now().Unix(),
+		Before:    (*entities.TaskSnapshot)(before),
+		After:     (*entities.TaskSnapshot)(after),
+	}
+
+	if revision.Actor == "" {
+		revision.Actor = "system"
+	}
+
+	if after != nil {
+		revision.TaskId = after.Id
+	} else {
+		revision.TaskId = before.Id
+	}
+
+	if err := s.store.AddRevision(revision); err != nil {
+		return err
+	}
+
+	s.notify(revision)
+
+	return nil
+}
+
+// notify сообщает уведомителям сервиса об изменении задачи, сохраненном в записи истории revision.
+// Выполнение задачи соответствует событию entities.EventTaskDone, появление задачи (в том числе
+// восстановление из корзины) - entities.EventTaskCreated, а перемещение в корзину - entities.EventTaskDeleted.
+// Ошибки уведомителей записываются в журнал и не влияют на результат изменения.
+func (s *TaskService) notify(revision entities.Revision) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+
+	event := entities.Event{Actor: revision.Actor, FiredAt: revision.CreatedAt}
+
+	switch {
+	case revision.Action == entities.ActionComplete:
+		event.Type = entities.EventTaskDone
+	case revision.Before == nil:
+		event.Type = entities.EventTaskCreated
+	case revision.After == nil:
+		event.Type = entities.EventTaskDeleted
+	default:
+		event.Type = entities.EventTaskUpdated
+	}
+
+	if revision.After != nil {
+		event.Task = entities.Task(*revision.After)
+	} else {
+		event.Task = entities.Task(*revision.Before)
+	}
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.Notify(context.Background(), event); err != nil {
+			log.Printf("notifier %q failed to send %s event of task %s: %s\n", notifier.Name(), event.Type, event.Task.Id, err.Error())
+		}
+	}
+}
+
+// GetHistory возвращает историю изменений задачи с id, полученным из параметра запроса,
+// дополняя каждую запись списком измененных полей.
+func (s *TaskService) GetHistory(taskId string) ([]entities.Revision, error) {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return nil, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	revisions, err := s.store.GetRevisions(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		revisions[i].Changes = diffTasks(revisions[i].Before, revisions[i].After)
+	}
+
+	return revisions, nil
+}
+
+// RevertTask возвращает задачу в состояние, сохраненное в записи истории с id revisionId.
+// Задача, находящаяся в корзине, предварительно восстанавливается.
+func (s *TaskService) RevertTask(revisionId string) (entities.Task, error) {
+	if _, err := strconv.Atoi(revisionId); err != nil {
+		return entities.Task{}, errors.New("the revision id is not specified or is specified not correctly")
+	}
+
+	revision, err := s.store.GetRevision(revisionId)
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	if revision.After == nil {
+		return entities.Task{}, errors.New("the revision does not contain a task state to revert to")
+	}
+
+	current, err := s.store.SearchTask(revision.TaskId)
+	if err != nil {
+		if err := s.RestoreTask(revision.TaskId); err != nil {
+			return entities.Task{}, err
+		}
+
+		current, err = s.store.SearchTask(revision.TaskId)
+		if err != nil {
+			return entities.Task{}, err
+		}
+	}
+
+	task := entities.Task(*revision.After)
+	task.Version = current.Version
+
+	return s.replaceTask(entities.ActionRevert, current, task)
+}
+
+// diffTasks возвращает список полей задачи, отличающихся в состояниях before и after.
+func diffTasks(before, after *entities.TaskSnapshot) []entities.FieldChange {
+	var from, to entities.TaskSnapshot
+
+	if before != nil {
+		from = *before
+	}
+
+	if after != nil {
+		to = *after
+	}
+
+	fields := []entities.FieldChange{
+		{Field: "date", From: from.Date, To: to.Date},
+		{Field: "title", From: from.Title, To: to.Title},
+		{Field: "comment", From: from.Comment, To: to.Comment},
+		{Field: "repeat", From: from.Repeat, To: to.Repeat},
+	}
+
+	changes := []entities.FieldChange{}
+	for _, field := range fields {
+		if field.From != field.To {
+			changes = append(changes, field)
+		}
+	}
+
+	return changes
+}
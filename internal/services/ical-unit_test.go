@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"database/sql"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCalendarFeed тестирует метод GetCalendarFeed сервиса задач и перевод правил повторения в RRULE.
+func TestGetCalendarFeed(t *testing.T) {
+	tasks := []entities.Task{
+		{Id: "1", Date: "20240101", Title: "Без повторения", Version: 1},
+		{Id: "2", Date: "20240102", Title: "Каждые 3 дня", Repeat: "d 3", Version: 2},
+		{Id: "3", Date: "20240103", Title: "Каждый год", Repeat: "y", Version: 1},
+		{Id: "4", Date: "20240104", Title: "По будням", Repeat: "w 1,2,3,4,5", Version: 1},
+		{Id: "5", Date: "20240105", Title: "Первое и последнее число", Repeat: "m 1,-1", Version: 1},
+		{Id: "6", Date: "20240106", Title: "Летом", Repeat: "m 15 6,7,8", Version: 1},
+		{Id: "7", Date: "20240107", Title: "Неизвестное правило", Repeat: "zzz", Version: 1},
+		{Id: "8", Date: "20240108", Title: "Несуществующая дата", Repeat: "m 30 2", Version: 1},
+	}
+
+	expectedRRules := []string{
+		"",
+		"FREQ=DAILY;INTERVAL=3",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"FREQ=MONTHLY;BYMONTHDAY=1,-1",
+		"FREQ=YEARLY;BYMONTH=6,7,8;BYMONTHDAY=15",
+		"",
+		"",
+	}
+
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+	mockStore.On("GetTasks").Return(tasks, nil)
+
+	t.Run("events", func(t *testing.T) {
+		calendar, err := s.GetCalendarFeed(false)
+		require.NoError(t, err)
+		require.Equal(t, "VCALENDAR", calendar.Name)
+		require.Len(t, calendar.Components, len(tasks))
+
+		for i, component := range calendar.Components {
+			require.Equal(t, "VEVENT", component.Name)
+			require.Equal(t, "task-"+tasks[i].Id+"@task_scheduler", component.Get("UID").Value)
+			require.Equal(t, tasks[i].Date, component.Get("DTSTART").Value)
+			require.Equal(t, "DATE", component.Get("DTSTART").Params["VALUE"])
+
+			if expectedRRules[i] == "" {
+				require.Nil(t, component.Get("RRULE"))
+			} else {
+				require.Equal(t, expectedRRules[i], component.Get("RRULE").Value)
+			}
+		}
+
+		require.Equal(t, "1", calendar.Components[1].Get("SEQUENCE").Value)
+	})
+
+	t.Run("todos", func(t *testing.T) {
+		calendar, err := s.GetCalendarFeed(true)
+		require.NoError(t, err)
+
+		for i, component := range calendar.Components {
+			require.Equal(t, "VTODO", component.Name)
+			require.Equal(t, tasks[i].Date, component.Get("DUE").Value)
+		}
+	})
+}
+
+// TestFeedToken тестирует создание и проверку токена календарной подписки.
+func TestFeedToken(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	var storedHash string
+	mockStore.On("ReplaceFeedToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.String(0)
+	}).Return(nil)
+
+	token, err := s.CreateFeedToken()
+	require.NoError(t, err)
+	require.Len(t, token, 64)
+	require.NotEqual(t, token, storedHash)
+
+	mockStore.On("FeedTokenExists", storedHash).Return(true, nil)
+	mockStore.On("FeedTokenExists", mock.Anything).Return(false, nil)
+
+	valid, err := s.CheckFeedToken(token)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	valid, err = s.CheckFeedToken("wrong")
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	valid, err = s.CheckFeedToken("")
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+// TestImportICS тестирует метод ImportICS сервиса задач.
+func TestImportICS(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:new@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20990101\r\n" +
+		"SUMMARY:Новая задача\r\n" +
+		"DESCRIPTION:Первая строка\\nвторая\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,FR\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:known@example.com\r\n" +
+		"DUE:20990105T120000Z\r\n" +
+		"SUMMARY:Известная задача\r\n" +
+		"RRULE:FREQ=MONTHLY\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:limited@example.com\r\n" +
+		"DTSTART:20990101\r\n" +
+		"SUMMARY:Ограниченное повторение\r\n" +
+		"RRULE:FREQ=DAILY;COUNT=5\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:impossible@example.com\r\n" +
+		"DTSTART:20990101\r\n" +
+		"SUMMARY:Несуществующая дата\r\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:done@example.com\r\n" +
+		"SUMMARY:Выполненная задача\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	mockStore.On("GetTaskIdByUID", "new@example.com").Return("", sql.ErrNoRows)
+	mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "Новая задача", Comment: "Первая строка\nвторая", Repeat: "w 1,5"}).Return("7", nil)
+	mockStore.On("SetTaskUID", "new@example.com", "7").Return(nil)
+
+	mockStore.On("GetTaskIdByUID", "known@example.com").Return("3", nil)
+	mockStore.On("SearchTask", "3").Return(entities.Task{Id: "3", Date: "20990105", Title: "Старое название", Version: 2}, nil)
+	mockStore.On("UpdateTask", entities.Task{Id: "3", Date: "20990105", Title: "Известная задача", Repeat: "m 5"}).Return(nil)
+
+	mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+	report, err := s.ImportICS(strings.NewReader(data))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Errors, 2)
+	require.Equal(t, "limited@example.com", report.Errors[0].Id)
+	require.Contains(t, report.Errors[0].Error, "unsupported RRULE")
+	require.Equal(t, "impossible@example.com", report.Errors[1].Id)
+	require.Contains(t, report.Errors[1].Error, "unsupported RRULE")
+
+	_, err = s.ImportICS(strings.NewReader("BEGIN:VEVENT\r\nEND:VEVENT\r\n"))
+	require.ErrorIs(t, err, entities.ErrInvalidImport)
+}
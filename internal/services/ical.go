@@ -0,0 +1,404 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/ical"
+	"time"
+)
+
+// icalProdId идентифицирует приложение в файлах iCalendar.
+const icalProdId = "-//task_scheduler//Task Scheduler//RU"
+
+// icalWeekdays сопоставляет порядковые номера дней недели правила "w" дням недели RRULE.
+var icalWeekdays = map[string]string{
+	"1": "MO",
+	"2": "TU",
+	"3": "WE",
+	"4": "TH",
+	"5": "FR",
+	"6": "SA",
+	"7": "SU",
+}
+
+// GetCalendarFeed возвращает все задачи в виде календаря iCalendar.
+// При todo задачи представляются компонентами VTODO, в противном случае - событиями
+// VEVENT на весь день. Правила повторения задач переводятся в RRULE.
+func (s *TaskService) GetCalendarFeed(todo bool) (*ical.Component, error) {
+	tasks, err := s.store.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", icalProdId)
+	calendar.Add("CALSCALE", "GREGORIAN")
+	calendar.Add("X-WR-CALNAME", "Task Scheduler")
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	for _, task := range tasks {
+		component := taskToComponent(task, todo, stamp)
+
+		calendar.Components = append(calendar.Components, component)
+	}
+
+	return calendar, nil
+}
+
+// taskToComponent возвращает задачу в виде компонента VTODO или VEVENT. Задача
+// с правилом повторения, которое нельзя перевести в RRULE, представляется
+// без повторения, чтобы она не мешала получить остальные задачи.
+func taskToComponent(task entities.Task, todo bool, stamp string) *ical.Component {
+	component := ical.NewComponent("VEVENT")
+	if todo {
+		component.Name = "VTODO"
+	}
+
+	component.Add("UID", taskUID(task.Id))
+	component.Add("DTSTAMP", stamp)
+	component.Add("DTSTART", task.Date, "VALUE", "DATE")
+
+	if todo {
+		component.Add("DUE", task.Date, "VALUE", "DATE")
+		component.Add("STATUS", "NEEDS-ACTION")
+	}
+
+	component.AddText("SUMMARY", task.Title)
+
+	if task.Comment != "" {
+		component.AddText("DESCRIPTION", task.Comment)
+	}
+
+	if task.Version > 1 {
+		component.Add("SEQUENCE", strconv.Itoa(task.Version-1))
+	}
+
+	if task.Repeat != "" {
+		rrule, err := repeatToRRule(task.Repeat)
+		if err != nil {
+			log.Printf("failed to translate the repeat rule %q of task %s to RRULE: %s\n", task.Repeat, task.Id, err.Error())
+		} else {
+			component.Add("RRULE", rrule)
+		}
+	}
+
+	return component
+}
+
+// taskUID возвращает глобально уникальный идентификатор задачи для iCalendar.
+func taskUID(id string) string {
+	return "task-" + id + "@task_scheduler"
+}
+
+// repeatToRRule переводит правило повторения задачи в правило RRULE формата iCalendar.
+func repeatToRRule(repeat string) (string, error) {
+	elems := strings.Fields(repeat)
+	if len(elems) == 0 {
+		return "", errInvalidFormat
+	}
+
+	switch {
+	case elems[0] == "d" && len(elems) == 2:
+		if _, err := strconv.Atoi(elems[1]); err != nil {
+			return "", errInvalidFormat
+		}
+
+		return "FREQ=DAILY;INTERVAL=" + elems[1], nil
+	case elems[0] == "y" && len(elems) == 1:
+		return "FREQ=YEARLY", nil
+	case elems[0] == "w" && len(elems) == 2:
+		days := strings.Split(elems[1], ",")
+		for i, day := range days {
+			weekday, ok := icalWeekdays[day]
+			if !ok {
+				return "", errInvalidFormat
+			}
+
+			days[i] = weekday
+		}
+
+		return "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ","), nil
+	case elems[0] == "m" && (len(elems) == 2 || len(elems) == 3):
+		rrule := "FREQ=MONTHLY;BYMONTHDAY=" + elems[1]
+		if len(elems) == 3 {
+			if !monthDaysOccur(elems[1], elems[2]) {
+				return "", errInvalidFormat
+			}
+
+			rrule = "FREQ=YEARLY;BYMONTH=" + elems[2] + ";BYMONTHDAY=" + elems[1]
+		}
+
+		return rrule, nil
+	default:
+		return "", errInvalidFormat
+	}
+}
+
+// ImportICS добавляет задачи из компонентов VEVENT и VTODO файла iCalendar.
+// Правила RRULE переводятся в правила повторения задач, а компоненты с правилами,
+// которые нельзя выразить правилом повторения, попадают в отчет как ошибки.
+// Задачи связываются с UID компонентов, поэтому при повторном импорте того же файла
+// ранее импортированные задачи обновляются, а не дублируются. Выполненные задачи
+// (VTODO со статусом COMPLETED) и отмененные компоненты пропускаются.
+func (s *TaskService) ImportICS(r io.Reader) (entities.ImportReport, error) {
+	report := entities.ImportReport{Errors: []entities.ImportError{}}
+
+	calendar, err := ical.Decode(r)
+	if err != nil {
+		return report, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	if calendar.Name != "VCALENDAR" {
+		return report, fmt.Errorf("%w: the file is not an iCalendar file", entities.ErrInvalidImport)
+	}
+
+	i := -1
+	for _, component := range calendar.Components {
+		if component.Name != "VEVENT" && component.Name != "VTODO" {
+			continue
+		}
+		i++
+
+		var uid string
+		if property := component.Get("UID"); property != nil {
+			uid = property.Value
+		}
+
+		if status := component.Get("STATUS"); status != nil &&
+			(strings.EqualFold(status.Value, "COMPLETED") || strings.EqualFold(status.Value, "CANCELLED")) {
+			report.Skipped++
+			continue
+		}
+
+		task, err := s.componentToTask(component)
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Id: uid, Error: err.Error()})
+			continue
+		}
+
+		updated, err := s.importComponentTask(uid, task)
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Id: uid, Error: err.Error()})
+			continue
+		}
+
+		if updated {
+			report.Updated++
+		} else {
+			report.Created++
+		}
+	}
+
+	return report, nil
+}
+
+// importComponentTask сохраняет задачу, полученную из компонента с идентификатором uid.
+// Если с uid уже связана существующая задача, то она обновляется и возвращается true.
+func (s *TaskService) importComponentTask(uid string, task entities.Task) (bool, error) {
+	if uid != "" {
+		id, err := s.store.GetTaskIdByUID(uid)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+
+		if err == nil {
+			if _, err := s.store.SearchTask(id); err == nil {
+				task.Id = id
+				return true, s.editTask(task, entities.ActionImport)
+			}
+		}
+	}
+
+	id, err := s.addTask(task, entities.ActionImport)
+	if err != nil {
+		return false, err
+	}
+
+	if uid != "" {
+		return false, s.store.SetTaskUID(uid, id)
+	}
+
+	return false, nil
+}
+
+// componentToTask возвращает задачу, соответствующую компоненту VEVENT или VTODO.
+func (s *TaskService) componentToTask(component *ical.Component) (entities.Task, error) {
+	var task entities.Task
+
+	if summary := component.Get("SUMMARY"); summary != nil {
+		task.Title = ical.UnescapeText(summary.Value)
+	}
+
+	if description := component.Get("DESCRIPTION"); description != nil {
+		task.Comment = ical.UnescapeText(description.Value)
+	}
+
+	date := component.Get("DTSTART")
+	if component.Name == "VTODO" && component.Get("DUE") != nil {
+		date = component.Get("DUE")
+	}
+
+	if date != nil {
+		if len(date.Value) < 8 {
+			return task, fmt.Errorf("invalid date %q", date.Value)
+		}
+
+		if _, err := time.Parse("20060102", date.Value[:8]); err != nil {
+			return task, fmt.Errorf("invalid date %q", date.Value)
+		}
+
+		task.Date = date.Value[:8]
+	}
+
+	if rrule := component.Get("RRULE"); rrule != nil {
+		if date == nil {
+			return task, errors.New("a recurring component has no start date")
+		}
+
+		repeat, err := s.rruleToRepeat(rrule.Value, task.Date)
+		if err != nil {
+			return task, err
+		}
+
+		task.Repeat = repeat
+	}
+
+	return task, nil
+}
+
+// rruleToRepeat переводит правило RRULE формата iCalendar в правило повторения задачи.
+// date является датой начала повторений в формате 20060102 и используется для правил
+// без явно указанных дней. Правила, которые нельзя выразить правилом повторения
+// (с ограничением COUNT или UNTIL, с интервалом для недель, месяцев и лет и т.д.), отклоняются.
+func (s *TaskService) rruleToRepeat(rrule, date string) (string, error) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return "", fmt.Errorf("invalid RRULE %q", rrule)
+		}
+
+		parts[strings.ToUpper(name)] = strings.ToUpper(value)
+	}
+
+	unsupported := fmt.Errorf("unsupported RRULE %q", rrule)
+
+	interval := 1
+	if value, ok := parts["INTERVAL"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid RRULE %q", rrule)
+		}
+
+		interval = n
+	}
+
+	known := map[string]bool{"FREQ": true, "INTERVAL": true, "BYDAY": true, "BYMONTHDAY": true, "BYMONTH": true, "WKST": true}
+	for name := range parts {
+		if !known[name] {
+			return "", unsupported
+		}
+	}
+
+	start, err := time.Parse("20060102", date)
+	if err != nil {
+		return "", err
+	}
+
+	var repeat string
+
+	switch parts["FREQ"] {
+	case "DAILY":
+		if interval > 366 || parts["BYDAY"] != "" || parts["BYMONTHDAY"] != "" || parts["BYMONTH"] != "" {
+			return "", unsupported
+		}
+
+		repeat = "d " + strconv.Itoa(interval)
+	case "WEEKLY":
+		if parts["BYMONTHDAY"] != "" || parts["BYMONTH"] != "" {
+			return "", unsupported
+		}
+
+		if parts["BYDAY"] == "" {
+			if interval*7 > 366 {
+				return "", unsupported
+			}
+
+			return "d " + strconv.Itoa(interval*7), nil
+		}
+
+		if interval != 1 {
+			return "", unsupported
+		}
+
+		days := strings.Split(parts["BYDAY"], ",")
+		for i, day := range days {
+			number, ok := weekdayNumber(day)
+			if !ok {
+				return "", unsupported
+			}
+
+			days[i] = number
+		}
+
+		repeat = "w " + strings.Join(days, ",")
+	case "MONTHLY":
+		if interval != 1 || parts["BYDAY"] != "" || parts["BYMONTH"] != "" {
+			return "", unsupported
+		}
+
+		days := parts["BYMONTHDAY"]
+		if days == "" {
+			days = strconv.Itoa(start.Day())
+		}
+
+		repeat = "m " + days
+	case "YEARLY":
+		if interval != 1 || parts["BYDAY"] != "" {
+			return "", unsupported
+		}
+
+		if parts["BYMONTHDAY"] == "" && parts["BYMONTH"] == "" {
+			return "y", nil
+		}
+
+		days, months := parts["BYMONTHDAY"], parts["BYMONTH"]
+		if days == "" {
+			days = strconv.Itoa(start.Day())
+		}
+
+		if months == "" {
+			months = strconv.Itoa(int(start.Month()))
+		}
+
+		repeat = "m " + days + " " + months
+	default:
+		return "", unsupported
+	}
+
+	// Проверка того, что полученное правило допустимо для GetNextDate.
+	if _, err := s.GetNextDate(start, date, repeat); err != nil {
+		return "", unsupported
+	}
+
+	return repeat, nil
+}
+
+// weekdayNumber возвращает порядковый номер дня недели RRULE для правила "w".
+func weekdayNumber(day string) (string, bool) {
+	for number, weekday := range icalWeekdays {
+		if weekday == day {
+			return number, true
+		}
+	}
+
+	return "", false
+}
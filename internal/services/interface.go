@@ -1,17 +1,68 @@
 package services
 
 import (
+	"io"
 	"task_scheduler/internal/entities"
+	"task_scheduler/internal/ical"
 	"time"
 )
 
 type TaskServiceInterface interface {
+	As(actor string) TaskServiceInterface
 	AddTask(newTask entities.Task) (string, error)
-	DeleteTask(id string) error
+	DeleteTask(id string, version int) error
+	DoneTask(id string) error
 	EditTask(updatedTask entities.Task) error
 	GetNextDate(now time.Time, date string, repeat string) (string, error)
 	GetTask(id string) (entities.Task, error)
 	GetTasks(target string) ([]entities.Task, error)
+	GetAgenda(from, to time.Time) ([]entities.Day, error)
+	GetOverdueTasks() ([]entities.Day, error)
+	GetCalendar(from, to time.Time) ([]entities.Occurrence, error)
+	GetTrash() ([]entities.TrashedTask, error)
+	RestoreTask(id string) error
+	PurgeTask(id string) error
+	GetCompletions(target string, from, to time.Time) ([]entities.Completion, error)
+	GetHistory(taskId string) ([]entities.Revision, error)
+	RevertTask(revisionId string) (entities.Task, error)
+	Undo() (entities.Task, error)
+	GetReminders(taskId string) ([]entities.Reminder, error)
+	SetReminders(taskId string, rules []string) ([]entities.Reminder, error)
+	GetPendingReminders() ([]entities.Reminder, error)
+	Export() (entities.Export, error)
+	Import(doc entities.Export, mode string) (entities.ImportReport, error)
+	ImportCSV(r io.Reader, mapping map[string]string, dryRun bool) (entities.ImportReport, error)
+	ExportText(format string) ([]byte, error)
+	ImportText(r io.Reader, format string, dryRun bool) (entities.ImportReport, error)
+	ImportFromApp(r io.Reader, source string, dryRun bool) (entities.ImportReport, error)
+	GetCalendarFeed(todo bool) (*ical.Component, error)
+	ImportICS(r io.Reader) (entities.ImportReport, error)
+	GetCalDAVObjects() ([]entities.CalDAVObject, error)
+	GetCalDAVObject(name string) (entities.CalDAVObject, error)
+	PutCalDAVObject(name string, r io.Reader, version int) (bool, error)
+	DeleteCalDAVObject(name string, version int) error
+	CreateFeedToken() (string, error)
+	CheckFeedToken(token string) (bool, error)
+}
+
+type WebhookServiceInterface interface {
+	GetWebhooks() ([]entities.Webhook, error)
+	AddWebhook(webhook entities.Webhook) (entities.Webhook, error)
+	DeleteWebhook(id string) error
+	GetDeliveries(webhookId string) ([]entities.Delivery, error)
+	Redeliver(deliveryId string) (entities.Delivery, error)
+}
+
+type JobServiceInterface interface {
+	GetJob(taskId string) (entities.Job, error)
+	SetJob(taskId string, job entities.Job) (entities.Job, error)
+	ImportJobs(jobs []entities.Job, taskIds map[string]string) []entities.ImportError
+	DeleteJob(taskId string) error
+	GetJobRuns(taskId string) ([]entities.JobRun, error)
+}
+
+type EventStreamInterface interface {
+	Subscribe(lastEventId string) *Subscription
 }
 
 type AuthServiceInterface interface {
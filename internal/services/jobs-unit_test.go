@@ -0,0 +1,231 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"task_scheduler/internal/config"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetJob тестирует проверку действий задач сервисом действий.
+func TestSetJob(t *testing.T) {
+	password := config.Password
+	config.Password = "valid_password"
+	t.Cleanup(func() { config.Password = password })
+
+	mockStore := new(services.MockStorage)
+	task := entities.Task{Id: "1", Date: "20990220", Title: "Резервное копирование", Repeat: "d 1"}
+
+	mockStore.On("SearchTask", "1").Return(task, nil)
+
+	t.Run("disabled", func(t *testing.T) {
+		js := services.GetJobService(mockStore, services.GetTaskService(mockStore), services.JobConfig{})
+
+		_, err := js.SetJob("1", entities.Job{Type: entities.JobCommand, Command: "true"})
+		require.ErrorIs(t, err, entities.ErrJobsDisabled)
+	})
+
+	js := services.GetJobService(mockStore, services.GetTaskService(mockStore), services.JobConfig{Enabled: true})
+
+	t.Run("command", func(t *testing.T) {
+		expected := entities.Job{TaskId: "1", Type: entities.JobCommand, Command: "backup.sh", Timeout: 600}
+		mockStore.On("SetJob", expected).Return(nil).Once()
+
+		job, err := js.SetJob("1", entities.Job{Type: entities.JobCommand, Command: "backup.sh", Url: "https://example.com", Timeout: 600})
+		require.NoError(t, err)
+		require.Equal(t, expected, job)
+	})
+
+	t.Run("command without password", func(t *testing.T) {
+		config.Password = ""
+		defer func() { config.Password = "valid_password" }()
+
+		_, err := js.SetJob("1", entities.Job{Type: entities.JobCommand, Command: "backup.sh"})
+		require.ErrorIs(t, err, entities.ErrJobsDisabled)
+	})
+
+	t.Run("http request without password", func(t *testing.T) {
+		config.Password = ""
+		defer func() { config.Password = "valid_password" }()
+
+		expected := entities.Job{TaskId: "1", Type: entities.JobHTTP, Method: http.MethodGet, Url: "https://ci.example.com/status"}
+		mockStore.On("SetJob", expected).Return(nil).Once()
+
+		_, err := js.SetJob("1", entities.Job{Type: entities.JobHTTP, Url: "https://ci.example.com/status"})
+		require.NoError(t, err)
+	})
+
+	t.Run("http request", func(t *testing.T) {
+		expected := entities.Job{TaskId: "1", Type: entities.JobHTTP, Method: http.MethodPost, Url: "https://ci.example.com/build", Body: `{"ref":"main"}`}
+		mockStore.On("SetJob", expected).Return(nil).Once()
+
+		job, err := js.SetJob("1", entities.Job{Type: entities.JobHTTP, Url: "https://ci.example.com/build", Body: `{"ref":"main"}`})
+		require.NoError(t, err)
+		require.Equal(t, expected, job)
+	})
+
+	for name, job := range map[string]entities.Job{
+		"unknown type":     {Type: "script", Command: "backup.sh"},
+		"empty command":    {Type: entities.JobCommand, Command: "  "},
+		"relative url":     {Type: entities.JobHTTP, Url: "/build"},
+		"unknown method":   {Type: entities.JobHTTP, Method: "TRACE", Url: "https://ci.example.com/build"},
+		"negative timeout": {Type: entities.JobCommand, Command: "backup.sh", Timeout: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := js.SetJob("1", job)
+			require.ErrorIs(t, err, entities.ErrInvalidJob)
+		})
+	}
+
+	t.Run("job not found", func(t *testing.T) {
+		mockStore.On("GetJob", "2").Return(entities.Job{}, sql.ErrNoRows).Once()
+
+		_, err := js.GetJob("2")
+		require.ErrorIs(t, err, entities.ErrJobNotFound)
+	})
+
+	t.Run("import jobs", func(t *testing.T) {
+		mockStore.On("SetJob", entities.Job{TaskId: "1", Type: entities.JobCommand, Command: "backup.sh"}).Return(nil).Once()
+
+		jobs := []entities.Job{
+			{TaskId: "5", Type: entities.JobCommand, Command: "backup.sh"},
+			{TaskId: "6", Type: "script", Command: "backup.sh"},
+			{TaskId: "7", Type: entities.JobCommand, Command: "cleanup.sh"},
+		}
+
+		warnings := js.ImportJobs(jobs, map[string]string{"5": "1", "6": "1"})
+		require.Len(t, warnings, 1)
+		require.Equal(t, 1, warnings[0].Index)
+		require.Equal(t, "6", warnings[0].Id)
+		mockStore.AssertNotCalled(t, "SetJob", entities.Job{TaskId: "1", Type: entities.JobCommand, Command: "cleanup.sh"})
+	})
+}
+
+// TestJobServiceRun тестирует выполнение действий задач при наступлении их дат.
+func TestJobServiceRun(t *testing.T) {
+	task := entities.Task{Id: "1", Date: "20990220", Title: "Резервное копирование", Repeat: "d 1"}
+
+	// start запускает сервис действий с хранилищем, в котором у задачи есть действие job,
+	// и возвращает хранилище, канал завершенных запусков и функцию остановки сервиса.
+	start := func(t *testing.T, job entities.Job, cfg services.JobConfig) (*services.MockStorage, chan entities.JobRun, func()) {
+		mockStore := new(services.MockStorage)
+		runs := make(chan entities.JobRun, 4)
+
+		mockStore.On("GetJob", "1").Return(job, nil)
+		mockStore.On("AddJobRun", mock.Anything).Return("7", nil)
+		mockStore.On("UpdateJobRun", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			if run := args.Get(0).(entities.JobRun); run.FinishedAt != nil {
+				runs <- run
+			}
+		})
+		mockStore.On("SearchTask", "1").Return(task, nil)
+		mockStore.On("UpdateTask", mock.Anything).Return(nil)
+		mockStore.On("AddRevision", mock.Anything).Return(nil)
+		mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil)
+		mockStore.On("AddCompletion", mock.Anything).Return(nil)
+
+		cfg.Enabled = true
+		js := services.GetJobService(mockStore, services.GetTaskService(mockStore), cfg)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			js.Run(ctx)
+			close(done)
+		}()
+
+		require.NoError(t, js.Notify(context.Background(), entities.Event{Type: entities.EventTaskCreated, Task: task}))
+		require.NoError(t, js.Notify(context.Background(), entities.Event{Type: entities.EventTaskDue, Task: task}))
+
+		return mockStore, runs, func() {
+			cancel()
+			<-done
+		}
+	}
+
+	receive := func(t *testing.T, runs chan entities.JobRun) entities.JobRun {
+		select {
+		case run := <-runs:
+			return run
+		case <-time.After(5 * time.Second):
+			t.Fatal("Запуск действия не завершился")
+			return entities.JobRun{}
+		}
+	}
+
+	t.Run("command", func(t *testing.T) {
+		job := entities.Job{TaskId: "1", Type: entities.JobCommand, Command: `echo "$TASK_TITLE $TASK_DATE"; echo oops >&2; exit 3`}
+		mockStore, runs, stop := start(t, job, services.JobConfig{})
+
+		run := receive(t, runs)
+		stop()
+
+		require.Equal(t, "7", run.Id)
+		require.Equal(t, "20990220", run.Date)
+		require.Equal(t, entities.JobFailed, run.Status)
+		require.NotNil(t, run.ExitCode)
+		require.Equal(t, 3, *run.ExitCode)
+		require.Equal(t, "Резервное копирование 20990220\n", run.Stdout)
+		require.Equal(t, "oops\n", run.Stderr)
+		require.NotNil(t, run.StartedAt)
+
+		// Повторяющаяся задача переходит к следующей дате и после неудачного запуска.
+		mockStore.AssertCalled(t, "UpdateTask", mock.MatchedBy(func(updated entities.Task) bool {
+			return updated.Date == "20990221"
+		}))
+		mockStore.AssertNumberOfCalls(t, "AddJobRun", 1)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		job := entities.Job{TaskId: "1", Type: entities.JobCommand, Command: "exec sleep 10"}
+		_, runs, stop := start(t, job, services.JobConfig{Timeout: 200 * time.Millisecond})
+
+		run := receive(t, runs)
+		stop()
+
+		require.Equal(t, entities.JobTimedOut, run.Status)
+		require.Equal(t, "the job has been stopped after 200ms", run.Error)
+	})
+
+	t.Run("http request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "Bearer t0ken", r.Header.Get("Authorization"))
+			require.Equal(t, `{"ref":"main"}`, string(body))
+
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("queued"))
+		}))
+		defer server.Close()
+
+		job := entities.Job{
+			TaskId:  "1",
+			Type:    entities.JobHTTP,
+			Method:  http.MethodPost,
+			Url:     server.URL + "/build",
+			Headers: entities.JobHeaders{"Authorization": "Bearer t0ken"},
+			Body:    `{"ref":"main"}`,
+		}
+		_, runs, stop := start(t, job, services.JobConfig{})
+
+		run := receive(t, runs)
+		stop()
+
+		require.Equal(t, entities.JobSucceeded, run.Status)
+		require.Equal(t, http.StatusAccepted, run.StatusCode)
+		require.Equal(t, "queued", run.Stdout)
+		require.Empty(t, run.Error)
+	})
+}
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"task_scheduler/internal/config"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+const (
+	// jobQueueSize ограничивает количество запусков, ожидающих выполнения.
+	jobQueueSize = 64
+	// jobOutputLimit ограничивает размер сохраняемого вывода команды или тела ответа.
+	jobOutputLimit = 64 << 10
+	// jobMaxTimeout ограничивает время выполнения, которое можно задать действию.
+	jobMaxTimeout = 24 * 60 * 60
+	// jobWaitDelay задает время ожидания закрытия вывода команды после ее остановки.
+	jobWaitDelay = 5 * time.Second
+	// jobRunLimit ограничивает количество записей журнала запусков в ответе API.
+	jobRunLimit = 100
+)
+
+// jobMethods перечисляет методы HTTP запросов, которые можно задать действию.
+var jobMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// JobConfig является структурой параметров выполнения действий задач. Пока Enabled
+// не установлен, действия нельзя задать, а наступление даты задачи их не запускает.
+// Concurrency ограничивает количество одновременно выполняемых действий, Timeout
+// задает время выполнения по умолчанию, а Shell - оболочку для выполнения команд.
+type JobConfig struct {
+	Enabled     bool
+	Concurrency int
+	Timeout     time.Duration
+	Shell       string
+}
+
+// JobService управляет действиями задач и выполняет их при наступлении дат задач.
+// Как уведомитель он только ставит запуски в очередь, а выполняет их Run.
+// После запуска повторяющаяся задача отмечается выполненной, чтобы перейти
+// к следующей дате, а разовая - только после успешного запуска.
+type JobService struct {
+	store  storage.JobInterface
+	tasks  TaskServiceInterface
+	cfg    JobConfig
+	client *http.Client
+	queue  chan jobRequest
+}
+
+// jobRequest является запуском действия, ожидающим выполнения.
+type jobRequest struct {
+	job  entities.Job
+	task entities.Task
+	run  entities.JobRun
+}
+
+func GetJobService(store storage.JobInterface, tasks TaskServiceInterface, cfg JobConfig) *JobService {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 2
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Minute
+	}
+
+	if cfg.Shell == "" {
+		cfg.Shell = "/bin/sh"
+	}
+
+	return &JobService{
+		store:  store,
+		tasks:  tasks,
+		cfg:    cfg,
+		client: &http.Client{},
+		queue:  make(chan jobRequest, jobQueueSize),
+	}
+}
+
+// GetJob возвращает действие задачи с id, полученным из параметра запроса.
+func (js *JobService) GetJob(taskId string) (entities.Job, error) {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return entities.Job{}, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	job, err := js.store.GetJob(taskId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Job{}, entities.ErrJobNotFound
+	}
+
+	return job, err
+}
+
+// SetJob задает действие job задачи с id taskId и возвращает его.
+func (js *JobService) SetJob(taskId string, job entities.Job) (entities.Job, error) {
+	if !js.cfg.Enabled {
+		return entities.Job{}, entities.ErrJobsDisabled
+	}
+
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return entities.Job{}, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	job.Id, job.TaskId = "", taskId
+
+	switch job.Type {
+	case entities.JobCommand:
+		// Без пароля API доступно без аутентификации, и команда дала бы любому
+		// клиенту возможность выполнять произвольный код на сервере.
+		if config.Password == "" {
+			return entities.Job{}, fmt.Errorf("%w: command jobs require a password to be set", entities.ErrJobsDisabled)
+		}
+
+		if strings.TrimSpace(job.Command) == "" {
+			return entities.Job{}, fmt.Errorf("%w: the command is empty", entities.ErrInvalidJob)
+		}
+
+		job.Method, job.Url, job.Headers, job.Body = "", "", nil, ""
+	case entities.JobHTTP:
+		target, err := url.Parse(job.Url)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return entities.Job{}, fmt.Errorf("%w: the url must be an absolute http or https url", entities.ErrInvalidJob)
+		}
+
+		job.Method = strings.ToUpper(job.Method)
+		if job.Method == "" {
+			job.Method = http.MethodGet
+			if job.Body != "" {
+				job.Method = http.MethodPost
+			}
+		}
+
+		if !slices.Contains(jobMethods, job.Method) {
+			return entities.Job{}, fmt.Errorf("%w: unsupported method %q", entities.ErrInvalidJob, job.Method)
+		}
+
+		job.Command = ""
+	default:
+		return entities.Job{}, fmt.Errorf("%w: the type must be %q or %q", entities.ErrInvalidJob, entities.JobCommand, entities.JobHTTP)
+	}
+
+	if job.Timeout < 0 || job.Timeout > jobMaxTimeout {
+		return entities.Job{}, fmt.Errorf("%w: the timeout must be between 0 and %d seconds", entities.ErrInvalidJob, jobMaxTimeout)
+	}
+
+	if _, err := js.store.SearchTask(taskId); err != nil {
+		return entities.Job{}, err
+	}
+
+	if err := js.store.SetJob(job); err != nil {
+		return entities.Job{}, err
+	}
+
+	return job, nil
+}
+
+// ImportJobs задает действия jobs из документа полного экспорта задачам, созданным
+// при импорте, с id из taskIds. Действия проверяются так же, как в SetJob, а ошибки
+// отдельных действий возвращаются как предупреждения отчета об импорте.
+func (js *JobService) ImportJobs(jobs []entities.Job, taskIds map[string]string) []entities.ImportError {
+	var warnings []entities.ImportError
+
+	for i, job := range jobs {
+		id, ok := taskIds[job.TaskId]
+		if !ok {
+			continue
+		}
+
+		if _, err := js.SetJob(id, job); err != nil {
+			warnings = append(warnings, entities.ImportError{
+				Index: i,
+				Id:    job.TaskId,
+				Error: fmt.Sprintf("failed to import the job: %s", err.Error()),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// DeleteJob удаляет действие задачи с id, полученным из параметра запроса.
+// Журнал запусков действия сохраняется.
+func (js *JobService) DeleteJob(taskId string) error {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return errors.New("the id is not specified or is specified not correctly")
+	}
+
+	err := js.store.DeleteJob(taskId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ErrJobNotFound
+	}
+
+	return err
+}
+
+// GetJobRuns возвращает последние записи журнала запусков действия задачи с id taskId.
+func (js *JobService) GetJobRuns(taskId string) ([]entities.JobRun, error) {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return nil, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	return js.store.GetJobRuns(taskId, jobRunLimit)
+}
+
+func (js *JobService) Name() string {
+	return "job"
+}
+
+// Notify ставит в очередь запуск действия задачи, дата которой наступила.
+// Остальные события пропускаются.
+func (js *JobService) Notify(_ context.Context, event entities.Event) error {
+	if !js.cfg.Enabled || event.Type != entities.EventTaskDue {
+		return nil
+	}
+
+	job, err := js.store.GetJob(event.Task.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	run := entities.JobRun{
+		TaskId:   job.TaskId,
+		Date:     event.Task.Date,
+		Type:     job.Type,
+		Status:   entities.JobQueued,
+		QueuedAt: time.Now().Unix(),
+	}
+
+	run.Id, err = js.store.AddJobRun(run)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case js.queue <- jobRequest{job: job, task: event.Task, run: run}:
+		return nil
+	default:
+		js.finish(run, entities.JobFailed, "the job queue is full")
+		return fmt.Errorf("the job queue is full, the job of task %s is skipped", job.TaskId)
+	}
+}
+
+// Run выполняет запуски из очереди не более чем в cfg.Concurrency потоков до отмены
+// контекста ctx. Выполняемые действия при этом останавливаются, а оставшиеся
+// в очереди запуски отмечаются неудавшимися.
+func (js *JobService) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+
+	for range js.cfg.Concurrency {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case req := <-js.queue:
+					js.execute(ctx, req)
+				}
+			}
+		}()
+	}
+
+	workers.Wait()
+
+	for {
+		select {
+		case req := <-js.queue:
+			js.finish(req.run, entities.JobFailed, "the scheduler has been stopped")
+		default:
+			return
+		}
+	}
+}
+
+// execute выполняет действие запуска req, сохраняет результат в журнал запусков
+// и отмечает задачу выполненной.
+func (js *JobService) execute(ctx context.Context, req jobRequest) {
+	run := req.run
+
+	startedAt := time.Now().Unix()
+	run.Status, run.StartedAt = entities.JobRunning, &startedAt
+
+	if err := js.store.UpdateJobRun(run); err != nil {
+		log.Printf("failed to save the job run of task %s: %s\n", run.TaskId, err.Error())
+	}
+
+	timeout := js.cfg.Timeout
+	if req.job.Timeout > 0 {
+		timeout = time.Duration(req.job.Timeout) * time.Second
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	var err error
+	if req.job.Type == entities.JobHTTP {
+		err = js.request(runCtx, req.job, &run)
+	} else {
+		err = js.command(runCtx, req.job, req.task, &run)
+	}
+
+	deadline := runCtx.Err()
+	cancel()
+
+	switch {
+	case err == nil:
+		js.finish(run, entities.JobSucceeded, "")
+	case ctx.Err() != nil:
+		js.finish(run, entities.JobFailed, "the scheduler has been stopped")
+	case errors.Is(deadline, context.DeadlineExceeded):
+		js.finish(run, entities.JobTimedOut, fmt.Sprintf("the job has been stopped after %s", timeout))
+	default:
+		js.finish(run, entities.JobFailed, err.Error())
+	}
+
+	if err == nil || req.task.Repeat != "" {
+		js.complete(req.task)
+	}
+}
+
+// finish сохраняет завершение запуска run с состоянием status и ошибкой message.
+func (js *JobService) finish(run entities.JobRun, status, message string) {
+	finishedAt := time.Now().Unix()
+	run.Status, run.Error, run.FinishedAt = status, message, &finishedAt
+
+	if err := js.store.UpdateJobRun(run); err != nil {
+		log.Printf("failed to save the job run of task %s: %s\n", run.TaskId, err.Error())
+	}
+
+	if status != entities.JobSucceeded {
+		log.Printf("job of task %s %s: %s\n", run.TaskId, status, message)
+	}
+}
+
+// complete отмечает задачу task выполненной, если ее дата не изменилась после запуска.
+func (js *JobService) complete(task entities.Task) {
+	current, err := js.tasks.GetTask(task.Id)
+	if err != nil || current.Date != task.Date {
+		return
+	}
+
+	if err := js.tasks.As("job").DoneTask(task.Id); err != nil {
+		log.Printf("failed to complete task %s after its job: %s\n", task.Id, err.Error())
+	}
+}
+
+// command выполняет команду действия job задачи task оболочкой и сохраняет
+// ее вывод и код завершения в run.
+func (js *JobService) command(ctx context.Context, job entities.Job, task entities.Task, run *entities.JobRun) error {
+	stdout := &outputBuffer{limit: jobOutputLimit}
+	stderr := &outputBuffer{limit: jobOutputLimit}
+
+	cmd := exec.CommandContext(ctx, js.cfg.Shell, "-c", job.Command)
+	cmd.Env = append(os.Environ(), "TASK_ID="+task.Id, "TASK_DATE="+task.Date, "TASK_TITLE="+task.Title)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = jobWaitDelay
+
+	err := cmd.Run()
+
+	run.Stdout, run.Stderr = stdout.String(), stderr.String()
+
+	if cmd.ProcessState != nil {
+		code := cmd.ProcessState.ExitCode()
+		run.ExitCode = &code
+	}
+
+	return err
+}
+
+// request отправляет HTTP запрос действия job и сохраняет статус и тело ответа в run.
+func (js *JobService) request(ctx context.Context, job entities.Job, run *entities.JobRun) error {
+	req, err := http.NewRequestWithContext(ctx, job.Method, job.Url, strings.NewReader(job.Body))
+	if err != nil {
+		return err
+	}
+
+	for name, value := range job.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := js.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := &outputBuffer{limit: jobOutputLimit}
+	_, err = io.Copy(body, resp.Body)
+
+	run.StatusCode, run.Stdout = resp.StatusCode, body.String()
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// outputBuffer сохраняет не более limit байт записанных в него данных.
+type outputBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// String возвращает сохраненные данные в виде текста UTF-8, пригодного для записи в БД.
+func (b *outputBuffer) String() string {
+	text := strings.ToValidUTF8(strings.ReplaceAll(b.buf.String(), "\x00", ""), "�")
+	if b.truncated {
+		text += "\n[output truncated]"
+	}
+
+	return text
+}
@@ -0,0 +1,128 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLock является блокировкой, которую можно захватить, только пока она свободна,
+// и потерю которой можно вызвать методом lose.
+type fakeLock struct {
+	mu       sync.Mutex
+	free     bool
+	held     bool
+	lost     bool
+	unlocked int
+}
+
+func (l *fakeLock) TryLock(_ context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.free {
+		return false, nil
+	}
+
+	l.free, l.held, l.lost = false, true, false
+
+	return true, nil
+}
+
+func (l *fakeLock) Check(_ context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.lost {
+		return errors.New("connection reset")
+	}
+
+	return nil
+}
+
+func (l *fakeLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = false
+	l.unlocked++
+
+	return nil
+}
+
+// release освобождает блокировку, удерживаемую другим экземпляром.
+func (l *fakeLock) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.free = true
+}
+
+// lose вызывает потерю блокировки, удерживаемой экземпляром.
+func (l *fakeLock) lose() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lost = true
+}
+
+// TestLeaderElector тестирует выполнение фоновой работы только экземпляром-лидером.
+func TestLeaderElector(t *testing.T) {
+	lock := &fakeLock{}
+	elector := services.GetLeaderElector(lock, 10*time.Millisecond)
+
+	started := make(chan struct{}, 4)
+	stopped := make(chan struct{}, 4)
+
+	work := func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+	}
+
+	wait := func(ch chan struct{}, message string) {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal(message)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		elector.Run(ctx, work)
+		close(done)
+	}()
+
+	// Пока блокировку удерживает другой экземпляр, работа не выполняется.
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, started)
+
+	lock.release()
+	wait(started, "Работа не запущена после захвата блокировки")
+
+	// При потере блокировки работа останавливается, а после ее освобождения
+	// другим экземпляром запускается снова.
+	lock.lose()
+	wait(stopped, "Работа не остановлена после потери блокировки")
+
+	lock.release()
+	wait(started, "Работа не запущена после повторного захвата блокировки")
+
+	cancel()
+	wait(stopped, "Работа не остановлена после отмены контекста")
+	wait(done, "Выбор лидера не завершился после отмены контекста")
+
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+
+	require.False(t, lock.held)
+	require.Equal(t, 2, lock.unlocked)
+}
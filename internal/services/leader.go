@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+// LeaderElector выбирает среди экземпляров сервиса, работающих с одной БД, лидера,
+// который единственный выполняет фоновую работу по расписанию. Лидером становится
+// экземпляр, захвативший блокировку lock.
+type LeaderElector struct {
+	lock     storage.Locker
+	interval time.Duration
+}
+
+func GetLeaderElector(lock storage.Locker, interval time.Duration) *LeaderElector {
+	return &LeaderElector{lock: lock, interval: interval}
+}
+
+// Run с периодичностью interval пытается захватить блокировку до отмены контекста ctx.
+// Захватив блокировку, экземпляр запускает work и с той же периодичностью проверяет,
+// что блокировка удерживается. При потере блокировки контекст work отменяется,
+// и после завершения work экземпляр снова пытается ее захватить. При отмене ctx
+// Run дожидается завершения work и освобождает блокировку, чтобы лидером сразу
+// мог стать другой экземпляр.
+func (e *LeaderElector) Run(ctx context.Context, work ...func(ctx context.Context)) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		locked, err := e.lock.TryLock(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("failed to acquire the leader lock: %s\n", err.Error())
+		}
+
+		if locked {
+			e.lead(ctx, ticker, work)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead выполняет work, пока экземпляр удерживает блокировку и контекст ctx не отменен.
+func (e *LeaderElector) lead(ctx context.Context, ticker *time.Ticker, work []func(ctx context.Context)) {
+	log.Println("This instance is the leader now")
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+
+	var running sync.WaitGroup
+	for _, run := range work {
+		running.Add(1)
+
+		go func() {
+			defer running.Done()
+			run(leaderCtx)
+		}()
+	}
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			if err := e.lock.Check(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("leadership is lost: %s\n", err.Error())
+				}
+
+				break loop
+			}
+		}
+	}
+
+	cancel()
+	running.Wait()
+
+	if err := e.lock.Unlock(); err != nil {
+		log.Printf("failed to release the leader lock: %s\n", err.Error())
+	}
+}
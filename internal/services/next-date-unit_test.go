@@ -41,6 +41,10 @@ var tbl = []nextDate{
 	{"20240329", "m 10,17 12,8,1", "20240810"},
 	{"20230311", "m 07,19 05,6", "20240507"},
 	{"20230311", "m 1 1,2", "20240201"},
+	{"20240101", "m 30 2", ""},
+	{"20990101", "m 31 4,6", ""},
+	{"20240101", "m 29,30 2", ""},
+	{"20240101", "m 29 2", "20240229"},
 	{"20240127", "m -1", "20240131"},
 	{"20240222", "m -2", "20240228"},
 	{"20240222", "m -2,-3", ""},
@@ -156,6 +156,12 @@ func nextDateByDayOfMonth(now time.Time, date time.Time, elems []string) (string
 
 			monthDir[num] = true
 		}
+
+		// Для дней, которых нет ни в одном из месяцев (например, "m 30 2"),
+		// следующая дата не нашлась бы никогда.
+		if !monthDaysOccur(elems[1], elems[2]) {
+			return "", errInvalidFormat
+		}
 	}
 
 	for {
@@ -180,3 +186,36 @@ func getNegativeDay(date time.Time) int {
 	tmpDate := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.Local)
 	return date.Day() - tmpDate.Day() - 1
 }
+
+// monthDaysOccur проверяет, что каждый из дней days правила "m" встречается хотя бы
+// в одном из месяцев months (например, 30 февраля не встречается никогда).
+// Некорректные номера не проверяются и отклоняются GetNextDate.
+func monthDaysOccur(days, months string) bool {
+	for _, day := range strings.Split(days, ",") {
+		num, err := strconv.Atoi(day)
+		if err != nil || num < 0 {
+			continue
+		}
+
+		occurs := false
+		for _, month := range strings.Split(months, ",") {
+			m, err := strconv.Atoi(month)
+			if err != nil || m < 1 || m > 12 {
+				occurs = true
+				break
+			}
+
+			// Високосный год, чтобы 29 февраля считалось встречающимся днем.
+			if num <= time.Date(2024, time.Month(m)+1, 0, 0, 0, 0, 0, time.Local).Day() {
+				occurs = true
+				break
+			}
+		}
+
+		if !occurs {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"log"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+// wheelSlots задает количество ячеек колеса таймеров напоминаний.
+const wheelSlots = 512
+
+// wheelEntry является напоминанием в ячейке колеса таймеров. rounds содержит
+// количество полных оборотов колеса, оставшихся до срабатывания напоминания.
+type wheelEntry struct {
+	reminder entities.Reminder
+	rounds   int
+}
+
+// timerWheel является колесом таймеров: напоминание помещается в ячейку, до которой
+// стрелка дойдет к моменту его срабатывания, поэтому на каждом шаге проверяется
+// только одна ячейка, а не все ожидающие напоминания.
+type timerWheel struct {
+	slots [][]wheelEntry
+	pos   int
+	tick  time.Duration
+	now   time.Time
+}
+
+func newTimerWheel(tick time.Duration, now time.Time) *timerWheel {
+	return &timerWheel{slots: make([][]wheelEntry, wheelSlots), tick: tick, now: now}
+}
+
+// add помещает напоминание reminder в колесо. Просроченное напоминание сработает
+// на следующем шаге.
+func (w *timerWheel) add(reminder entities.Reminder) {
+	delay := time.Unix(reminder.FireAt, 0).Sub(w.now)
+
+	ticks := int((delay + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	slot := (w.pos + ticks) % len(w.slots)
+	w.slots[slot] = append(w.slots[slot], wheelEntry{reminder: reminder, rounds: (ticks - 1) / len(w.slots)})
+}
+
+// advance передвигает стрелку колеса на один шаг и возвращает сработавшие напоминания.
+func (w *timerWheel) advance() []entities.Reminder {
+	w.pos = (w.pos + 1) % len(w.slots)
+	w.now = w.now.Add(w.tick)
+
+	var (
+		due     []entities.Reminder
+		pending = w.slots[w.pos][:0]
+	)
+
+	for _, entry := range w.slots[w.pos] {
+		if entry.rounds > 0 {
+			entry.rounds--
+			pending = append(pending, entry)
+			continue
+		}
+
+		due = append(due, entry.reminder)
+	}
+
+	w.slots[w.pos] = pending
+
+	return due
+}
+
+// reset удаляет все напоминания из колеса и переводит его время на now.
+func (w *timerWheel) reset(now time.Time) {
+	for i := range w.slots {
+		w.slots[i] = nil
+	}
+
+	w.now = now
+}
+
+// ReminderScheduler отправляет уведомителям напоминания о задачах в момент их срабатывания.
+type ReminderScheduler struct {
+	store     storage.ReminderInterface
+	notifiers []Notifier
+}
+
+func GetReminderScheduler(store storage.ReminderInterface, notifiers ...Notifier) *ReminderScheduler {
+	return &ReminderScheduler{store: store, notifiers: notifiers}
+}
+
+// Run с шагом tick отправляет сработавшие напоминания до отмены контекста ctx.
+// С периодичностью sync колесо таймеров заполняется заново напоминаниями, которые
+// сработают в ближайшие два периода, поэтому изменения напоминаний и дат задач
+// учитываются не позднее чем через sync.
+func (rs *ReminderScheduler) Run(ctx context.Context, tick, sync time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	wheel := newTimerWheel(tick, time.Now())
+	var synced time.Time
+
+	for {
+		if now := time.Now(); now.Sub(synced) >= sync {
+			if err := rs.load(wheel, now, sync); err != nil {
+				log.Printf("failed to load pending reminders: %s\n", err.Error())
+			} else {
+				synced = now
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, reminder := range wheel.advance() {
+			rs.fire(context.WithoutCancel(ctx), reminder, time.Now())
+		}
+	}
+}
+
+// load заполняет колесо wheel напоминаниями, которые сработают не позднее now+2*sync.
+func (rs *ReminderScheduler) load(wheel *timerWheel, now time.Time, sync time.Duration) error {
+	reminders, err := rs.store.GetPendingReminders(now.Add(2 * sync).Unix())
+	if err != nil {
+		return err
+	}
+
+	wheel.reset(now)
+	for _, reminder := range reminders {
+		wheel.add(reminder)
+	}
+
+	return nil
+}
+
+// fire отмечает напоминание reminder отправленным и сообщает о нем уведомителям.
+// Напоминание, уже отмеченное другим экземпляром сервиса или удаленное, пропускается.
+func (rs *ReminderScheduler) fire(ctx context.Context, reminder entities.Reminder, now time.Time) {
+	fired, err := rs.store.MarkReminderFired(reminder.Id, now.Unix())
+	if err != nil {
+		log.Printf("failed to mark reminder %s as fired: %s\n", reminder.Id, err.Error())
+		return
+	}
+
+	if !fired {
+		return
+	}
+
+	event := entities.Event{
+		Type:     entities.EventReminder,
+		Task:     entities.Task{Id: reminder.TaskId, Title: reminder.Title, Date: reminder.Date},
+		Reminder: &reminder,
+		FiredAt:  now.Unix(),
+	}
+
+	for _, notifier := range rs.notifiers {
+		if err := notifier.Notify(ctx, event); err != nil {
+			log.Printf("notifier %q failed to send %s event of task %s: %s\n", notifier.Name(), event.Type, reminder.TaskId, err.Error())
+		}
+	}
+}
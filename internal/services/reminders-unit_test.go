@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"context"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetReminders тестирует метод SetReminders сервиса задач.
+func TestSetReminders(t *testing.T) {
+	task := entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Version: 1}
+	day := time.Date(2099, 2, 20, 0, 0, 0, 0, time.Local)
+
+	t.Run("valid rules", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		expected := []entities.Reminder{
+			{TaskId: "1", Rule: "1 day before", Offset: -24 * 60, FireAt: day.AddDate(0, 0, -1).Unix()},
+			{TaskId: "1", Rule: "on the day at 09:00", Offset: 9 * 60, FireAt: day.Add(9 * time.Hour).Unix()},
+			{TaskId: "1", Rule: "2 hours before", Offset: -120, FireAt: day.Add(-2 * time.Hour).Unix()},
+			{TaskId: "1", Rule: "1 day before at 18:00", Offset: -6 * 60, FireAt: day.Add(-6 * time.Hour).Unix()},
+		}
+
+		mockStore.On("SearchTask", "1").Return(task, nil)
+		mockStore.On("ReplaceReminders", "1", expected).Return(nil)
+		mockStore.On("GetReminders", "1").Return(expected, nil)
+
+		reminders, err := s.SetReminders("1", []string{"1 day before", "On the day  at 09:00", "2 hours before", "120 minutes before", "1 day before at 18:00"})
+
+		require.NoError(t, err)
+		require.Equal(t, expected, reminders)
+	})
+
+	t.Run("remove reminders", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("SearchTask", "1").Return(task, nil)
+		mockStore.On("ReplaceReminders", "1", []entities.Reminder{}).Return(nil)
+		mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil)
+
+		reminders, err := s.SetReminders("1", nil)
+
+		require.NoError(t, err)
+		require.Empty(t, reminders)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		mockStore.On("SearchTask", "1").Return(task, nil)
+
+		for _, rule := range []string{"", "tomorrow", "0 days before", "2 hours after", "at 25:00", "on the day at 9:60", "60 weeks before"} {
+			_, err := s.SetReminders("1", []string{rule})
+
+			require.ErrorIsf(t, err, entities.ErrInvalidReminder, "Правило %q не должно приниматься", rule)
+		}
+
+		_, err := s.SetReminders("isnotnum", []string{"1 day before"})
+		require.Error(t, err)
+
+		mockStore.AssertNotCalled(t, "ReplaceReminders", mock.Anything, mock.Anything)
+	})
+}
+
+// chanNotifier передает полученные события в канал.
+type chanNotifier chan entities.Event
+
+func (n chanNotifier) Name() string {
+	return "chan"
+}
+
+func (n chanNotifier) Notify(_ context.Context, event entities.Event) error {
+	n <- event
+	return nil
+}
+
+// TestReminderSchedulerRun тестирует отправку напоминаний планировщиком напоминаний.
+func TestReminderSchedulerRun(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	notifier := make(chanNotifier, 1)
+	rs := services.GetReminderScheduler(mockStore, notifier)
+
+	now := time.Now()
+	due := entities.Reminder{Id: "1", TaskId: "2", Rule: "on the day", FireAt: now.Unix(), Title: "Просмотр матча", Date: now.Format("20060102")}
+	taken := entities.Reminder{Id: "2", TaskId: "3", Rule: "on the day", FireAt: now.Unix(), Title: "Чтение книги", Date: now.Format("20060102")}
+	later := entities.Reminder{Id: "3", TaskId: "2", Rule: "on the day at 23:59", FireAt: now.Add(time.Hour).Unix()}
+
+	mockStore.On("GetPendingReminders", mock.Anything).Return([]entities.Reminder{due, taken, later}, nil)
+	mockStore.On("MarkReminderFired", "1", mock.Anything).Return(true, nil)
+	mockStore.On("MarkReminderFired", "2", mock.Anything).Return(false, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		rs.Run(ctx, 10*time.Millisecond, time.Hour)
+		close(done)
+	}()
+
+	var event entities.Event
+
+	select {
+	case event = <-notifier:
+	case <-time.After(time.Second):
+		t.Fatal("Напоминание не было отправлено")
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Планировщик напоминаний не остановился после отмены контекста")
+	}
+
+	require.Equal(t, entities.EventReminder, event.Type)
+	require.Equal(t, entities.Task{Id: "2", Title: "Просмотр матча", Date: due.Date}, event.Task)
+	require.Equal(t, "1", event.Reminder.Id)
+	require.Empty(t, notifier)
+	mockStore.AssertCalled(t, "MarkReminderFired", "2", mock.Anything)
+	mockStore.AssertNotCalled(t, "MarkReminderFired", "3", mock.Anything)
+}
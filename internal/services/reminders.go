@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// maxReminders ограничивает количество напоминаний одной задачи.
+const maxReminders = 10
+
+// reminderRule соответствует правилу напоминания: "2 hours before", "1 day before at 18:00",
+// "on the day", "on the day at 09:00" или "at 09:00".
+var reminderRule = regexp.MustCompile(`^(?:([0-9]+) (minute|hour|day|week)s? before|on the day)?(?: ?at ([0-9]{1,2}):([0-9]{2}))?$`)
+
+// reminderUnits задает длительность единиц времени правил напоминаний в минутах.
+var reminderUnits = map[string]int{
+	"minute": 1,
+	"hour":   60,
+	"day":    24 * 60,
+	"week":   7 * 24 * 60,
+}
+
+// GetReminders возвращает напоминания задачи с id, полученным из параметра запроса.
+func (s *TaskService) GetReminders(taskId string) ([]entities.Reminder, error) {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return nil, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	return s.store.GetReminders(taskId)
+}
+
+// SetReminders заменяет напоминания задачи с id taskId напоминаниями по правилам rules
+// и возвращает новые напоминания. Время срабатывания отсчитывается от начала дня задачи.
+func (s *TaskService) SetReminders(taskId string, rules []string) ([]entities.Reminder, error) {
+	if _, err := strconv.Atoi(taskId); err != nil {
+		return nil, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	if len(rules) > maxReminders {
+		return nil, fmt.Errorf("%w: a task can have at most %d reminders", entities.ErrInvalidReminder, maxReminders)
+	}
+
+	task, err := s.store.SearchTask(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]entities.Reminder, 0, len(rules))
+	seen := make(map[int]bool, len(rules))
+
+	for _, rule := range rules {
+		rule = strings.Join(strings.Fields(strings.ToLower(rule)), " ")
+
+		offset, err := parseReminderRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[offset] {
+			continue
+		}
+		seen[offset] = true
+
+		fireAt, err := reminderTime(task.Date, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, entities.Reminder{TaskId: taskId, Rule: rule, Offset: offset, FireAt: fireAt.Unix()})
+	}
+
+	if err := s.store.ReplaceReminders(taskId, reminders); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetReminders(taskId)
+}
+
+// GetPendingReminders возвращает все ожидающие отправки напоминания в порядке их срабатывания.
+func (s *TaskService) GetPendingReminders() ([]entities.Reminder, error) {
+	return s.store.GetPendingReminders(math.MaxInt64)
+}
+
+// rescheduleReminders пересчитывает время срабатывания напоминаний задачи task после
+// переноса ее даты. Уже отправленные напоминания снова становятся ожидающими.
+func (s *TaskService) rescheduleReminders(task entities.Task) error {
+	reminders, err := s.store.GetReminders(task.Id)
+	if err != nil || len(reminders) == 0 {
+		return err
+	}
+
+	for i := range reminders {
+		fireAt, err := reminderTime(task.Date, reminders[i].Offset)
+		if err != nil {
+			return err
+		}
+
+		reminders[i].FireAt = fireAt.Unix()
+		reminders[i].FiredAt = nil
+	}
+
+	return s.store.ReplaceReminders(task.Id, reminders)
+}
+
+// parseReminderRule возвращает смещение времени напоминания в минутах от начала дня задачи
+// по правилу rule. Например, "2 hours before" соответствует -120, "on the day at 09:00" - 540,
+// а "1 day before at 18:00" - -360.
+func parseReminderRule(rule string) (int, error) {
+	match := reminderRule.FindStringSubmatch(rule)
+	if match == nil || rule == "" {
+		return 0, fmt.Errorf("%w: unrecognized reminder rule %q", entities.ErrInvalidReminder, rule)
+	}
+
+	offset := 0
+
+	if match[1] != "" {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n*reminderUnits[match[2]] > 366*24*60 {
+			return 0, fmt.Errorf("%w: invalid reminder offset in %q", entities.ErrInvalidReminder, rule)
+		}
+
+		offset -= n * reminderUnits[match[2]]
+	}
+
+	if match[3] != "" {
+		hour, _ := strconv.Atoi(match[3])
+		minute, _ := strconv.Atoi(match[4])
+
+		if hour > 23 || minute > 59 {
+			return 0, fmt.Errorf("%w: invalid reminder time in %q", entities.ErrInvalidReminder, rule)
+		}
+
+		offset += hour*60 + minute
+	}
+
+	return offset, nil
+}
+
+// reminderTime возвращает время срабатывания напоминания со смещением offset минут
+// от начала дня date в местном времени.
+func reminderTime(date string, offset int) (time.Time, error) {
+	day, err := time.Parse("20060102", date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, offset, 0, 0, time.Local), nil
+}
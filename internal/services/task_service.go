@@ -4,8 +4,21 @@ import "task_scheduler/internal/storage"
 
 type TaskService struct {
 	store storage.StorageInterface
+	// actor идентифицирует инициатора изменений задач и сохраняется в их истории.
+	actor string
+	undo  *undoStore
+	// notifiers получают события добавления, изменения, выполнения и удаления задач.
+	notifiers []Notifier
 }
 
-func GetTaskService(store storage.StorageInterface) *TaskService {
-	return &TaskService{store: store}
+func GetTaskService(store storage.StorageInterface, notifiers ...Notifier) *TaskService {
+	return &TaskService{store: store, undo: newUndoStore(), notifiers: notifiers}
+}
+
+// As возвращает копию сервиса задач, изменения через которую выполняются от имени actor.
+func (s *TaskService) As(actor string) TaskServiceInterface {
+	clone := *s
+	clone.actor = actor
+
+	return &clone
 }
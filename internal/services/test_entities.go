@@ -1,7 +1,10 @@
 package services
 
 import (
+	"context"
+	"io"
 	"task_scheduler/internal/entities"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -30,12 +33,252 @@ func (m *MockStorage) SearchTask(id string) (entities.Task, error) {
 	return args.Get(0).(entities.Task), args.Error(1)
 }
 
+func (m *MockStorage) GetTasksByPeriod(from, to string) ([]entities.Task, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]entities.Task), args.Error(1)
+}
+
+func (m *MockStorage) GetTasksBefore(date string) ([]entities.Task, error) {
+	args := m.Called(date)
+	return args.Get(0).([]entities.Task), args.Error(1)
+}
+
 func (m *MockStorage) UpdateTask(task entities.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
 
-func (m *MockStorage) DeleteTask(id string) error {
+func (m *MockStorage) DeleteTask(id string, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetTrash() ([]entities.TrashedTask, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.TrashedTask), args.Error(1)
+}
+
+func (m *MockStorage) RestoreTask(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) PurgeTask(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) PurgeTrash(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStorage) AddCompletion(completion entities.Completion) error {
+	args := m.Called(completion)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteCompletion(taskId, date string) error {
+	args := m.Called(taskId, date)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetCompletions(target string, from, to int64) ([]entities.Completion, error) {
+	args := m.Called(target, from, to)
+	return args.Get(0).([]entities.Completion), args.Error(1)
+}
+
+func (m *MockStorage) AddRevision(revision entities.Revision) error {
+	args := m.Called(revision)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetRevisions(taskId string) ([]entities.Revision, error) {
+	args := m.Called(taskId)
+	return args.Get(0).([]entities.Revision), args.Error(1)
+}
+
+func (m *MockStorage) GetRevision(id string) (entities.Revision, error) {
 	args := m.Called(id)
+	return args.Get(0).(entities.Revision), args.Error(1)
+}
+
+func (m *MockStorage) GetAllRevisions() ([]entities.Revision, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Revision), args.Error(1)
+}
+
+func (m *MockStorage) ReplaceFeedToken(tokenHash string, createdAt int64) error {
+	args := m.Called(tokenHash, createdAt)
+	return args.Error(0)
+}
+
+func (m *MockStorage) FeedTokenExists(tokenHash string) (bool, error) {
+	args := m.Called(tokenHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetTaskIdByUID(uid string) (string, error) {
+	args := m.Called(uid)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) SetTaskUID(uid, taskId string) error {
+	args := m.Called(uid, taskId)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetCalDAVResources() ([]entities.CalDAVResource, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.CalDAVResource), args.Error(1)
+}
+
+func (m *MockStorage) SetCalDAVResource(resource entities.CalDAVResource) error {
+	args := m.Called(resource)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteCalDAVResource(taskId string) error {
+	args := m.Called(taskId)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Backup(w io.Writer) error {
+	args := m.Called(w)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Restore(r io.Reader) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetDueTasks(date, event string) ([]entities.Task, error) {
+	args := m.Called(date, event)
+	return args.Get(0).([]entities.Task), args.Error(1)
+}
+
+func (m *MockStorage) MarkFired(taskId, date, event string, firedAt int64) (bool, error) {
+	args := m.Called(taskId, date, event, firedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetAllReminders() ([]entities.Reminder, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) GetReminders(taskId string) ([]entities.Reminder, error) {
+	args := m.Called(taskId)
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) ReplaceReminders(taskId string, reminders []entities.Reminder) error {
+	args := m.Called(taskId, reminders)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetPendingReminders(before int64) ([]entities.Reminder, error) {
+	args := m.Called(before)
+	return args.Get(0).([]entities.Reminder), args.Error(1)
+}
+
+func (m *MockStorage) GetInstanceId() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) MarkReminderFired(id string, firedAt int64) (bool, error) {
+	args := m.Called(id, firedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetWebhooks() ([]entities.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Webhook), args.Error(1)
+}
+
+func (m *MockStorage) GetWebhook(id string) (entities.Webhook, error) {
+	args := m.Called(id)
+	return args.Get(0).(entities.Webhook), args.Error(1)
+}
+
+func (m *MockStorage) AddWebhook(webhook entities.Webhook) (string, error) {
+	args := m.Called(webhook)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) DeleteWebhook(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) AddDelivery(delivery entities.Delivery) (string, error) {
+	args := m.Called(delivery)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) GetDelivery(id string) (entities.Delivery, error) {
+	args := m.Called(id)
+	return args.Get(0).(entities.Delivery), args.Error(1)
+}
+
+func (m *MockStorage) GetDeliveries(webhookId string, limit int) ([]entities.Delivery, error) {
+	args := m.Called(webhookId, limit)
+	return args.Get(0).([]entities.Delivery), args.Error(1)
+}
+
+func (m *MockStorage) GetPendingDeliveries(before int64, limit int) ([]entities.Delivery, error) {
+	args := m.Called(before, limit)
+	return args.Get(0).([]entities.Delivery), args.Error(1)
+}
+
+func (m *MockStorage) UpdateDelivery(delivery entities.Delivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetJobs() ([]entities.Job, error) {
+	args := m.Called()
+	return args.Get(0).([]entities.Job), args.Error(1)
+}
+
+func (m *MockStorage) GetJob(taskId string) (entities.Job, error) {
+	args := m.Called(taskId)
+	return args.Get(0).(entities.Job), args.Error(1)
+}
+
+func (m *MockStorage) SetJob(job entities.Job) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteJob(taskId string) error {
+	args := m.Called(taskId)
+	return args.Error(0)
+}
+
+func (m *MockStorage) AddJobRun(run entities.JobRun) (string, error) {
+	args := m.Called(run)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) UpdateJobRun(run entities.JobRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetJobRuns(taskId string, limit int) ([]entities.JobRun, error) {
+	args := m.Called(taskId, limit)
+	return args.Get(0).([]entities.JobRun), args.Error(1)
+}
+
+func (m *MockStorage) PublishEvent(channel string, payload []byte) error {
+	args := m.Called(channel, payload)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListenEvents(ctx context.Context, channel string, onListen func(), handle func(payload []byte)) error {
+	args := m.Called(ctx, channel, onListen, handle)
 	return args.Error(0)
 }
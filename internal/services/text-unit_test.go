@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"strings"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportText тестирует метод ExportText сервиса задач.
+func TestExportText(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	tasks := []entities.Task{
+		{Id: "1", Date: "20990101", Title: "(A) Просмотр фильма +досуг", Comment: "Выбрать фильм\nКупить попкорн"},
+		{Id: "2", Date: "20990102", Title: "Просмотр матча", Repeat: "d 14"},
+		{Id: "3", Date: "20990105", Title: "Прогулка", Repeat: "w 1"},
+		{Id: "4", Date: "20990105", Title: "Чтение книги", Repeat: "w 1,3"},
+		{Id: "5", Date: "20990115", Title: "Оплата счетов", Repeat: "m 15"},
+		{Id: "6", Date: "20990101", Title: "Зарплата", Comment: "Проверить 100% суммы", Repeat: "m 1,15"},
+	}
+	mockStore.On("GetTasks").Return(tasks, nil)
+
+	data, err := s.ExportText(entities.TextTodoTxt)
+	require.NoError(t, err)
+	require.Equal(t, "(A) Просмотр фильма +досуг due:2099-01-01 comment:Выбрать%20фильм%0AКупить%20попкорн\n"+
+		"Просмотр матча due:2099-01-02 rec:2w\n"+
+		"Прогулка due:2099-01-05 rec:1w\n"+
+		"Чтение книги due:2099-01-05 repeat:w%201,3\n"+
+		"Оплата счетов due:2099-01-15 rec:1m\n"+
+		"Зарплата due:2099-01-01 repeat:m%201,15 comment:Проверить%20100%25%20суммы\n", string(data))
+
+	data, err = s.ExportText(entities.TextMarkdown)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "- [ ] (A) Просмотр фильма +досуг due:2099-01-01\n"+
+		"  Выбрать фильм\n"+
+		"  Купить попкорн\n"+
+		"- [ ] Просмотр матча due:2099-01-02 rec:2w\n"))
+
+	_, err = s.ExportText("org")
+	require.ErrorIs(t, err, entities.ErrUnknownFormat)
+}
+
+// TestImportText тестирует метод ImportText сервиса задач.
+func TestImportText(t *testing.T) {
+	t.Run("todo.txt", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		data := "(A) 2098-12-01 Просмотр фильма +досуг @дом due:2099-01-01\n" +
+			"\n" +
+			"x 2098-12-02 2098-12-01 Чтение книги\n" +
+			"Просмотр матча due:2099-01-02 rec:+2w\n" +
+			"Оплата счетов due:2099-01-15 rec:1m\n" +
+			"Уборка due:01.01.2099\n" +
+			"Прогулка rec:2m\n" +
+			"Зарплата due:2099-01-01 repeat:m%201,15 rec:1m comment:Проверить%20100%25%20суммы\n"
+
+		mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "(A) Просмотр фильма +досуг @дом"}).Return("1", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990102", Title: "Просмотр матча", Repeat: "d 14"}).Return("2", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990115", Title: "Оплата счетов", Repeat: "m 15"}).Return("3", nil)
+		mockStore.On("PostTask", entities.Task{Date: "20990101", Title: "Зарплата", Comment: "Проверить 100% суммы", Repeat: "m 1,15"}).Return("4", nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionImport
+		})).Return(nil)
+
+		report, err := s.ImportText(strings.NewReader(data), entities.TextTodoTxt, false)
+
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Created)
+		require.Equal(t, 1, report.Skipped)
+		require.Len(t, report.Errors, 2)
+		require.Equal(t, []int{6, 7}, []int{report.Errors[0].Line, report.Errors[1].Line})
+	})
+
+	t.Run("markdown preview", func(t *testing.T) {
+		mockStore := new(services.MockStorage)
+		s := services.GetTaskService(mockStore)
+
+		data := "# Дела\n" +
+			"\n" +
+			"- [ ] Просмотр фильма due:2099-01-01\n" +
+			"  Выбрать фильм\n" +
+			"\n" +
+			"  Купить попкорн\n" +
+			"- [x] Чтение книги\n" +
+			"  Прочитано\n" +
+			"* [ ] Прогулка due:2099-01-05 rec:1y\n" +
+			"- [ ] Чтение книги due:2099-01-05 repeat:w%201,3 comment:Глава%201\n" +
+			"  Глава 2\n" +
+			"Заметки без отступа\n"
+
+		report, err := s.ImportText(strings.NewReader(data), entities.TextMarkdown, true)
+
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, 3, report.Created)
+		require.Equal(t, 1, report.Skipped)
+		require.Equal(t, []entities.Task{
+			{Date: "20990101", Title: "Просмотр фильма", Comment: "Выбрать фильм\nКупить попкорн"},
+			{Date: "20990105", Title: "Прогулка", Repeat: "y"},
+			{Date: "20990105", Title: "Чтение книги", Comment: "Глава 1\nГлава 2", Repeat: "w 1,3"},
+		}, report.Preview)
+		mockStore.AssertNotCalled(t, "PostTask", mock.Anything)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		s := services.GetTaskService(new(services.MockStorage))
+
+		_, err := s.ImportText(strings.NewReader(""), "org", true)
+		require.ErrorIs(t, err, entities.ErrInvalidImport)
+	})
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"task_scheduler/internal/entities"
+	"time"
+	"unicode"
+)
+
+var (
+	// todoPriority соответствует приоритету задачи todo.txt, например "(A)".
+	todoPriority = regexp.MustCompile(`^\([A-Z]\)$`)
+	// todoRec соответствует расширению rec: todo.txt, например "rec:2w" или "rec:+1m".
+	todoRec = regexp.MustCompile(`^\+?([0-9]+)([dwmy])$`)
+	// markdownItem соответствует пункту списка задач Markdown, например "- [ ] задача".
+	markdownItem = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s*(.*)$`)
+)
+
+// textItem является задачей, прочитанной из текстового списка.
+// line содержит номер строки, с которой начинается задача.
+type textItem struct {
+	line     int
+	done     bool
+	priority string
+	text     string
+	due      string
+	rec      string
+	repeat   string
+	comment  []string
+}
+
+// ExportText возвращает все задачи в виде текстового списка в формате format
+// (entities.TextTodoTxt или entities.TextMarkdown).
+// Задачи не имеют отдельного приоритета, поэтому приоритет todo.txt хранится в начале названия.
+// Правила повторения, которые нельзя выразить расширением rec:, записываются в расширение
+// repeat:, а комментарии в файле todo.txt - в расширение comment:. Знак "%" и пробельные
+// символы в значениях этих расширений экранируются, как в URL.
+func (s *TaskService) ExportText(format string) ([]byte, error) {
+	if format != entities.TextTodoTxt && format != entities.TextMarkdown {
+		return nil, fmt.Errorf("%w %q", entities.ErrUnknownFormat, format)
+	}
+
+	tasks, err := s.store.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	for _, task := range tasks {
+		line := task.Title
+
+		if date, err := time.Parse("20060102", task.Date); err == nil {
+			line += " due:" + date.Format(time.DateOnly)
+
+			if rec, ok := repeatToRec(task.Repeat, date); ok {
+				line += " rec:" + rec
+			} else if task.Repeat != "" {
+				line += " repeat:" + escapeTodoValue(task.Repeat)
+			}
+		}
+
+		if format == entities.TextTodoTxt {
+			if task.Comment != "" {
+				line += " comment:" + escapeTodoValue(task.Comment)
+			}
+
+			buf.WriteString(line + "\n")
+			continue
+		}
+
+		buf.WriteString("- [ ] " + line + "\n")
+
+		for _, comment := range strings.Split(task.Comment, "\n") {
+			if comment = strings.TrimSpace(comment); comment != "" {
+				buf.WriteString("  " + comment + "\n")
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ImportText добавляет задачи из текстового списка в формате format (entities.TextTodoTxt
+// или entities.TextMarkdown). Дата задачи берется из расширения due:, правило повторения
+// из расширения repeat: или rec:, комментарий из расширения comment:, а приоритет остается
+// в начале названия. В списке Markdown строки с отступом под пунктом добавляются
+// к комментарию задачи. Выполненные задачи пропускаются.
+// При dryRun задачи не сохраняются, а отчет содержит задачи, которые были бы добавлены.
+func (s *TaskService) ImportText(r io.Reader, format string, dryRun bool) (entities.ImportReport, error) {
+	report := entities.ImportReport{Errors: []entities.ImportError{}, DryRun: dryRun}
+
+	var (
+		items []textItem
+		err   error
+	)
+
+	switch format {
+	case entities.TextTodoTxt:
+		items, err = readTodoTxt(r)
+	case entities.TextMarkdown:
+		items, err = readMarkdown(r)
+	default:
+		return report, fmt.Errorf("%w: unknown format %q", entities.ErrInvalidImport, format)
+	}
+
+	if err != nil {
+		return report, err
+	}
+
+	for i, item := range items {
+		if item.done {
+			report.Skipped++
+			continue
+		}
+
+		task, err := s.textItemToTask(item)
+		if err == nil && !dryRun {
+			task.Id, err = s.addTask(task, entities.ActionImport)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, entities.ImportError{Index: i, Line: item.line, Error: err.Error()})
+			continue
+		}
+
+		if dryRun {
+			report.Preview = append(report.Preview, task)
+		}
+
+		report.Created++
+	}
+
+	return report, nil
+}
+
+// readTodoTxt читает задачи из файла todo.txt, пропуская пустые строки.
+func readTodoTxt(r io.Reader) ([]textItem, error) {
+	var items []textItem
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" {
+			continue
+		}
+
+		item := parseTodoLine(text)
+		item.line = line
+		items = append(items, item)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	return items, nil
+}
+
+// readMarkdown читает задачи из пунктов списков задач Markdown. Непустые строки
+// с отступом, следующие за пунктом, добавляются к комментарию задачи, а остальные
+// строки (заголовки, абзацы) пропускаются.
+func readMarkdown(r io.Reader) ([]textItem, error) {
+	var (
+		items   []textItem
+		current *textItem
+	)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+
+		if match := markdownItem.FindStringSubmatch(text); match != nil {
+			item := parseTodoLine(match[2])
+			item.line = line
+			item.done = match[1] != " "
+			items = append(items, item)
+			current = &items[len(items)-1]
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "":
+		case current != nil && trimmed != text:
+			current.comment = append(current.comment, trimmed)
+		default:
+			current = nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", entities.ErrInvalidImport, err.Error())
+	}
+
+	return items, nil
+}
+
+// parseTodoLine разбирает строку задачи todo.txt: признак выполнения "x", приоритет,
+// даты выполнения и создания, описание и расширения due:, rec:, repeat: и comment:.
+// Остальные расширения, проекты (+проект) и контексты (@контекст) остаются в описании.
+func parseTodoLine(line string) textItem {
+	var item textItem
+
+	fields := strings.Fields(line)
+
+	if len(fields) > 0 && fields[0] == "x" {
+		item.done = true
+		fields = fields[1:]
+
+		// За признаком выполнения следует дата выполнения и, возможно, дата создания.
+		for range 2 {
+			if len(fields) > 0 && isTodoDate(fields[0]) {
+				fields = fields[1:]
+			}
+		}
+	} else {
+		if len(fields) > 0 && todoPriority.MatchString(fields[0]) {
+			item.priority = fields[0]
+			fields = fields[1:]
+		}
+
+		if len(fields) > 0 && isTodoDate(fields[0]) {
+			fields = fields[1:]
+		}
+	}
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "due:"):
+			item.due = strings.TrimPrefix(field, "due:")
+		case strings.HasPrefix(field, "rec:"):
+			item.rec = strings.TrimPrefix(field, "rec:")
+		case strings.HasPrefix(field, "repeat:"):
+			item.repeat = unescapeTodoValue(strings.TrimPrefix(field, "repeat:"))
+		case strings.HasPrefix(field, "comment:"):
+			item.comment = append(item.comment, unescapeTodoValue(strings.TrimPrefix(field, "comment:")))
+		default:
+			words = append(words, field)
+		}
+	}
+
+	item.text = strings.Join(words, " ")
+
+	return item
+}
+
+// escapeTodoValue экранирует в значении расширения todo.txt знак "%" и пробельные символы,
+// которые разделяют поля строки todo.txt.
+func escapeTodoValue(value string) string {
+	var b strings.Builder
+
+	for _, r := range value {
+		if r != '%' && !unicode.IsSpace(r) {
+			b.WriteRune(r)
+			continue
+		}
+
+		for _, c := range []byte(string(r)) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+// unescapeTodoValue возвращает значение расширения todo.txt, экранированное escapeTodoValue.
+// Значение с некорректной последовательностью "%" возвращается без изменений.
+func unescapeTodoValue(value string) string {
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+
+	return value
+}
+
+// isTodoDate проверяет, является ли value датой todo.txt в формате 2006-01-02.
+func isTodoDate(value string) bool {
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// textItemToTask возвращает задачу из пункта текстового списка, проверенную
+// по тем же правилам, что и в AddTask.
+func (s *TaskService) textItemToTask(item textItem) (entities.Task, error) {
+	task := entities.Task{
+		Title:   item.text,
+		Comment: strings.Join(item.comment, "\n"),
+	}
+
+	if item.priority != "" && item.text != "" {
+		task.Title = item.priority + " " + item.text
+	}
+
+	date := time.Now()
+	if item.due != "" {
+		due, err := time.Parse(time.DateOnly, item.due)
+		if err != nil {
+			return task, fmt.Errorf("invalid due date %q", item.due)
+		}
+
+		date = due
+		task.Date = due.Format("20060102")
+	}
+
+	// Расширение repeat: содержит правило повторения задачи без перевода и точнее rec:.
+	if item.repeat != "" {
+		task.Repeat = item.repeat
+	} else if item.rec != "" {
+		repeat, err := recToRepeat(item.rec, date)
+		if err != nil {
+			return task, err
+		}
+
+		task.Repeat = repeat
+	}
+
+	return s.validateImportedTask(task)
+}
+
+// recToRepeat переводит расширение rec: todo.txt в правило повторения задачи.
+// Повторение раз в несколько дней или недель переводится в правило "d", ежемесячное
+// повторение в правило "m" с числом месяца из даты date, а ежегодное в правило "y".
+// Префикс "+" (повторение от даты задачи, а не от даты выполнения) не учитывается,
+// так как следующая дата задачи всегда вычисляется от ее текущей даты.
+func recToRepeat(rec string, date time.Time) (string, error) {
+	match := todoRec.FindStringSubmatch(rec)
+	if match == nil {
+		return "", fmt.Errorf("invalid rec value %q", rec)
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("invalid rec value %q", rec)
+	}
+
+	switch match[2] {
+	case "d":
+		return "d " + strconv.Itoa(n), nil
+	case "w":
+		return "d " + strconv.Itoa(n*7), nil
+	case "m":
+		if n == 1 {
+			return "m " + strconv.Itoa(date.Day()), nil
+		}
+	case "y":
+		if n == 1 {
+			return "y", nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported rec value %q", rec)
+}
+
+// repeatToRec переводит правило повторения задачи с датой date в расширение rec: todo.txt.
+// Возвращает false, если правило нельзя выразить расширением rec:.
+func repeatToRec(repeat string, date time.Time) (string, bool) {
+	elems := strings.Fields(repeat)
+	if len(elems) == 0 {
+		return "", false
+	}
+
+	switch {
+	case elems[0] == "d" && len(elems) == 2:
+		n, err := strconv.Atoi(elems[1])
+		if err != nil || n < 1 {
+			return "", false
+		}
+
+		if n%7 == 0 {
+			return strconv.Itoa(n/7) + "w", true
+		}
+
+		return strconv.Itoa(n) + "d", true
+	case elems[0] == "y" && len(elems) == 1:
+		return "1y", true
+	case elems[0] == "w" && len(elems) == 2:
+		weekday := int(date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+
+		if elems[1] == strconv.Itoa(weekday) {
+			return "1w", true
+		}
+	case elems[0] == "m" && len(elems) == 2:
+		if elems[1] == strconv.Itoa(date.Day()) {
+			return "1m", true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"errors"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetTrash тестирует метод GetTrash сервиса задач.
+func TestGetTrash(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	trash := []entities.TrashedTask{
+		{Task: validTasksTableForGet[0], DeletedAt: 1700000000},
+	}
+
+	mockStore.On("GetTrash").Return(trash, nil)
+	actualTrash, err := s.GetTrash()
+
+	require.NoError(t, err)
+	require.Equal(t, trash, actualTrash)
+}
+
+// TestRestoreTask тестирует метод RestoreTask сервиса задач.
+func TestRestoreTask(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	t.Run("restore valid task", func(t *testing.T) {
+		mockStore.On("RestoreTask", "1").Return(nil)
+		mockStore.On("SearchTask", "1").Return(validTasksTableForGet[0], nil)
+		mockStore.On("AddRevision", mock.MatchedBy(func(revision entities.Revision) bool {
+			return revision.Action == entities.ActionRestore && revision.Before == nil
+		})).Return(nil)
+		err := s.RestoreTask("1")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("restore invalid task", func(t *testing.T) {
+		err := s.RestoreTask("isnotnum")
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "RestoreTask", "isnotnum")
+	})
+}
+
+// TestPurgeTask тестирует метод PurgeTask сервиса задач.
+func TestPurgeTask(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	t.Run("purge valid task", func(t *testing.T) {
+		mockStore.On("PurgeTask", "1").Return(nil)
+		err := s.PurgeTask("1")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("purge invalid task", func(t *testing.T) {
+		err := s.PurgeTask("")
+
+		require.Error(t, err)
+		mockStore.AssertNotCalled(t, "PurgeTask", "")
+	})
+}
+
+// TestPurgeTrash тестирует метод PurgeTrash сервиса задач.
+func TestPurgeTrash(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	s := services.GetTaskService(mockStore)
+
+	retention := 30 * 24 * time.Hour
+
+	t.Run("purge expired tasks", func(t *testing.T) {
+		mockStore.On("PurgeTrash", mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before)-retention < time.Minute
+		})).Return(int64(2), nil)
+		purged, err := s.PurgeTrash(retention)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(2), purged)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStore.ExpectedCalls = nil
+		mockStore.On("PurgeTrash", mock.Anything).Return(int64(0), errors.New("failed"))
+		_, err := s.PurgeTrash(retention)
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+// GetTrash возвращает задачи, находящиеся в корзине.
+func (s *TaskService) GetTrash() ([]entities.TrashedTask, error) {
+	return s.store.GetTrash()
+}
+
+// RestoreTask восстанавливает задачу с id, полученным из параметра запроса, из корзины.
+func (s *TaskService) RestoreTask(id string) error {
+	return s.restoreTask(id, entities.ActionRestore)
+}
+
+// restoreTask восстанавливает задачу из корзины и сохраняет восстановление в историю как действие action.
+func (s *TaskService) restoreTask(id string, action string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return errors.New("the id is not specified or is specified not correctly")
+	}
+
+	if err := s.store.RestoreTask(id); err != nil {
+		return err
+	}
+
+	task, err := s.store.SearchTask(id)
+	if err != nil {
+		return err
+	}
+
+	return s.addRevision(action, nil, &task)
+}
+
+// PurgeTask окончательно удаляет задачу с id, полученным из параметра запроса, из корзины.
+func (s *TaskService) PurgeTask(id string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return errors.New("the id is not specified or is specified not correctly")
+	}
+
+	return s.store.PurgeTask(id)
+}
+
+// PurgeTrash окончательно удаляет задачи, пролежавшие в корзине дольше retention,
+// и возвращает их количество.
+func (s *TaskService) PurgeTrash(retention time.Duration) (int64, error) {
+	return s.store.PurgeTrash(time.Now().Add(-retention))
+}
+
+// RunTrashPurge с периодичностью interval очищает корзину от задач, пролежавших
+// в ней дольше retention, до отмены контекста ctx.
+func (s *TaskService) RunTrashPurge(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeTrash(retention)
+		if err != nil {
+			log.Printf("failed to purge trash: %s\n", err.Error())
+		} else if purged > 0 {
+			log.Printf("%d task(s) purged from trash\n", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
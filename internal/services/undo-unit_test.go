@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUndo тестирует метод Undo сервиса задач.
+func TestUndo(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	service := services.GetTaskService(mockStore)
+	s := service.As("192.0.2.1/session")
+
+	before := entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Version: 1}
+	edited := entities.Task{Id: "1", Date: "20990221", Title: "Просмотр матча", Version: 1}
+
+	mockStore.On("AddRevision", mock.Anything).Return(nil)
+	mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil)
+
+	t.Run("nothing to undo", func(t *testing.T) {
+		_, err := s.Undo()
+
+		require.ErrorIs(t, err, entities.ErrNothingToUndo)
+	})
+
+	t.Run("undo edit", func(t *testing.T) {
+		mockStore.On("SearchTask", "1").Return(before, nil).Once()
+		mockStore.On("UpdateTask", edited).Return(nil).Once()
+		require.NoError(t, s.EditTask(edited))
+
+		// Сессия другого пользователя не может отменить чужое изменение.
+		_, err := service.As("192.0.2.2/other").Undo()
+		require.ErrorIs(t, err, entities.ErrNothingToUndo)
+
+		current := edited
+		current.Version = 2
+		mockStore.On("SearchTask", "1").Return(current, nil).Once()
+		mockStore.On("UpdateTask", entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Version: 2}).Return(nil).Once()
+
+		task, err := s.Undo()
+
+		require.NoError(t, err)
+		require.Equal(t, entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Version: 3}, task)
+	})
+
+	t.Run("undo edit changed by another request", func(t *testing.T) {
+		mockStore.On("SearchTask", "1").Return(before, nil).Once()
+		mockStore.On("UpdateTask", edited).Return(nil).Once()
+		require.NoError(t, s.EditTask(edited))
+
+		current := edited
+		current.Version = 5
+		mockStore.On("SearchTask", "1").Return(current, nil).Once()
+
+		_, err := s.Undo()
+
+		require.ErrorIs(t, err, entities.ErrVersionMismatch)
+	})
+
+	t.Run("undo completion", func(t *testing.T) {
+		repeated := entities.Task{Id: "2", Date: "20990220", Title: "Планерка", Repeat: "d 1", Version: 1}
+		mockStore.On("SearchTask", "2").Return(repeated, nil).Twice()
+		mockStore.On("UpdateTask", mock.MatchedBy(func(task entities.Task) bool { return task.Date == "20990221" })).Return(nil).Once()
+		mockStore.On("GetReminders", "2").Return([]entities.Reminder{}, nil)
+		mockStore.On("AddCompletion", mock.Anything).Return(nil).Once()
+		require.NoError(t, s.DoneTask("2"))
+
+		current := repeated
+		current.Date, current.Version = "20990221", 2
+		mockStore.On("SearchTask", "2").Return(current, nil).Once()
+		mockStore.On("UpdateTask", entities.Task{Id: "2", Date: "20990220", Title: "Планерка", Repeat: "d 1", Version: 2}).Return(nil).Once()
+		mockStore.On("DeleteCompletion", "2", "20990220").Return(nil).Once()
+
+		task, err := s.Undo()
+
+		require.NoError(t, err)
+		require.Equal(t, "20990220", task.Date)
+		mockStore.AssertCalled(t, "DeleteCompletion", "2", "20990220")
+	})
+
+	t.Run("undo delete", func(t *testing.T) {
+		mockStore.On("SearchTask", "1").Return(before, nil).Once()
+		mockStore.On("DeleteTask", "1", 1).Return(nil).Once()
+		mockStore.On("DeleteCalDAVResource", "1").Return(nil).Once()
+		require.NoError(t, s.DeleteTask("1", 1))
+
+		restored := before
+		restored.Version = 3
+		mockStore.On("RestoreTask", "1").Return(nil).Once()
+		mockStore.On("SearchTask", "1").Return(restored, nil)
+
+		task, err := s.Undo()
+
+		require.NoError(t, err)
+		require.Equal(t, restored, task)
+
+		_, err = s.Undo()
+		require.ErrorIs(t, err, entities.ErrNothingToUndo)
+	})
+}
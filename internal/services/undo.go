@@ -0,0 +1,132 @@
+package services
+
+import (
+	"sync"
+	"task_scheduler/internal/entities"
+	"time"
+)
+
+const (
+	// undoWindow задает время, в течение которого изменение задачи можно отменить.
+	undoWindow = 10 * time.Minute
+	// undoDepth ограничивает количество изменений, хранимых для отмены в одной сессии.
+	undoDepth = 20
+)
+
+// undoEntry является записью об изменении задачи, которое можно отменить.
+// before содержит состояние задачи до изменения, deleted указывает, что задача
+// была перемещена в корзину, а completed - что изменение было выполнением задачи.
+type undoEntry struct {
+	before    entities.Task
+	deleted   bool
+	completed bool
+	createdAt time.Time
+}
+
+// undoStore хранит стеки отменяемых изменений для каждой сессии.
+type undoStore struct {
+	mu     sync.Mutex
+	stacks map[string][]undoEntry
+}
+
+func newUndoStore() *undoStore {
+	return &undoStore{stacks: make(map[string][]undoEntry)}
+}
+
+// push добавляет изменение в стек сессии session, вытесняя самые старые записи.
+// Стеки сессий, последнее изменение которых старше undoWindow, удаляются,
+// чтобы не хранить стеки завершенных сессий.
+func (u *undoStore) push(session string, entry undoEntry) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, stack := range u.stacks {
+		if len(stack) == 0 || time.Since(stack[len(stack)-1].createdAt) > undoWindow {
+			delete(u.stacks, key)
+		}
+	}
+
+	stack := append(u.stacks[session], entry)
+	if len(stack) > undoDepth {
+		stack = stack[len(stack)-undoDepth:]
+	}
+
+	u.stacks[session] = stack
+}
+
+// pop извлекает последнее изменение из стека сессии session, если оно не старше undoWindow.
+// Устаревшие изменения удаляются вместе со всеми предшествующими им.
+func (u *undoStore) pop(session string) (undoEntry, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stack := u.stacks[session]
+	if len(stack) == 0 {
+		return undoEntry{}, false
+	}
+
+	entry := stack[len(stack)-1]
+	if time.Since(entry.createdAt) > undoWindow {
+		delete(u.stacks, session)
+		return undoEntry{}, false
+	}
+
+	if len(stack) == 1 {
+		delete(u.stacks, session)
+	} else {
+		u.stacks[session] = stack[:len(stack)-1]
+	}
+
+	return entry, true
+}
+
+// Undo отменяет последнее изменение, удаление или выполнение задачи, сделанное в текущей
+// сессии не ранее undoWindow назад, и возвращает восстановленную задачу.
+// Если задача была изменена после этого другим запросом, то возвращается entities.ErrVersionMismatch.
+func (s *TaskService) Undo() (entities.Task, error) {
+	entry, ok := s.undo.pop(s.actor)
+	if !ok {
+		return entities.Task{}, entities.ErrNothingToUndo
+	}
+
+	if entry.deleted {
+		if err := s.restoreTask(entry.before.Id, entities.ActionUndo); err != nil {
+			return entities.Task{}, err
+		}
+
+		if err := s.undoCompletion(entry); err != nil {
+			return entities.Task{}, err
+		}
+
+		return s.store.SearchTask(entry.before.Id)
+	}
+
+	current, err := s.store.SearchTask(entry.before.Id)
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	if current.Version != entry.before.Version+1 {
+		return entities.Task{}, entities.ErrVersionMismatch
+	}
+
+	task := entry.before
+	task.Version = current.Version
+
+	task, err = s.replaceTask(entities.ActionUndo, current, task)
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	return task, s.undoCompletion(entry)
+}
+
+// undoCompletion удаляет из журнала выполненных задач запись о выполнении,
+// отмененном изменением entry. Для остальных изменений ничего не делает.
+func (s *TaskService) undoCompletion(entry undoEntry) error {
+	if !entry.completed {
+		return nil
+	}
+
+	return s.store.DeleteCompletion(entry.before.Id, entry.before.Date)
+}
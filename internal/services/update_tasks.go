@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"log"
 	"strconv"
 	"task_scheduler/internal/entities"
 	"time"
@@ -9,23 +10,42 @@ import (
 
 // AddTask добавляет задачу с параметрами, полученными из тела запроса.
 func (s *TaskService) AddTask(newTask entities.Task) (string, error) {
-	var (
-		id  string
-		err error
-	)
+	return s.addTask(newTask, entities.ActionCreate)
+}
+
+// addTask добавляет задачу и сохраняет ее создание в историю как действие action.
+func (s *TaskService) addTask(newTask entities.Task, action string) (string, error) {
+	newTask, err := s.prepareTask(newTask)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.store.PostTask(newTask)
+	if err != nil {
+		return "", err
+	}
 
+	newTask.Id, newTask.Version = id, 1
+
+	return id, s.addRevision(action, nil, &newTask)
+}
+
+// prepareTask проверяет параметры новой задачи и возвращает задачу в том виде,
+// в котором она будет сохранена: без даты задача назначается на сегодня, а задача
+// с прошедшей датой переносится на сегодня или на следующую дату по правилу повторения.
+func (s *TaskService) prepareTask(newTask entities.Task) (entities.Task, error) {
 	if newTask.Date == "" {
 		newTask.Date = time.Now().Format("20060102")
 	}
 
 	if newTask.Title == "" {
-		return "", errors.New("the task title is empty")
+		return newTask, errors.New("the task title is empty")
 	}
 
 	now := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, time.Local)
 	date, err := time.Parse("20060102", newTask.Date)
 	if err != nil {
-		return "", err
+		return newTask, err
 	}
 
 	if date.Before(now) {
@@ -34,18 +54,21 @@ func (s *TaskService) AddTask(newTask entities.Task) (string, error) {
 		} else {
 			newTask.Date, err = s.GetNextDate(now, newTask.Date, newTask.Repeat)
 			if err != nil {
-				return "", err
+				return newTask, err
 			}
 		}
 	}
 
-	id, err = s.store.PostTask(newTask)
-
-	return id, err
+	return newTask, nil
 }
 
 // editTask изменяет пармаетры задачи, полученные из тела запроса.
 func (s *TaskService) EditTask(updatedTask entities.Task) error {
+	return s.editTask(updatedTask, entities.ActionUpdate)
+}
+
+// editTask изменяет параметры задачи и сохраняет изменение в историю как действие action.
+func (s *TaskService) editTask(updatedTask entities.Task, action string) error {
 	var err error
 
 	if updatedTask.Date == "" {
@@ -77,18 +100,114 @@ func (s *TaskService) EditTask(updatedTask entities.Task) error {
 		}
 	}
 
-	err = s.store.UpdateTask(updatedTask)
+	before, err := s.store.SearchTask(updatedTask.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.replaceTask(action, before, updatedTask); err != nil {
+		return err
+	}
+
+	s.undo.push(s.actor, undoEntry{before: before, completed: action == entities.ActionComplete, createdAt: time.Now()})
+
+	return nil
+}
 
-	return err
+// replaceTask сохраняет состояние task задачи, находившейся в состоянии before, пересчитывает
+// напоминания при переносе ее даты и сохраняет изменение в историю как действие action.
+// Возвращается сохраненная задача с новой версией.
+func (s *TaskService) replaceTask(action string, before, task entities.Task) (entities.Task, error) {
+	if err := s.store.UpdateTask(task); err != nil {
+		return entities.Task{}, err
+	}
+
+	task.Version = before.Version + 1
+
+	if before.Date != task.Date {
+		if err := s.rescheduleReminders(task); err != nil {
+			return entities.Task{}, err
+		}
+	}
+
+	return task, s.addRevision(action, &before, &task)
 }
 
 // deleteTask удаляет задачу с id, полученным из параметра запроса.
-func (s *TaskService) DeleteTask(id string) error {
+// Если version отличается от нуля, то задача удаляется только при совпадении версии.
+func (s *TaskService) DeleteTask(id string, version int) error {
+	return s.deleteTask(id, version, entities.ActionDelete)
+}
+
+// deleteTask перемещает задачу в корзину и сохраняет удаление в историю как действие action.
+func (s *TaskService) deleteTask(id string, version int, action string) error {
 	if _, err := strconv.Atoi(id); err != nil {
 		return errors.New("the id is not specified or is specified not correctly")
 	}
 
-	err := s.store.DeleteTask(id)
+	before, err := s.store.SearchTask(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.DeleteTask(id, version)
+	if err != nil {
+		return err
+	}
+
+	// Ресурс CalDAV задачи удаляется вместе с ней, иначе клиенты CalDAV не смогли бы
+	// снова использовать его имя.
+	if err := s.store.DeleteCalDAVResource(id); err != nil {
+		return err
+	}
+
+	s.undo.push(s.actor, undoEntry{before: before, deleted: true, completed: action == entities.ActionComplete, createdAt: time.Now()})
+
+	return s.addRevision(action, &before, nil)
+}
+
+// DoneTask отмечает задачу с id, полученным из параметра запроса, выполненной.
+// Задача с правилом повторения переносится на следующую дату, а задача без него удаляется.
+// Каждое выполнение сохраняется в журнал выполненных задач.
+func (s *TaskService) DoneTask(id string) error {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return err
+	}
+
+	completion := entities.Completion{
+		TaskId:      task.Id,
+		Title:       task.Title,
+		Date:        task.Date,
+		CompletedAt: time.Now().Unix(),
+	}
+
+	if task.Repeat != "" {
+		task.Date, err = s.GetNextDate(time.Now(), task.Date, task.Repeat)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Выполнение сохраняется до переноса или удаления задачи, чтобы ошибка записи в журнал
+	// не оставила задачу выполненной с ошибкой в ответе: повторный запрос выполнил бы ее снова.
+	if err := s.store.AddCompletion(completion); err != nil {
+		return err
+	}
+
+	if task.Repeat != "" {
+		err = s.editTask(task, entities.ActionComplete)
+	} else {
+		err = s.deleteTask(id, task.Version, entities.ActionComplete)
+	}
+
+	if err != nil {
+		if err := s.store.DeleteCompletion(completion.TaskId, completion.Date); err != nil {
+			log.Printf("failed to delete the completion of task %s: %s\n", completion.TaskId, err.Error())
+		}
+
+		return err
+	}
 
-	return err
+	return nil
 }
@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskServiceEvents тестирует события, о которых сервис задач сообщает уведомителям.
+func TestTaskServiceEvents(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	notifier := &recordingNotifier{}
+	s := services.GetTaskService(mockStore, notifier).As("192.0.2.1")
+
+	task := entities.Task{Id: "1", Date: "20990220", Title: "Просмотр фильма", Version: 1}
+
+	mockStore.On("PostTask", mock.Anything).Return("1", nil)
+	mockStore.On("SearchTask", "1").Return(task, nil)
+	mockStore.On("UpdateTask", mock.Anything).Return(nil)
+	mockStore.On("DeleteTask", "1", 1).Return(nil)
+	mockStore.On("DeleteCalDAVResource", "1").Return(nil)
+	mockStore.On("AddCompletion", mock.Anything).Return(nil)
+	mockStore.On("GetReminders", "1").Return([]entities.Reminder{}, nil)
+	mockStore.On("AddRevision", mock.Anything).Return(nil)
+
+	_, err := s.AddTask(entities.Task{Date: "20990220", Title: "Просмотр фильма"})
+	require.NoError(t, err)
+	require.NoError(t, s.EditTask(entities.Task{Id: "1", Date: "20990221", Title: "Просмотр матча"}))
+	require.NoError(t, s.DeleteTask("1", 1))
+	require.NoError(t, s.DoneTask("1"))
+
+	types := make([]string, 0, len(notifier.events))
+	for _, event := range notifier.events {
+		types = append(types, event.Type)
+		require.Equal(t, "192.0.2.1", event.Actor)
+		require.Equal(t, "1", event.Task.Id)
+	}
+
+	require.Equal(t, []string{entities.EventTaskCreated, entities.EventTaskUpdated, entities.EventTaskDeleted, entities.EventTaskDone}, types)
+	require.Equal(t, "Просмотр матча", notifier.events[1].Task.Title)
+}
+
+// TestAddWebhook тестирует метод AddWebhook сервиса подписок.
+func TestAddWebhook(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	ws := services.GetWebhookService(mockStore)
+
+	t.Run("valid webhook", func(t *testing.T) {
+		mockStore.On("AddWebhook", mock.MatchedBy(func(webhook entities.Webhook) bool {
+			return webhook.Url == "https://ci.example.com/hook" && len(webhook.Secret) == 64 && len(webhook.Events) == 1
+		})).Return("1", nil).Once()
+
+		webhook, err := ws.AddWebhook(entities.Webhook{Url: "https://ci.example.com/hook", Events: entities.EventTypes{entities.EventTaskDone}})
+
+		require.NoError(t, err)
+		require.Equal(t, "1", webhook.Id)
+		require.NotEmpty(t, webhook.Secret)
+	})
+
+	t.Run("invalid webhooks", func(t *testing.T) {
+		for _, webhook := range []entities.Webhook{
+			{Url: ""},
+			{Url: "ftp://ci.example.com/hook"},
+			{Url: "/hook"},
+			{Url: "https://ci.example.com/hook", Events: entities.EventTypes{"task.archived"}},
+		} {
+			_, err := ws.AddWebhook(webhook)
+
+			require.ErrorIs(t, err, entities.ErrInvalidWebhook)
+		}
+
+		mockStore.AssertNumberOfCalls(t, "AddWebhook", 1)
+	})
+
+	t.Run("delete missing webhook", func(t *testing.T) {
+		mockStore.On("DeleteWebhook", "5").Return(sql.ErrNoRows).Once()
+
+		require.ErrorIs(t, ws.DeleteWebhook("5"), entities.ErrWebhookNotFound)
+	})
+}
+
+// TestWebhookNotify тестирует сохранение доставок событий подпискам.
+func TestWebhookNotify(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	ws := services.GetWebhookService(mockStore)
+
+	event := entities.Event{Type: entities.EventTaskDone, Task: entities.Task{Id: "1", Title: "Просмотр фильма"}, FiredAt: 1700000000}
+	payload, _ := json.Marshal(event)
+
+	mockStore.On("GetWebhooks").Return([]entities.Webhook{
+		{Id: "1", Url: "https://ci.example.com/hook", Events: entities.EventTypes{}},
+		{Id: "2", Url: "https://chat.example.com/hook", Events: entities.EventTypes{entities.EventTaskCreated}},
+		{Id: "3", Url: "https://bot.example.com/hook", Events: entities.EventTypes{entities.EventTaskDone, entities.EventTaskDeleted}},
+	}, nil)
+	mockStore.On("AddDelivery", mock.MatchedBy(func(delivery entities.Delivery) bool {
+		return delivery.Event == entities.EventTaskDone && delivery.Status == entities.DeliveryPending && string(delivery.Payload) == string(payload)
+	})).Return("7", nil)
+
+	require.NoError(t, ws.Notify(context.Background(), event))
+
+	mockStore.AssertNumberOfCalls(t, "AddDelivery", 2)
+	mockStore.AssertNotCalled(t, "AddDelivery", mock.MatchedBy(func(delivery entities.Delivery) bool {
+		return delivery.WebhookId == "2"
+	}))
+}
+
+// TestWebhookDeliver тестирует доставку событий подписчикам и повторные попытки.
+func TestWebhookDeliver(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := entities.Payload(`{"type":"task.done","task":{"id":"1"},"fired_at":1700000000}`)
+
+	var signature string
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Webhook-Signature")
+
+		require.Equal(t, string(payload), string(body))
+		require.Equal(t, entities.EventTaskDone, r.Header.Get("X-Webhook-Event"))
+	}))
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	mockStore := new(services.MockStorage)
+	ws := services.GetWebhookService(mockStore)
+
+	mockStore.On("GetPendingDeliveries", now.Unix(), mock.Anything).Return([]entities.Delivery{
+		{Id: "1", WebhookId: "1", Event: entities.EventTaskDone, Payload: payload, Status: entities.DeliveryPending},
+		{Id: "2", WebhookId: "2", Event: entities.EventTaskDone, Payload: payload, Status: entities.DeliveryPending, Attempts: 2},
+		{Id: "3", WebhookId: "2", Event: entities.EventTaskDone, Payload: payload, Status: entities.DeliveryPending, Attempts: 7},
+		{Id: "4", WebhookId: "3", Event: entities.EventTaskDone, Payload: payload, Status: entities.DeliveryPending},
+	}, nil)
+	mockStore.On("GetWebhook", "1").Return(entities.Webhook{Id: "1", Url: ok.URL, Secret: "s3cr3t"}, nil).Once()
+	mockStore.On("GetWebhook", "2").Return(entities.Webhook{Id: "2", Url: failing.URL, Secret: "s3cr3t"}, nil).Once()
+	mockStore.On("GetWebhook", "3").Return(entities.Webhook{}, sql.ErrNoRows).Once()
+
+	var updated []entities.Delivery
+	mockStore.On("UpdateDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		updated = append(updated, args.Get(0).(entities.Delivery))
+	}).Return(nil)
+
+	count, err := ws.Deliver(context.Background(), now)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, "sha256="+services.SignPayload("s3cr3t", payload), signature)
+	require.Len(t, updated, 4)
+
+	require.Equal(t, entities.DeliveryDelivered, updated[0].Status)
+	require.Equal(t, http.StatusOK, updated[0].ResponseCode)
+	require.NotNil(t, updated[0].DeliveredAt)
+
+	require.Equal(t, entities.DeliveryPending, updated[1].Status)
+	require.Equal(t, 3, updated[1].Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, updated[1].ResponseCode)
+	require.Equal(t, now.Add(2*time.Minute).Unix(), updated[1].NextAttemptAt)
+	require.NotEmpty(t, updated[1].LastError)
+
+	require.Equal(t, entities.DeliveryFailed, updated[2].Status)
+	require.Equal(t, 8, updated[2].Attempts)
+
+	require.Equal(t, entities.DeliveryFailed, updated[3].Status)
+}
+
+// TestRedeliver тестирует метод Redeliver сервиса подписок.
+func TestRedeliver(t *testing.T) {
+	mockStore := new(services.MockStorage)
+	ws := services.GetWebhookService(mockStore)
+
+	original := entities.Delivery{Id: "3", WebhookId: "2", Event: entities.EventTaskDone, Payload: entities.Payload(`{}`), Status: entities.DeliveryFailed, Attempts: 8}
+
+	mockStore.On("GetDelivery", "3").Return(original, nil)
+	mockStore.On("GetDelivery", "4").Return(entities.Delivery{}, sql.ErrNoRows)
+	mockStore.On("AddDelivery", mock.MatchedBy(func(delivery entities.Delivery) bool {
+		return delivery.WebhookId == "2" && delivery.Status == entities.DeliveryPending && delivery.Attempts == 0
+	})).Return("9", nil)
+
+	delivery, err := ws.Redeliver("3")
+
+	require.NoError(t, err)
+	require.Equal(t, "9", delivery.Id)
+
+	_, err = ws.Redeliver("4")
+	require.ErrorIs(t, err, entities.ErrWebhookNotFound)
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"task_scheduler/internal/entities"
+	"task_scheduler/internal/storage"
+	"time"
+)
+
+const (
+	// webhookMaxAttempts ограничивает количество попыток доставки события.
+	webhookMaxAttempts = 8
+	// webhookRetryBase задает задержку перед второй попыткой доставки,
+	// которая удваивается с каждой следующей попыткой.
+	webhookRetryBase = 30 * time.Second
+	// webhookRetryMax ограничивает задержку между попытками доставки.
+	webhookRetryMax = time.Hour
+	// webhookBatch ограничивает количество доставок за один проход.
+	webhookBatch = 100
+	// webhookLogLimit ограничивает количество записей журнала доставок в ответе API.
+	webhookLogLimit = 100
+)
+
+// webhookEvents перечисляет события, на которые можно подписаться.
+var webhookEvents = []string{
+	entities.EventTaskCreated,
+	entities.EventTaskUpdated,
+	entities.EventTaskDone,
+	entities.EventTaskDeleted,
+	entities.EventTaskDue,
+	entities.EventReminder,
+}
+
+// WebhookService управляет подписками на события задач и доставляет им события.
+// Как уведомитель он только сохраняет доставки в журнал, а отправляет их Run.
+type WebhookService struct {
+	store  storage.WebhookInterface
+	client *http.Client
+	// wake сообщает Run о появлении новых доставок.
+	wake chan struct{}
+}
+
+func GetWebhookService(store storage.WebhookInterface) *WebhookService {
+	return &WebhookService{
+		store:  store,
+		client: &http.Client{Timeout: 10 * time.Second},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// GetWebhooks возвращает все подписки без их ключей.
+func (ws *WebhookService) GetWebhooks() ([]entities.Webhook, error) {
+	webhooks, err := ws.store.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// AddWebhook добавляет подписку и возвращает ее вместе с ключом подписи.
+// Если ключ не указан, то он генерируется.
+func (ws *WebhookService) AddWebhook(webhook entities.Webhook) (entities.Webhook, error) {
+	target, err := url.Parse(webhook.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return entities.Webhook{}, fmt.Errorf("%w: the url must be an absolute http or https url", entities.ErrInvalidWebhook)
+	}
+
+	for _, event := range webhook.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return entities.Webhook{}, fmt.Errorf("%w: unknown event %q", entities.ErrInvalidWebhook, event)
+		}
+	}
+
+	if webhook.Events == nil {
+		webhook.Events = entities.EventTypes{}
+	}
+
+	if webhook.Secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return entities.Webhook{}, err
+		}
+
+		webhook.Secret = hex.EncodeToString(key)
+	}
+
+	webhook.CreatedAt = time.Now().Unix()
+
+	webhook.Id, err = ws.store.AddWebhook(webhook)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет подписку с id, полученным из параметра запроса, и журнал ее доставок.
+func (ws *WebhookService) DeleteWebhook(id string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return errors.New("the id is not specified or is specified not correctly")
+	}
+
+	err := ws.store.DeleteWebhook(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ErrWebhookNotFound
+	}
+
+	return err
+}
+
+// GetDeliveries возвращает последние записи журнала доставок подписки с id webhookId.
+func (ws *WebhookService) GetDeliveries(webhookId string) ([]entities.Delivery, error) {
+	if _, err := strconv.Atoi(webhookId); err != nil {
+		return nil, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	return ws.store.GetDeliveries(webhookId, webhookLogLimit)
+}
+
+// Redeliver повторно отправляет событие из записи журнала доставок с id deliveryId.
+// Для повторной отправки создается новая запись журнала, которая и возвращается.
+func (ws *WebhookService) Redeliver(deliveryId string) (entities.Delivery, error) {
+	if _, err := strconv.Atoi(deliveryId); err != nil {
+		return entities.Delivery{}, errors.New("the id is not specified or is specified not correctly")
+	}
+
+	original, err := ws.store.GetDelivery(deliveryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Delivery{}, entities.ErrWebhookNotFound
+	}
+
+	if err != nil {
+		return entities.Delivery{}, err
+	}
+
+	delivery, err := ws.enqueue(original.WebhookId, original.Event, original.Payload, time.Now())
+	if err != nil {
+		return entities.Delivery{}, err
+	}
+
+	ws.signal()
+
+	return delivery, nil
+}
+
+func (ws *WebhookService) Name() string {
+	return "webhook"
+}
+
+// Notify сохраняет в журнал доставку события event каждой подписке на него.
+func (ws *WebhookService) Notify(_ context.Context, event entities.Event) error {
+	webhooks, err := ws.store.GetWebhooks()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	queued := false
+
+	for _, webhook := range webhooks {
+		if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event.Type) {
+			continue
+		}
+
+		if _, err := ws.enqueue(webhook.Id, event.Type, payload, now); err != nil {
+			return err
+		}
+
+		queued = true
+	}
+
+	if queued {
+		ws.signal()
+	}
+
+	return nil
+}
+
+// enqueue добавляет в журнал ожидающую доставку события eventType подписке webhookId.
+func (ws *WebhookService) enqueue(webhookId, eventType string, payload entities.Payload, now time.Time) (entities.Delivery, error) {
+	delivery := entities.Delivery{
+		WebhookId:     webhookId,
+		Event:         eventType,
+		Payload:       payload,
+		Status:        entities.DeliveryPending,
+		NextAttemptAt: now.Unix(),
+		CreatedAt:     now.Unix(),
+	}
+
+	id, err := ws.store.AddDelivery(delivery)
+	delivery.Id = id
+
+	return delivery, err
+}
+
+// signal будит Run, не блокируя вызывающего, если Run уже разбужен.
+func (ws *WebhookService) signal() {
+	select {
+	case ws.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Deliver выполняет попытку доставки каждого ожидающего события, время следующей
+// попытки которого наступило к моменту now, и возвращает количество доставленных событий.
+// После неудачной попытки следующая откладывается с экспоненциально растущей задержкой,
+// а после webhookMaxAttempts попыток доставка считается неудавшейся.
+func (ws *WebhookService) Deliver(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := ws.store.GetPendingDeliveries(now.Unix(), webhookBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[string]entities.Webhook)
+	count := 0
+
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookId]
+		if !ok {
+			webhook, err = ws.store.GetWebhook(delivery.WebhookId)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return count, err
+			}
+
+			webhooks[delivery.WebhookId] = webhook
+		}
+
+		delivery.Attempts++
+
+		if webhook.Id == "" {
+			delivery.Status = entities.DeliveryFailed
+			delivery.LastError = "the webhook has been deleted"
+		} else {
+			delivery.ResponseCode, err = ws.post(ctx, webhook, delivery)
+			delivery.LastError = ""
+
+			switch {
+			case err == nil:
+				deliveredAt := time.Now().Unix()
+				delivery.Status = entities.DeliveryDelivered
+				delivery.DeliveredAt = &deliveredAt
+				count++
+			case delivery.Attempts >= webhookMaxAttempts:
+				delivery.Status = entities.DeliveryFailed
+				delivery.LastError = err.Error()
+			default:
+				delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts)).Unix()
+				delivery.LastError = err.Error()
+			}
+		}
+
+		if err := ws.store.UpdateDelivery(delivery); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// post отправляет событие из записи журнала delivery на адрес подписки webhook
+// и возвращает код ответа. Тело запроса подписывается HMAC-SHA256 с ключом подписки,
+// а подпись передается в заголовке X-Webhook-Signature в виде "sha256=<hex>".
+func (ws *WebhookService) post(ctx context.Context, webhook entities.Webhook, delivery entities.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "task_scheduler-webhook")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.Id)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignPayload(webhook.Secret, delivery.Payload))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignPayload возвращает подпись HMAC-SHA256 тела payload с ключом secret в шестнадцатеричном виде.
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay возвращает задержку перед следующей попыткой доставки после attempts неудачных попыток.
+func retryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, webhookRetryMax)
+}
+
+// Run доставляет события подписчикам до отмены контекста ctx. Новые события
+// доставляются сразу, а повторные попытки проверяются с периодичностью interval.
+func (ws *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ws.Deliver(context.WithoutCancel(ctx), time.Now()); err != nil {
+			log.Printf("failed to deliver webhooks: %s\n", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-ws.wake:
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"task_scheduler/internal/config"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// backupFormat и backupVersion идентифицируют формат архива резервной копии.
+	backupFormat  = "task_scheduler-backup"
+	backupVersion = 1
+
+	manifestName = "manifest.json"
+	snapshotName = "scheduler.db"
+	dumpName     = "dump.json"
+)
+
+// backupTables перечисляет таблицы, которые сохраняются в резервную копию.
+var backupTables = []string{"scheduler", "completions", "history", "feed_tokens", "ical_uids", "caldav_resources", "fired_events", "reminders", "webhooks", "webhook_deliveries", "jobs", "job_runs", "instance"}
+
+// manifest является описанием содержимого архива резервной копии.
+type manifest struct {
+	Format    string           `json:"format"`
+	Version   int              `json:"version"`
+	Mode      string           `json:"mode"`
+	CreatedAt time.Time        `json:"created_at"`
+	Tables    map[string]int64 `json:"tables"`
+}
+
+// tableDump является логическим дампом одной таблицы.
+type tableDump struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// Backup записывает в w архив с согласованным снимком БД.
+// В режиме "sqlite" снимок создается командой VACUUM INTO, а в режиме "postgres"
+// выполняется логический дамп таблиц в одной транзакции только для чтения.
+func (s *Storage) Backup(w io.Writer) error {
+	m := manifest{
+		Format:    backupFormat,
+		Version:   backupVersion,
+		Mode:      config.Mode,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var err error
+	if config.Mode == "postgres" {
+		err = s.backupDump(tw, &m)
+	} else {
+		err = s.backupSnapshot(tw, &m)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeTarFile(tw, manifestName, data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// backupSnapshot добавляет в архив копию файла БД в режиме "sqlite".
+func (s *Storage) backupSnapshot(tw *tar.Writer, m *manifest) error {
+	dir, err := os.MkdirTemp("", "scheduler-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, snapshotName)
+	if _, err := s.db.Exec("VACUUM INTO ?", snapshot); err != nil {
+		return fmt.Errorf("failed to create database snapshot: %w", err)
+	}
+
+	snapshotDb, err := sqlx.Open("sqlite", snapshot)
+	if err != nil {
+		return err
+	}
+	defer snapshotDb.Close()
+
+	if m.Tables, err = countTables(snapshotDb); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return writeTarFile(tw, snapshotName, data)
+}
+
+// backupDump добавляет в архив логический дамп таблиц в режиме "postgres".
+func (s *Storage) backupDump(tw *tar.Writer, m *manifest) error {
+	tx, err := s.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dump, err := dumpTables(tx)
+	if err != nil {
+		return err
+	}
+
+	m.Tables = make(map[string]int64, len(dump))
+	for _, table := range dump {
+		m.Tables[table.Name] = int64(len(table.Rows))
+	}
+
+	data, err := json.Marshal(dump)
+	if err != nil {
+		return err
+	}
+
+	return writeTarFile(tw, dumpName, data)
+}
+
+// Restore заменяет содержимое таблиц БД данными из архива резервной копии, созданного Backup.
+// Архив может быть создан в любом из режимов: данные загружаются в одной транзакции,
+// после чего количество строк сверяется с описанием архива.
+func (s *Storage) Restore(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	var (
+		m     *manifest
+		dump  []tableDump
+		tr    = tar.NewReader(gz)
+		found bool
+	)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read backup archive: %w", err)
+		}
+
+		switch header.Name {
+		case manifestName:
+			m = &manifest{}
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return fmt.Errorf("failed to read backup manifest: %w", err)
+			}
+		case dumpName:
+			decoder := json.NewDecoder(tr)
+			decoder.UseNumber()
+			if err := decoder.Decode(&dump); err != nil {
+				return fmt.Errorf("failed to read database dump: %w", err)
+			}
+			found = true
+		case snapshotName:
+			if dump, err = readSnapshot(tr); err != nil {
+				return err
+			}
+			found = true
+		}
+	}
+
+	if m == nil || m.Format != backupFormat {
+		return errors.New("the file is not a task scheduler backup")
+	}
+
+	if m.Version > backupVersion {
+		return fmt.Errorf("unsupported backup version %d", m.Version)
+	}
+
+	if !found {
+		return errors.New("the backup does not contain any data")
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := loadTables(tx, dump); err != nil {
+		return err
+	}
+
+	counts, err := countTables(tx)
+	if err != nil {
+		return err
+	}
+
+	for table, expected := range m.Tables {
+		if counts[table] != expected {
+			return fmt.Errorf("table %q has %d rows after restore, expected %d", table, counts[table], expected)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// readSnapshot читает снимок БД режима "sqlite" из архива и возвращает его логический дамп.
+func readSnapshot(r io.Reader) ([]tableDump, error) {
+	dir, err := os.MkdirTemp("", "scheduler-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, snapshotName)
+
+	file, err := os.Create(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	db, err := sqlx.Open("sqlite", snapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return dumpTables(db)
+}
+
+// dumpTables возвращает логический дамп таблиц backupTables.
+// Отсутствующие в БД таблицы пропускаются.
+func dumpTables(q sqlx.Queryer) ([]tableDump, error) {
+	dump := []tableDump{}
+
+	for _, name := range backupTables {
+		rows, err := q.Queryx(fmt.Sprintf("SELECT * FROM %s ORDER BY id", name))
+		if err != nil {
+			if tableMissing(q, name) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to dump table %q: %w", name, err)
+		}
+
+		table := tableDump{Name: name, Rows: [][]any{}}
+		if table.Columns, err = rows.Columns(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		for rows.Next() {
+			row, err := rows.SliceScan()
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			for i, value := range row {
+				if data, ok := value.([]byte); ok {
+					row[i] = string(data)
+				}
+			}
+
+			table.Rows = append(table.Rows, row)
+		}
+
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		dump = append(dump, table)
+	}
+
+	return dump, nil
+}
+
+// loadTables заменяет содержимое таблиц данными из дампа и обновляет
+// последовательности идентификаторов в режиме "postgres".
+func loadTables(tx *sqlx.Tx, dump []tableDump) error {
+	for _, table := range dump {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table.Name)); err != nil {
+			return fmt.Errorf("failed to clear table %q: %w", table.Name, err)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(table.Columns)), ", ")
+		query := tx.Rebind(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			table.Name, strings.Join(table.Columns, ", "), placeholders))
+
+		for _, row := range table.Rows {
+			for i, value := range row {
+				row[i] = normalizeValue(value)
+			}
+
+			if _, err := tx.Exec(query, row...); err != nil {
+				return fmt.Errorf("failed to restore table %q: %w", table.Name, err)
+			}
+		}
+
+		if err := resetSequence(tx, table.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countTables возвращает количество строк в каждой из таблиц backupTables.
+func countTables(q sqlx.Queryer) (map[string]int64, error) {
+	counts := make(map[string]int64, len(backupTables))
+
+	for _, name := range backupTables {
+		var count int64
+		if err := sqlx.Get(q, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", name)); err != nil {
+			if tableMissing(q, name) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		counts[name] = count
+	}
+
+	return counts, nil
+}
+
+// tableMissing проверяет отсутствие таблицы name в БД.
+func tableMissing(q sqlx.Queryer, name string) bool {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`
+	if db, ok := q.(interface{ DriverName() string }); ok && db.DriverName() == "pgx" {
+		query = `SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = $1)`
+	}
+
+	if err := sqlx.Get(q, &exists, query, name); err != nil {
+		return false
+	}
+
+	return !exists
+}
+
+// normalizeValue приводит значения, прочитанные из JSON, к типам, поддерживаемым драйверами БД.
+func normalizeValue(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+
+	return number.String()
+}
+
+// writeTarFile добавляет в архив файл name с содержимым data.
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := tw.Write(data)
+
+	return err
+}
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -14,6 +17,9 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// taskColumns перечисляет столбцы таблицы scheduler, соответствующие полям entities.Task.
+const taskColumns = "id, date, title, comment, repeat, version"
+
 type Storage struct {
 	db *sqlx.DB
 }
@@ -52,18 +58,170 @@ func NewSqliteStore(fileName string) (*sqlx.DB, error) {
         date INTEGER NOT NULL DEFAULT 0,
         title TEXT NOT NULL DEFAULT '',
         comment TEXT NOT NULL DEFAULT '',
-        repeat VARCHAR(128) NOT NULL DEFAULT ''
+        repeat VARCHAR(128) NOT NULL DEFAULT '',
+        version INTEGER NOT NULL DEFAULT 1,
+        deleted_at BIGINT
     );
 
 	CREATE INDEX IF NOT EXISTS scheduler_date ON scheduler (date);
+
+	CREATE TABLE IF NOT EXISTS completions (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL,
+        title TEXT NOT NULL DEFAULT '',
+        date INTEGER NOT NULL DEFAULT 0,
+        completed_at BIGINT NOT NULL
+    );
+
+	CREATE INDEX IF NOT EXISTS completions_completed_at ON completions (completed_at);
+
+	CREATE TABLE IF NOT EXISTS history (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL,
+        action VARCHAR(16) NOT NULL,
+        actor TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL,
+        before_state TEXT,
+        after_state TEXT
+    );
+
+	CREATE INDEX IF NOT EXISTS history_task_id ON history (task_id);
+
+	CREATE TABLE IF NOT EXISTS instance (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        uid VARCHAR(64) NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS feed_tokens (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        token_hash VARCHAR(64) NOT NULL UNIQUE,
+        created_at BIGINT NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS ical_uids (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        uid TEXT NOT NULL UNIQUE,
+        task_id INTEGER NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS caldav_resources (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        task_id INTEGER NOT NULL,
+        uid TEXT NOT NULL DEFAULT ''
+    );
+
+	CREATE TABLE IF NOT EXISTS fired_events (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL,
+        date INTEGER NOT NULL,
+        event VARCHAR(32) NOT NULL,
+        fired_at BIGINT NOT NULL,
+        UNIQUE (task_id, date, event)
+    );
+
+	CREATE TABLE IF NOT EXISTS reminders (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL,
+        rule VARCHAR(64) NOT NULL,
+        offset_minutes INTEGER NOT NULL,
+        fire_at BIGINT NOT NULL,
+        fired_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS reminders_fire_at ON reminders (fire_at);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        url TEXT NOT NULL,
+        secret VARCHAR(128) NOT NULL,
+        events TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        webhook_id INTEGER NOT NULL,
+        event VARCHAR(32) NOT NULL,
+        payload TEXT NOT NULL,
+        status VARCHAR(16) NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at BIGINT NOT NULL,
+        response_code INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL,
+        delivered_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_pending ON webhook_deliveries (status, next_attempt_at);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL UNIQUE,
+        type VARCHAR(16) NOT NULL,
+        command TEXT NOT NULL DEFAULT '',
+        method VARCHAR(16) NOT NULL DEFAULT '',
+        url TEXT NOT NULL DEFAULT '',
+        headers TEXT NOT NULL DEFAULT '{}',
+        body TEXT NOT NULL DEFAULT '',
+        timeout_seconds INTEGER NOT NULL DEFAULT 0
+    );
+
+	CREATE TABLE IF NOT EXISTS job_runs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        task_id INTEGER NOT NULL,
+        date INTEGER NOT NULL,
+        type VARCHAR(16) NOT NULL,
+        status VARCHAR(16) NOT NULL,
+        exit_code INTEGER,
+        status_code INTEGER NOT NULL DEFAULT 0,
+        stdout TEXT NOT NULL DEFAULT '',
+        stderr TEXT NOT NULL DEFAULT '',
+        error TEXT NOT NULL DEFAULT '',
+        queued_at BIGINT NOT NULL,
+        started_at BIGINT,
+        finished_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS job_runs_task_id ON job_runs (task_id);
 		`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create table: %w", err)
 	}
 
+	// Добавление столбцов, появившихся после создания таблицы в старых версиях БД.
+	if err = addSqliteColumn(db, "scheduler", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return nil, err
+	}
+
+	if err = addSqliteColumn(db, "scheduler", "deleted_at", "BIGINT"); err != nil {
+		return nil, err
+	}
+
 	return db, err
 }
 
+// addSqliteColumn добавляет столбец в таблицу БД в режиме "sqlite", если его еще нет.
+func addSqliteColumn(db *sqlx.DB, table, column, definition string) error {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM pragma_table_info(?) WHERE name = ?)`
+	if err := db.Get(&exists, query, table, column); err != nil {
+		return fmt.Errorf("failed to get table info: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add column %q: %w", column, err)
+	}
+
+	return nil
+}
+
 // NewPostgresStore создает таблицу "scheduler" в БД для хранения посылок в режиме "postgres".
 func NewPostgresStore(psqlUrl string) (*sqlx.DB, error) {
 	db, err := sqlx.Open("pgx", psqlUrl)
@@ -77,10 +235,135 @@ func NewPostgresStore(psqlUrl string) (*sqlx.DB, error) {
         date INTEGER NOT NULL DEFAULT 0,
         title TEXT NOT NULL DEFAULT '',
         comment TEXT NOT NULL DEFAULT '',
-        repeat VARCHAR(128) NOT NULL DEFAULT ''
+        repeat VARCHAR(128) NOT NULL DEFAULT '',
+        version INTEGER NOT NULL DEFAULT 1,
+        deleted_at BIGINT
     );
 
+	ALTER TABLE scheduler ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE scheduler ADD COLUMN IF NOT EXISTS deleted_at BIGINT;
+
 	CREATE INDEX IF NOT EXISTS scheduler_date ON scheduler (date);
+
+	CREATE TABLE IF NOT EXISTS completions (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL,
+        title TEXT NOT NULL DEFAULT '',
+        date INTEGER NOT NULL DEFAULT 0,
+        completed_at BIGINT NOT NULL
+    );
+
+	CREATE INDEX IF NOT EXISTS completions_completed_at ON completions (completed_at);
+
+	CREATE TABLE IF NOT EXISTS history (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL,
+        action VARCHAR(16) NOT NULL,
+        actor TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL,
+        before_state TEXT,
+        after_state TEXT
+    );
+
+	CREATE INDEX IF NOT EXISTS history_task_id ON history (task_id);
+
+	CREATE TABLE IF NOT EXISTS instance (
+        id SERIAL PRIMARY KEY,
+        uid VARCHAR(64) NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS feed_tokens (
+        id SERIAL PRIMARY KEY,
+        token_hash VARCHAR(64) NOT NULL UNIQUE,
+        created_at BIGINT NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS ical_uids (
+        id SERIAL PRIMARY KEY,
+        uid TEXT NOT NULL UNIQUE,
+        task_id INTEGER NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS caldav_resources (
+        id SERIAL PRIMARY KEY,
+        name TEXT NOT NULL UNIQUE,
+        task_id INTEGER NOT NULL,
+        uid TEXT NOT NULL DEFAULT ''
+    );
+
+	CREATE TABLE IF NOT EXISTS fired_events (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL,
+        date INTEGER NOT NULL,
+        event VARCHAR(32) NOT NULL,
+        fired_at BIGINT NOT NULL,
+        UNIQUE (task_id, date, event)
+    );
+
+	CREATE TABLE IF NOT EXISTS reminders (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL,
+        rule VARCHAR(64) NOT NULL,
+        offset_minutes INTEGER NOT NULL,
+        fire_at BIGINT NOT NULL,
+        fired_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS reminders_fire_at ON reminders (fire_at);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+        id SERIAL PRIMARY KEY,
+        url TEXT NOT NULL,
+        secret VARCHAR(128) NOT NULL,
+        events TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL
+    );
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        id SERIAL PRIMARY KEY,
+        webhook_id INTEGER NOT NULL,
+        event VARCHAR(32) NOT NULL,
+        payload TEXT NOT NULL,
+        status VARCHAR(16) NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at BIGINT NOT NULL,
+        response_code INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT NOT NULL DEFAULT '',
+        created_at BIGINT NOT NULL,
+        delivered_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_pending ON webhook_deliveries (status, next_attempt_at);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL UNIQUE,
+        type VARCHAR(16) NOT NULL,
+        command TEXT NOT NULL DEFAULT '',
+        method VARCHAR(16) NOT NULL DEFAULT '',
+        url TEXT NOT NULL DEFAULT '',
+        headers TEXT NOT NULL DEFAULT '{}',
+        body TEXT NOT NULL DEFAULT '',
+        timeout_seconds INTEGER NOT NULL DEFAULT 0
+    );
+
+	CREATE TABLE IF NOT EXISTS job_runs (
+        id SERIAL PRIMARY KEY,
+        task_id INTEGER NOT NULL,
+        date INTEGER NOT NULL,
+        type VARCHAR(16) NOT NULL,
+        status VARCHAR(16) NOT NULL,
+        exit_code INTEGER,
+        status_code INTEGER NOT NULL DEFAULT 0,
+        stdout TEXT NOT NULL DEFAULT '',
+        stderr TEXT NOT NULL DEFAULT '',
+        error TEXT NOT NULL DEFAULT '',
+        queued_at BIGINT NOT NULL,
+        started_at BIGINT,
+        finished_at BIGINT
+    );
+
+	CREATE INDEX IF NOT EXISTS job_runs_task_id ON job_runs (task_id);
 		`)
 
 	return db, err
@@ -121,7 +404,7 @@ func (s *Storage) PostTask(task entities.Task) (string, error) {
 // GetTasks получат все существующие задач из таблицы scheduler.
 func (s *Storage) GetTasks() ([]entities.Task, error) {
 	tasks := []entities.Task{}
-	query := `SELECT * FROM scheduler ORDER BY date`
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE deleted_at IS NULL ORDER BY date`
 
 	err := s.db.Select(&tasks, query)
 
@@ -140,9 +423,9 @@ func (s *Storage) SearchTasks(target string) ([]entities.Task, error) {
 		dateInFormat := date.Format("20060102")
 
 		if config.Mode == "postgres" {
-			query = `SELECT * FROM scheduler WHERE date = $1`
+			query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date = $1 AND deleted_at IS NULL`
 		} else {
-			query = `SELECT * FROM scheduler WHERE date = ?`
+			query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date = ? AND deleted_at IS NULL`
 		}
 
 		err := s.db.Select(&tasks, query, dateInFormat)
@@ -153,9 +436,11 @@ func (s *Storage) SearchTasks(target string) ([]entities.Task, error) {
 	target = fmt.Sprint("%" + target + "%")
 
 	if config.Mode == "postgres" {
-		query = `SELECT * FROM scheduler WHERE title ILIKE $1 OR comment ILIKE $1 ORDER BY date`
+		query = `SELECT ` + taskColumns + ` FROM scheduler
+		         WHERE (title ILIKE $1 OR comment ILIKE $1) AND deleted_at IS NULL ORDER BY date`
 	} else {
-		query = `SELECT * FROM scheduler WHERE LOWER(title) LIKE LOWER(?) OR LOWER(comment) LIKE LOWER(?) ORDER BY date`
+		query = `SELECT ` + taskColumns + ` FROM scheduler
+		         WHERE (LOWER(title) LIKE LOWER(?) OR LOWER(comment) LIKE LOWER(?)) AND deleted_at IS NULL ORDER BY date`
 	}
 
 	err := s.db.Select(&tasks, query, target, target)
@@ -163,6 +448,43 @@ func (s *Storage) SearchTasks(target string) ([]entities.Task, error) {
 	return tasks, err
 }
 
+// GetTasksByPeriod возвращает задачи из таблицы scheduler, дата которых
+// попадает в промежуток [from, to] включительно.
+func (s *Storage) GetTasksByPeriod(from, to string) ([]entities.Task, error) {
+	var (
+		tasks = []entities.Task{}
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date BETWEEN $1 AND $2 AND deleted_at IS NULL ORDER BY date`
+	} else {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date BETWEEN ? AND ? AND deleted_at IS NULL ORDER BY date`
+	}
+
+	err := s.db.Select(&tasks, query, from, to)
+
+	return tasks, err
+}
+
+// GetTasksBefore возвращает задачи из таблицы scheduler с датой строго раньше date.
+func (s *Storage) GetTasksBefore(date string) ([]entities.Task, error) {
+	var (
+		tasks = []entities.Task{}
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date < $1 AND deleted_at IS NULL ORDER BY date`
+	} else {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date < ? AND deleted_at IS NULL ORDER BY date`
+	}
+
+	err := s.db.Select(&tasks, query, date)
+
+	return tasks, err
+}
+
 // SearchTask получает задачу по id из таблицы scheduler.
 func (s *Storage) SearchTask(id string) (entities.Task, error) {
 	var (
@@ -171,9 +493,9 @@ func (s *Storage) SearchTask(id string) (entities.Task, error) {
 	)
 
 	if config.Mode == "postgres" {
-		query = `SELECT * FROM scheduler WHERE id = $1`
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE id = $1 AND deleted_at IS NULL`
 	} else {
-		query = `SELECT * FROM scheduler WHERE id = ?`
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE id = ? AND deleted_at IS NULL`
 	}
 
 	err := s.db.Get(&task, query, id)
@@ -182,53 +504,994 @@ func (s *Storage) SearchTask(id string) (entities.Task, error) {
 }
 
 // Метод UpdateTask обновляет задачу по переданным параметрам в таблице scheduler.
+// Если у задачи указана версия, то обновление выполняется только при совпадении
+// версии в БД, в противном случае возвращается entities.ErrVersionMismatch.
+// При каждом обновлении версия задачи увеличивается на единицу.
 func (s *Storage) UpdateTask(task entities.Task) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE scheduler SET date = $1, title = $2, comment = $3, repeat = $4, version = version + 1
+		         WHERE id = $5 AND ($6 = 0 OR version = $6) AND deleted_at IS NULL`
+	} else {
+		query = `UPDATE scheduler SET date = ?1, title = ?2, comment = ?3, repeat = ?4, version = version + 1
+		         WHERE id = ?5 AND (?6 = 0 OR version = ?6) AND deleted_at IS NULL`
+	}
+
+	res, err := s.db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.Id, task.Version)
+	if err != nil {
+		return err
+	}
+
+	return s.checkAffected(res, task.Id)
+}
+
+// DeleteTask перемещает задачу по id в корзину, помечая ее временем удаления.
+// Если указана версия, отличная от нуля, то удаление выполняется только
+// при совпадении версии в БД.
+func (s *Storage) DeleteTask(id string, version int) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE scheduler SET deleted_at = $1, version = version + 1
+		         WHERE id = $2 AND ($3 = 0 OR version = $3) AND deleted_at IS NULL`
+	} else {
+		query = `UPDATE scheduler SET deleted_at = ?1, version = version + 1
+		         WHERE id = ?2 AND (?3 = 0 OR version = ?3) AND deleted_at IS NULL`
+	}
+
+	res, err := s.db.Exec(query, time.Now().Unix(), id, version)
+	if err != nil {
+		return err
+	}
+
+	return s.checkAffected(res, id)
+}
+
+// GetTrash возвращает все задачи из корзины, начиная с удаленных последними.
+func (s *Storage) GetTrash() ([]entities.TrashedTask, error) {
+	tasks := []entities.TrashedTask{}
+	query := `SELECT ` + taskColumns + `, deleted_at FROM scheduler WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`
+
+	err := s.db.Select(&tasks, query)
+
+	return tasks, err
+}
+
+// RestoreTask возвращает задачу по id из корзины.
+func (s *Storage) RestoreTask(id string) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE scheduler SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+	} else {
+		query = `UPDATE scheduler SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL`
+	}
+
+	res, err := s.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("there is no task with the specified id in the trash")
+	}
+
+	return nil
+}
+
+// PurgeTask окончательно удаляет задачу по id из корзины вместе с ее строками
+// в таблицах taskTables.
+func (s *Storage) PurgeTask(id string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(tx.Rebind(`DELETE FROM scheduler WHERE id = ? AND deleted_at IS NOT NULL`), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("there is no task with the specified id in the trash")
+	}
+
+	if err := deleteTaskRows(tx, `= ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeTrash окончательно удаляет из корзины задачи, удаленные раньше момента before,
+// вместе с их строками в таблицах taskTables и возвращает количество удаленных задач.
+func (s *Storage) PurgeTrash(before time.Time) (int64, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := deleteTaskRows(tx, `IN (SELECT id FROM scheduler WHERE deleted_at < ?)`, before.Unix()); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(tx.Rebind(`DELETE FROM scheduler WHERE deleted_at < ?`), before.Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, tx.Commit()
+}
+
+// taskTables перечисляет таблицы, строки которых принадлежат задачам по столбцу task_id
+// и теряют смысл после окончательного удаления задачи. Журнал выполненных задач
+// (completions) и история изменений (history) сохраняются.
+var taskTables = []string{
+	"ical_uids",
+	"caldav_resources",
+	"fired_events",
+	"reminders",
+	"jobs",
+	"job_runs",
+}
+
+// deleteTaskRows удаляет в транзакции tx строки таблиц taskTables, task_id которых
+// удовлетворяет условию condition с аргументами args.
+func deleteTaskRows(tx *sqlx.Tx, condition string, args ...any) error {
+	for _, table := range taskTables {
+		query := tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE task_id %s", table, condition))
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to delete rows of %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// checkAffected проверяет, что условный запрос изменил задачу с указанным id.
+// Если ни одна строка не была изменена, то определяет причину: отсутствие задачи
+// или несовпадение ее версии.
+func (s *Storage) checkAffected(res sql.Result, id string) error {
 	var (
-		exists            bool
-		queryCheck, query string
+		exists     bool
+		queryCheck string
 	)
 
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 0 {
+		return nil
+	}
+
 	if config.Mode == "postgres" {
-		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = $1)`
+		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = $1 AND deleted_at IS NULL)`
+	} else {
+		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = ? AND deleted_at IS NULL)`
+	}
+
+	if s.db.Get(&exists, queryCheck, id); !exists {
+		return errors.New("there is no task with the specified id")
+	}
+
+	return entities.ErrVersionMismatch
+}
+
+// AddCompletion добавляет запись о выполнении задачи в таблицу completions.
+func (s *Storage) AddCompletion(completion entities.Completion) error {
+	var query string
 
-		query = `UPDATE scheduler SET date = $1, title = $2, comment = $3, repeat = $4 WHERE id = $5`
+	if config.Mode == "postgres" {
+		query = `INSERT INTO completions (task_id, title, date, completed_at) VALUES ($1, $2, $3, $4)`
 	} else {
-		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = ?)`
+		query = `INSERT INTO completions (task_id, title, date, completed_at) VALUES (?, ?, ?, ?)`
+	}
 
-		query = `UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ? WHERE id = ?`
+	_, err := s.db.Exec(query, completion.TaskId, completion.Title, completion.Date, completion.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert completion: %w", err)
 	}
 
-	if s.db.Get(&exists, queryCheck, task.Id); !exists {
-		return errors.New("there is no task with the specified id")
+	return nil
+}
+
+// DeleteCompletion удаляет последнюю запись о выполнении задачи taskId на дату date.
+func (s *Storage) DeleteCompletion(taskId, date string) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `DELETE FROM completions WHERE id = (SELECT MAX(id) FROM completions WHERE task_id = $1 AND date = $2)`
+	} else {
+		query = `DELETE FROM completions WHERE id = (SELECT MAX(id) FROM completions WHERE task_id = ? AND date = ?)`
 	}
 
-	_, err := s.db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.Id)
+	if _, err := s.db.Exec(query, taskId, date); err != nil {
+		return fmt.Errorf("failed to delete completion: %w", err)
+	}
 
-	return err
+	return nil
 }
 
-// DeleteTask удаляет задачу по id из таблицы scheduler.
-func (s *Storage) DeleteTask(id string) error {
+// GetCompletions возвращает записи о выполнении задач из таблицы completions,
+// выполненных в промежутке [from, to) и содержащих строку или подстроку target в названии,
+// начиная с выполненных последними.
+func (s *Storage) GetCompletions(target string, from, to int64) ([]entities.Completion, error) {
 	var (
-		exists            bool
-		queryCheck, query string
+		completions = []entities.Completion{}
+		query       string
 	)
 
+	target = fmt.Sprint("%" + target + "%")
+
 	if config.Mode == "postgres" {
-		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = $1)`
+		query = `SELECT id, task_id, title, date, completed_at FROM completions
+		         WHERE completed_at >= $1 AND completed_at < $2 AND title ILIKE $3
+		         ORDER BY completed_at DESC, id DESC`
+	} else {
+		query = `SELECT id, task_id, title, date, completed_at FROM completions
+		         WHERE completed_at >= ? AND completed_at < ? AND LOWER(title) LIKE LOWER(?)
+		         ORDER BY completed_at DESC, id DESC`
+	}
+
+	err := s.db.Select(&completions, query, from, to, target)
 
-		query = `DELETE FROM scheduler WHERE id = $1`
+	return completions, err
+}
+
+// AddRevision добавляет запись об изменении задачи в таблицу history.
+func (s *Storage) AddRevision(revision entities.Revision) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO history (task_id, action, actor, created_at, before_state, after_state)
+		         VALUES ($1, $2, $3, $4, $5, $6)`
 	} else {
-		queryCheck = `SELECT EXISTS (SELECT 1 FROM scheduler WHERE id = ?)`
+		query = `INSERT INTO history (task_id, action, actor, created_at, before_state, after_state)
+		         VALUES (?, ?, ?, ?, ?, ?)`
+	}
 
-		query = `DELETE FROM scheduler WHERE id = ?`
+	_, err := s.db.Exec(query, revision.TaskId, revision.Action, revision.Actor, revision.CreatedAt,
+		revision.Before, revision.After)
+	if err != nil {
+		return fmt.Errorf("failed to insert revision: %w", err)
 	}
 
-	if s.db.Get(&exists, queryCheck, id); !exists {
-		return errors.New("there is no task with the specified id")
+	return nil
+}
+
+// GetRevisions возвращает историю изменений задачи с указанным id из таблицы history
+// в порядке времени изменений.
+func (s *Storage) GetRevisions(taskId string) ([]entities.Revision, error) {
+	var (
+		revisions = []entities.Revision{}
+		query     string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT id, task_id, action, actor, created_at, before_state, after_state FROM history WHERE task_id = $1 ORDER BY created_at, id`
+	} else {
+		query = `SELECT id, task_id, action, actor, created_at, before_state, after_state FROM history WHERE task_id = ? ORDER BY created_at, id`
 	}
 
-	_, err := s.db.Exec(query, id)
+	err := s.db.Select(&revisions, query, taskId)
 
-	return err
+	return revisions, err
+}
+
+// GetAllRevisions возвращает всю историю изменений задач из таблицы history в порядке их внесения.
+func (s *Storage) GetAllRevisions() ([]entities.Revision, error) {
+	revisions := []entities.Revision{}
+	query := `SELECT id, task_id, action, actor, created_at, before_state, after_state FROM history ORDER BY id`
+
+	err := s.db.Select(&revisions, query)
+
+	return revisions, err
+}
+
+// GetRevision возвращает запись истории изменений по ее id из таблицы history.
+func (s *Storage) GetRevision(id string) (entities.Revision, error) {
+	var (
+		revision = entities.Revision{}
+		query    string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT id, task_id, action, actor, created_at, before_state, after_state FROM history WHERE id = $1`
+	} else {
+		query = `SELECT id, task_id, action, actor, created_at, before_state, after_state FROM history WHERE id = ?`
+	}
+
+	err := s.db.Get(&revision, query, id)
+
+	return revision, err
+}
+
+// ReplaceFeedToken заменяет все токены календарной подписки в таблице feed_tokens
+// токеном с хешем tokenHash, отзывая ранее выданные токены.
+func (s *Storage) ReplaceFeedToken(tokenHash string, createdAt int64) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO feed_tokens (token_hash, created_at) VALUES ($1, $2)`
+	} else {
+		query = `INSERT INTO feed_tokens (token_hash, created_at) VALUES (?, ?)`
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM feed_tokens`); err != nil {
+		return fmt.Errorf("failed to revoke feed tokens: %w", err)
+	}
+
+	if _, err := tx.Exec(query, tokenHash, createdAt); err != nil {
+		return fmt.Errorf("failed to insert feed token: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FeedTokenExists проверяет наличие токена календарной подписки с хешем tokenHash в таблице feed_tokens.
+func (s *Storage) FeedTokenExists(tokenHash string) (bool, error) {
+	var (
+		exists bool
+		query  string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT EXISTS (SELECT 1 FROM feed_tokens WHERE token_hash = $1)`
+	} else {
+		query = `SELECT EXISTS (SELECT 1 FROM feed_tokens WHERE token_hash = ?)`
+	}
+
+	err := s.db.Get(&exists, query, tokenHash)
+
+	return exists, err
+}
+
+// GetInstanceId возвращает идентификатор экземпляра БД из таблицы instance,
+// создавая его при первом обращении.
+func (s *Storage) GetInstanceId() (string, error) {
+	var uid string
+
+	err := s.db.Get(&uid, `SELECT uid FROM instance ORDER BY id LIMIT 1`)
+	if err == nil {
+		return uid, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate instance id: %w", err)
+	}
+
+	if _, err := s.db.Exec(s.db.Rebind(`INSERT INTO instance (uid) VALUES (?)`), hex.EncodeToString(buf)); err != nil {
+		return "", fmt.Errorf("failed to insert instance id: %w", err)
+	}
+
+	// Повторное чтение возвращает одну и ту же строку при одновременном создании.
+	err = s.db.Get(&uid, `SELECT uid FROM instance ORDER BY id LIMIT 1`)
+
+	return uid, err
+}
+
+// GetTaskIdByUID возвращает id задачи, импортированной из iCalendar с идентификатором uid,
+// из таблицы ical_uids.
+func (s *Storage) GetTaskIdByUID(uid string) (string, error) {
+	var (
+		id    int
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT task_id FROM ical_uids WHERE uid = $1`
+	} else {
+		query = `SELECT task_id FROM ical_uids WHERE uid = ?`
+	}
+
+	err := s.db.Get(&id, query, uid)
+
+	return fmt.Sprint(id), err
+}
+
+// SetTaskUID связывает идентификатор iCalendar uid с задачей taskId в таблице ical_uids.
+func (s *Storage) SetTaskUID(uid, taskId string) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO ical_uids (uid, task_id) VALUES ($1, $2)
+		         ON CONFLICT (uid) DO UPDATE SET task_id = excluded.task_id`
+	} else {
+		query = `INSERT INTO ical_uids (uid, task_id) VALUES (?, ?)
+		         ON CONFLICT (uid) DO UPDATE SET task_id = excluded.task_id`
+	}
+
+	if _, err := s.db.Exec(query, uid, taskId); err != nil {
+		return fmt.Errorf("failed to save iCalendar uid: %w", err)
+	}
+
+	return nil
+}
+
+// GetCalDAVResources возвращает все ресурсы CalDAV, созданные клиентами, из таблицы caldav_resources.
+func (s *Storage) GetCalDAVResources() ([]entities.CalDAVResource, error) {
+	resources := []entities.CalDAVResource{}
+	query := `SELECT id, name, task_id, uid FROM caldav_resources ORDER BY id`
+
+	err := s.db.Select(&resources, query)
+
+	return resources, err
+}
+
+// SetCalDAVResource добавляет ресурс CalDAV в таблицу caldav_resources или обновляет
+// ресурс с тем же именем.
+func (s *Storage) SetCalDAVResource(resource entities.CalDAVResource) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO caldav_resources (name, task_id, uid) VALUES ($1, $2, $3)
+		         ON CONFLICT (name) DO UPDATE SET task_id = excluded.task_id, uid = excluded.uid`
+	} else {
+		query = `INSERT INTO caldav_resources (name, task_id, uid) VALUES (?, ?, ?)
+		         ON CONFLICT (name) DO UPDATE SET task_id = excluded.task_id, uid = excluded.uid`
+	}
+
+	if _, err := s.db.Exec(query, resource.Name, resource.TaskId, resource.UID); err != nil {
+		return fmt.Errorf("failed to save CalDAV resource: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCalDAVResource удаляет ресурс CalDAV задачи taskId из таблицы caldav_resources.
+func (s *Storage) DeleteCalDAVResource(taskId string) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `DELETE FROM caldav_resources WHERE task_id = $1`
+	} else {
+		query = `DELETE FROM caldav_resources WHERE task_id = ?`
+	}
+
+	_, err := s.db.Exec(query, taskId)
+
+	return err
+}
+
+// GetDueTasks возвращает задачи с датой не позднее date, для которых событие event
+// на их текущую дату еще не отмечено в таблице fired_events.
+func (s *Storage) GetDueTasks(date, event string) ([]entities.Task, error) {
+	var (
+		tasks = []entities.Task{}
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date <= $1 AND deleted_at IS NULL
+		         AND NOT EXISTS (SELECT 1 FROM fired_events f
+		                         WHERE f.task_id = scheduler.id AND f.date = scheduler.date AND f.event = $2)
+		         ORDER BY date`
+	} else {
+		query = `SELECT ` + taskColumns + ` FROM scheduler WHERE date <= ? AND deleted_at IS NULL
+		         AND NOT EXISTS (SELECT 1 FROM fired_events f
+		                         WHERE f.task_id = scheduler.id AND f.date = scheduler.date AND f.event = ?)
+		         ORDER BY date`
+	}
+
+	err := s.db.Select(&tasks, query, date, event)
+
+	return tasks, err
+}
+
+// MarkFired отмечает в таблице fired_events событие event задачи taskId на дату date.
+// Возвращает false, если событие уже было отмечено ранее.
+func (s *Storage) MarkFired(taskId, date, event string, firedAt int64) (bool, error) {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO fired_events (task_id, date, event, fired_at) VALUES ($1, $2, $3, $4)
+		         ON CONFLICT (task_id, date, event) DO NOTHING`
+	} else {
+		query = `INSERT INTO fired_events (task_id, date, event, fired_at) VALUES (?, ?, ?, ?)
+		         ON CONFLICT (task_id, date, event) DO NOTHING`
+	}
+
+	res, err := s.db.Exec(query, taskId, date, event, firedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark event as fired: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected > 0, err
+}
+
+// reminderColumns перечисляет столбцы таблицы reminders, соответствующие полям entities.Reminder.
+const reminderColumns = "id, task_id, rule, offset_minutes, fire_at, fired_at"
+
+// GetReminders возвращает напоминания задачи taskId из таблицы reminders.
+func (s *Storage) GetReminders(taskId string) ([]entities.Reminder, error) {
+	var (
+		reminders = []entities.Reminder{}
+		query     string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = $1 ORDER BY fire_at, id`
+	} else {
+		query = `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = ? ORDER BY fire_at, id`
+	}
+
+	err := s.db.Select(&reminders, query, taskId)
+
+	return reminders, err
+}
+
+// GetAllReminders возвращает напоминания всех задач, не находящихся в корзине, из таблицы reminders.
+func (s *Storage) GetAllReminders() ([]entities.Reminder, error) {
+	reminders := []entities.Reminder{}
+
+	query := `SELECT ` + reminderColumns + ` FROM reminders
+	          WHERE task_id IN (SELECT id FROM scheduler WHERE deleted_at IS NULL)
+	          ORDER BY task_id, fire_at, id`
+
+	err := s.db.Select(&reminders, query)
+
+	return reminders, err
+}
+
+// ReplaceReminders заменяет все напоминания задачи taskId в таблице reminders напоминаниями reminders.
+func (s *Storage) ReplaceReminders(taskId string, reminders []entities.Reminder) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM reminders WHERE task_id = ?`), taskId); err != nil {
+		return fmt.Errorf("failed to delete reminders: %w", err)
+	}
+
+	query := tx.Rebind(`INSERT INTO reminders (task_id, rule, offset_minutes, fire_at, fired_at) VALUES (?, ?, ?, ?, ?)`)
+	for _, reminder := range reminders {
+		if _, err := tx.Exec(query, taskId, reminder.Rule, reminder.Offset, reminder.FireAt, reminder.FiredAt); err != nil {
+			return fmt.Errorf("failed to insert reminder: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPendingReminders возвращает неотправленные напоминания задач, не находящихся в корзине,
+// со временем срабатывания не позднее before, дополненные названием и датой задачи.
+func (s *Storage) GetPendingReminders(before int64) ([]entities.Reminder, error) {
+	var (
+		reminders = []entities.Reminder{}
+		query     string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT r.id, r.task_id, r.rule, r.offset_minutes, r.fire_at, r.fired_at, s.title, s.date
+		         FROM reminders r JOIN scheduler s ON s.id = r.task_id
+		         WHERE r.fired_at IS NULL AND s.deleted_at IS NULL AND r.fire_at <= $1
+		         ORDER BY r.fire_at, r.id`
+	} else {
+		query = `SELECT r.id, r.task_id, r.rule, r.offset_minutes, r.fire_at, r.fired_at, s.title, s.date
+		         FROM reminders r JOIN scheduler s ON s.id = r.task_id
+		         WHERE r.fired_at IS NULL AND s.deleted_at IS NULL AND r.fire_at <= ?
+		         ORDER BY r.fire_at, r.id`
+	}
+
+	err := s.db.Select(&reminders, query, before)
+
+	return reminders, err
+}
+
+// MarkReminderFired отмечает напоминание id отправленным в момент firedAt.
+// Возвращает false, если напоминание уже отправлено или больше не существует.
+func (s *Storage) MarkReminderFired(id string, firedAt int64) (bool, error) {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE reminders SET fired_at = $1 WHERE id = $2 AND fired_at IS NULL`
+	} else {
+		query = `UPDATE reminders SET fired_at = ? WHERE id = ? AND fired_at IS NULL`
+	}
+
+	res, err := s.db.Exec(query, firedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark reminder as fired: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected > 0, err
+}
+
+// webhookColumns перечисляет столбцы таблицы webhooks, соответствующие полям entities.Webhook.
+const webhookColumns = "id, url, secret, events, created_at"
+
+// deliveryColumns перечисляет столбцы таблицы webhook_deliveries, соответствующие полям entities.Delivery.
+const deliveryColumns = "id, webhook_id, event, payload, status, attempts, next_attempt_at, response_code, last_error, created_at, delivered_at"
+
+// GetWebhooks возвращает все подписки на события задач из таблицы webhooks.
+func (s *Storage) GetWebhooks() ([]entities.Webhook, error) {
+	webhooks := []entities.Webhook{}
+
+	err := s.db.Select(&webhooks, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+
+	return webhooks, err
+}
+
+// GetWebhook возвращает подписку с указанным id из таблицы webhooks.
+func (s *Storage) GetWebhook(id string) (entities.Webhook, error) {
+	var (
+		webhook entities.Webhook
+		query   string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	} else {
+		query = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+	}
+
+	err := s.db.Get(&webhook, query, id)
+
+	return webhook, err
+}
+
+// AddWebhook добавляет подписку в таблицу webhooks и возвращает ее id.
+func (s *Storage) AddWebhook(webhook entities.Webhook) (string, error) {
+	var id int
+
+	if config.Mode == "postgres" {
+		query := `INSERT INTO webhooks (url, secret, events, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+		if err := s.db.Get(&id, query, webhook.Url, webhook.Secret, webhook.Events, webhook.CreatedAt); err != nil {
+			return "", fmt.Errorf("failed to insert webhook: %w", err)
+		}
+	} else {
+		query := `INSERT INTO webhooks (url, secret, events, created_at) VALUES (?, ?, ?, ?)`
+		res, err := s.db.Exec(query, webhook.Url, webhook.Secret, webhook.Events, webhook.CreatedAt)
+		if err != nil {
+			return "", fmt.Errorf("failed to insert webhook: %w", err)
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return "", fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+
+		id = int(lastId)
+	}
+
+	return fmt.Sprint(id), nil
+}
+
+// DeleteWebhook удаляет подписку с указанным id и журнал ее доставок.
+// Возвращает sql.ErrNoRows, если подписки нет.
+func (s *Storage) DeleteWebhook(id string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(tx.Rebind(`DELETE FROM webhooks WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+
+		return err
+	}
+
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`), id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// AddDelivery добавляет запись в журнал доставок webhook_deliveries и возвращает ее id.
+func (s *Storage) AddDelivery(delivery entities.Delivery) (string, error) {
+	var id int
+
+	args := []any{delivery.WebhookId, delivery.Event, delivery.Payload, delivery.Status, delivery.Attempts,
+		delivery.NextAttemptAt, delivery.ResponseCode, delivery.LastError, delivery.CreatedAt, delivery.DeliveredAt}
+
+	if config.Mode == "postgres" {
+		query := `INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at,
+		          response_code, last_error, created_at, delivered_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+		if err := s.db.Get(&id, query, args...); err != nil {
+			return "", fmt.Errorf("failed to insert delivery: %w", err)
+		}
+	} else {
+		query := `INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at,
+		          response_code, last_error, created_at, delivered_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		res, err := s.db.Exec(query, args...)
+		if err != nil {
+			return "", fmt.Errorf("failed to insert delivery: %w", err)
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return "", fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+
+		id = int(lastId)
+	}
+
+	return fmt.Sprint(id), nil
+}
+
+// GetDelivery возвращает запись журнала доставок с указанным id.
+func (s *Storage) GetDelivery(id string) (entities.Delivery, error) {
+	var (
+		delivery entities.Delivery
+		query    string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	} else {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+	}
+
+	err := s.db.Get(&delivery, query, id)
+
+	return delivery, err
+}
+
+// GetDeliveries возвращает не более limit последних записей журнала доставок подписки webhookId.
+func (s *Storage) GetDeliveries(webhookId string, limit int) ([]entities.Delivery, error) {
+	var (
+		deliveries = []entities.Delivery{}
+		query      string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+	} else {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`
+	}
+
+	err := s.db.Select(&deliveries, query, webhookId, limit)
+
+	return deliveries, err
+}
+
+// GetPendingDeliveries возвращает не более limit ожидающих доставки записей журнала,
+// время следующей попытки которых не позднее before.
+func (s *Storage) GetPendingDeliveries(before int64, limit int) ([]entities.Delivery, error) {
+	var (
+		deliveries = []entities.Delivery{}
+		query      string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		         WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at, id LIMIT $3`
+	} else {
+		query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		         WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`
+	}
+
+	err := s.db.Select(&deliveries, query, entities.DeliveryPending, before, limit)
+
+	return deliveries, err
+}
+
+// UpdateDelivery сохраняет результат попытки доставки в журнал доставок.
+func (s *Storage) UpdateDelivery(delivery entities.Delivery) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3,
+		         response_code = $4, last_error = $5, delivered_at = $6 WHERE id = $7`
+	} else {
+		query = `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?,
+		         response_code = ?, last_error = ?, delivered_at = ? WHERE id = ?`
+	}
+
+	_, err := s.db.Exec(query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.ResponseCode, delivery.LastError, delivery.DeliveredAt, delivery.Id)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+
+	return nil
+}
+
+// jobColumns перечисляет столбцы таблицы jobs, соответствующие полям entities.Job.
+const jobColumns = "id, task_id, type, command, method, url, headers, body, timeout_seconds"
+
+// jobRunColumns перечисляет столбцы таблицы job_runs, соответствующие полям entities.JobRun.
+const jobRunColumns = "id, task_id, date, type, status, exit_code, status_code, stdout, stderr, error, queued_at, started_at, finished_at"
+
+// GetJob возвращает действие задачи taskId из таблицы jobs.
+// Возвращает sql.ErrNoRows, если у задачи нет действия.
+func (s *Storage) GetJob(taskId string) (entities.Job, error) {
+	var (
+		job   entities.Job
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + jobColumns + ` FROM jobs WHERE task_id = $1`
+	} else {
+		query = `SELECT ` + jobColumns + ` FROM jobs WHERE task_id = ?`
+	}
+
+	err := s.db.Get(&job, query, taskId)
+
+	return job, err
+}
+
+// GetJobs возвращает действия всех задач, не находящихся в корзине, из таблицы jobs.
+func (s *Storage) GetJobs() ([]entities.Job, error) {
+	jobs := []entities.Job{}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs
+	          WHERE task_id IN (SELECT id FROM scheduler WHERE deleted_at IS NULL)
+	          ORDER BY task_id`
+
+	err := s.db.Select(&jobs, query)
+
+	return jobs, err
+}
+
+// SetJob добавляет действие задачи в таблицу jobs или заменяет существующее.
+func (s *Storage) SetJob(job entities.Job) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `INSERT INTO jobs (task_id, type, command, method, url, headers, body, timeout_seconds)
+		         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		         ON CONFLICT (task_id) DO UPDATE SET type = excluded.type, command = excluded.command,
+		         method = excluded.method, url = excluded.url, headers = excluded.headers,
+		         body = excluded.body, timeout_seconds = excluded.timeout_seconds`
+	} else {
+		query = `INSERT INTO jobs (task_id, type, command, method, url, headers, body, timeout_seconds)
+		         VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		         ON CONFLICT (task_id) DO UPDATE SET type = excluded.type, command = excluded.command,
+		         method = excluded.method, url = excluded.url, headers = excluded.headers,
+		         body = excluded.body, timeout_seconds = excluded.timeout_seconds`
+	}
+
+	_, err := s.db.Exec(query, job.TaskId, job.Type, job.Command, job.Method, job.Url, job.Headers, job.Body, job.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteJob удаляет действие задачи taskId. Журнал запусков действия сохраняется.
+// Возвращает sql.ErrNoRows, если у задачи нет действия.
+func (s *Storage) DeleteJob(taskId string) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `DELETE FROM jobs WHERE task_id = $1`
+	} else {
+		query = `DELETE FROM jobs WHERE task_id = ?`
+	}
+
+	res, err := s.db.Exec(query, taskId)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// AddJobRun добавляет запись в журнал запусков job_runs и возвращает ее id.
+func (s *Storage) AddJobRun(run entities.JobRun) (string, error) {
+	var id int
+
+	args := []any{run.TaskId, run.Date, run.Type, run.Status, run.ExitCode, run.StatusCode,
+		run.Stdout, run.Stderr, run.Error, run.QueuedAt, run.StartedAt, run.FinishedAt}
+
+	if config.Mode == "postgres" {
+		query := `INSERT INTO job_runs (task_id, date, type, status, exit_code, status_code, stdout, stderr,
+		          error, queued_at, started_at, finished_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
+		if err := s.db.Get(&id, query, args...); err != nil {
+			return "", fmt.Errorf("failed to insert job run: %w", err)
+		}
+	} else {
+		query := `INSERT INTO job_runs (task_id, date, type, status, exit_code, status_code, stdout, stderr,
+		          error, queued_at, started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		res, err := s.db.Exec(query, args...)
+		if err != nil {
+			return "", fmt.Errorf("failed to insert job run: %w", err)
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return "", fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+
+		id = int(lastId)
+	}
+
+	return fmt.Sprint(id), nil
+}
+
+// UpdateJobRun сохраняет состояние и результат запуска в журнал запусков.
+func (s *Storage) UpdateJobRun(run entities.JobRun) error {
+	var query string
+
+	if config.Mode == "postgres" {
+		query = `UPDATE job_runs SET status = $1, exit_code = $2, status_code = $3, stdout = $4, stderr = $5,
+		         error = $6, started_at = $7, finished_at = $8 WHERE id = $9`
+	} else {
+		query = `UPDATE job_runs SET status = ?, exit_code = ?, status_code = ?, stdout = ?, stderr = ?,
+		         error = ?, started_at = ?, finished_at = ? WHERE id = ?`
+	}
+
+	_, err := s.db.Exec(query, run.Status, run.ExitCode, run.StatusCode, run.Stdout, run.Stderr,
+		run.Error, run.StartedAt, run.FinishedAt, run.Id)
+	if err != nil {
+		return fmt.Errorf("failed to update job run: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobRuns возвращает не более limit последних записей журнала запусков действия задачи taskId.
+func (s *Storage) GetJobRuns(taskId string, limit int) ([]entities.JobRun, error) {
+	var (
+		runs  = []entities.JobRun{}
+		query string
+	)
+
+	if config.Mode == "postgres" {
+		query = `SELECT ` + jobRunColumns + ` FROM job_runs WHERE task_id = $1 ORDER BY id DESC LIMIT $2`
+	} else {
+		query = `SELECT ` + jobRunColumns + ` FROM job_runs WHERE task_id = ? ORDER BY id DESC LIMIT ?`
+	}
+
+	err := s.db.Select(&runs, query, taskId, limit)
+
+	return runs, err
 }
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"context"
+	"io"
 	"task_scheduler/internal/entities"
+	"time"
 )
 
 type StorageInterface interface {
@@ -9,6 +12,84 @@ type StorageInterface interface {
 	GetTasks() ([]entities.Task, error)
 	SearchTasks(target string) ([]entities.Task, error)
 	SearchTask(id string) (entities.Task, error)
+	GetTasksByPeriod(from, to string) ([]entities.Task, error)
+	GetTasksBefore(date string) ([]entities.Task, error)
 	UpdateTask(task entities.Task) error
-	DeleteTask(id string) error
+	DeleteTask(id string, version int) error
+	GetTrash() ([]entities.TrashedTask, error)
+	RestoreTask(id string) error
+	PurgeTask(id string) error
+	PurgeTrash(before time.Time) (int64, error)
+	AddCompletion(completion entities.Completion) error
+	DeleteCompletion(taskId, date string) error
+	GetCompletions(target string, from, to int64) ([]entities.Completion, error)
+	AddRevision(revision entities.Revision) error
+	GetRevisions(taskId string) ([]entities.Revision, error)
+	GetRevision(id string) (entities.Revision, error)
+	GetAllRevisions() ([]entities.Revision, error)
+	ReplaceFeedToken(tokenHash string, createdAt int64) error
+	FeedTokenExists(tokenHash string) (bool, error)
+	GetTaskIdByUID(uid string) (string, error)
+	SetTaskUID(uid, taskId string) error
+	GetCalDAVResources() ([]entities.CalDAVResource, error)
+	SetCalDAVResource(resource entities.CalDAVResource) error
+	DeleteCalDAVResource(taskId string) error
+	GetReminders(taskId string) ([]entities.Reminder, error)
+	GetAllReminders() ([]entities.Reminder, error)
+	ReplaceReminders(taskId string, reminders []entities.Reminder) error
+	GetPendingReminders(before int64) ([]entities.Reminder, error)
+	GetJobs() ([]entities.Job, error)
+	GetInstanceId() (string, error)
+}
+
+type BackupInterface interface {
+	Backup(w io.Writer) error
+	Restore(r io.Reader) error
+}
+
+type DispatchInterface interface {
+	GetDueTasks(date, event string) ([]entities.Task, error)
+	MarkFired(taskId, date, event string, firedAt int64) (bool, error)
+}
+
+type ReminderInterface interface {
+	GetPendingReminders(before int64) ([]entities.Reminder, error)
+	MarkReminderFired(id string, firedAt int64) (bool, error)
+}
+
+type WebhookInterface interface {
+	GetWebhooks() ([]entities.Webhook, error)
+	GetWebhook(id string) (entities.Webhook, error)
+	AddWebhook(webhook entities.Webhook) (string, error)
+	DeleteWebhook(id string) error
+	AddDelivery(delivery entities.Delivery) (string, error)
+	GetDelivery(id string) (entities.Delivery, error)
+	GetDeliveries(webhookId string, limit int) ([]entities.Delivery, error)
+	GetPendingDeliveries(before int64, limit int) ([]entities.Delivery, error)
+	UpdateDelivery(delivery entities.Delivery) error
+}
+
+type JobInterface interface {
+	SearchTask(id string) (entities.Task, error)
+	GetJob(taskId string) (entities.Job, error)
+	SetJob(job entities.Job) error
+	DeleteJob(taskId string) error
+	AddJobRun(run entities.JobRun) (string, error)
+	UpdateJobRun(run entities.JobRun) error
+	GetJobRuns(taskId string, limit int) ([]entities.JobRun, error)
+}
+
+// Locker является блокировкой, которую одновременно удерживает только один экземпляр сервиса.
+type Locker interface {
+	// TryLock пытается захватить блокировку без ожидания и сообщает, удалось ли это.
+	TryLock(ctx context.Context) (bool, error)
+	// Check возвращает ошибку, если захваченная блокировка больше не удерживается.
+	Check(ctx context.Context) error
+	// Unlock освобождает блокировку.
+	Unlock() error
+}
+
+type EventBusInterface interface {
+	PublishEvent(channel string, payload []byte) error
+	ListenEvents(ctx context.Context, channel string, onListen func(), handle func(payload []byte)) error
 }
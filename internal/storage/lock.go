@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNotLocked возвращается при проверке блокировки, которая не захвачена.
+var ErrNotLocked = errors.New("the lock is not held")
+
+// AdvisoryLock является сеансовой рекомендательной блокировкой PostgreSQL с ключом key.
+// Блокировка удерживается отдельным соединением из пула и освобождается сервером
+// при разрыве этого соединения, в том числе при аварийном завершении экземпляра.
+type AdvisoryLock struct {
+	db   *sqlx.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func NewAdvisoryLock(db *sqlx.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryLock пытается захватить блокировку функцией pg_try_advisory_lock.
+func (l *AdvisoryLock) TryLock(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&locked); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+
+	return true, nil
+}
+
+// Check проверяет, что соединение, удерживающее блокировку, не разорвано.
+// Если соединение разорвано, то блокировка считается потерянной.
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	if l.conn == nil {
+		return ErrNotLocked
+	}
+
+	if _, err := l.conn.ExecContext(ctx, `SELECT 1`); err != nil {
+		l.conn.Close()
+		l.conn = nil
+
+		return fmt.Errorf("the advisory lock is lost: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock освобождает блокировку и возвращает соединение в пул.
+func (l *AdvisoryLock) Unlock() error {
+	if l.conn == nil {
+		return nil
+	}
+
+	defer func() {
+		l.conn.Close()
+		l.conn = nil
+	}()
+
+	_, err := l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, l.key)
+
+	return err
+}
+
+// FileLock является исключительной блокировкой файла path. Операционная система
+// освобождает блокировку при завершении процесса, в том числе аварийном.
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// TryLock пытается захватить блокировку файла, создавая файл при необходимости.
+func (l *FileLock) TryLock(_ context.Context) (bool, error) {
+	if l.file != nil {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+
+	locked, err := lockFile(file)
+	if err != nil || !locked {
+		file.Close()
+		return false, err
+	}
+
+	l.file = file
+
+	return true, nil
+}
+
+// Check проверяет, что блокировка захвачена. Захваченная блокировка файла
+// удерживается до ее освобождения или завершения процесса.
+func (l *FileLock) Check(_ context.Context) error {
+	if l.file == nil {
+		return ErrNotLocked
+	}
+
+	return nil
+}
+
+// Unlock освобождает блокировку файла.
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	l.file = nil
+
+	return err
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// lockFile считает блокировку файла захваченной: на системах без flock
+// с одной БД SQLite должен работать только один экземпляр сервиса.
+func lockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile ничего не делает на системах без flock.
+func unlockFile(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MigrationReport содержит количество строк каждой из таблиц, перенесенных при миграции.
+type MigrationReport struct {
+	Source map[string]int64
+	Target map[string]int64
+	DryRun bool
+}
+
+// Migrate переносит задачи и связанные с ними таблицы из БД src в БД dst с сохранением
+// идентификаторов. Строки читаются из src в одной транзакции только для чтения и построчно
+// записываются в dst в одной транзакции, после чего количество строк сверяется.
+// Если таблицы dst не пусты, то миграция выполняется только при replace, при этом их
+// содержимое заменяется. При dryRun транзакция dst откатывается после проверки.
+func Migrate(src, dst *Storage, replace, dryRun bool) (MigrationReport, error) {
+	report := MigrationReport{DryRun: dryRun}
+
+	srcTx, err := src.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return report, err
+	}
+	defer srcTx.Rollback()
+
+	if report.Source, err = countTables(srcTx); err != nil {
+		return report, err
+	}
+
+	dstTx, err := dst.db.Beginx()
+	if err != nil {
+		return report, err
+	}
+	defer dstTx.Rollback()
+
+	existing, err := countTables(dstTx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, name := range backupTables {
+		if existing[name] > 0 && !replace {
+			return report, fmt.Errorf("table %q in the target database is not empty", name)
+		}
+	}
+
+	for _, name := range backupTables {
+		if _, ok := report.Source[name]; !ok {
+			continue
+		}
+
+		if err := copyTable(srcTx, dstTx, name); err != nil {
+			return report, err
+		}
+	}
+
+	if report.Target, err = countTables(dstTx); err != nil {
+		return report, err
+	}
+
+	for table, expected := range report.Source {
+		if report.Target[table] != expected {
+			return report, fmt.Errorf("table %q has %d rows after migration, expected %d", table, report.Target[table], expected)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	return report, dstTx.Commit()
+}
+
+// copyTable построчно заменяет содержимое таблицы name в dst строками из src.
+func copyTable(src sqlx.Queryer, dst *sqlx.Tx, name string) error {
+	if _, err := dst.Exec(fmt.Sprintf("DELETE FROM %s", name)); err != nil {
+		return fmt.Errorf("failed to clear table %q: %w", name, err)
+	}
+
+	rows, err := src.Queryx(fmt.Sprintf("SELECT * FROM %s ORDER BY id", name))
+	if err != nil {
+		return fmt.Errorf("failed to read table %q: %w", name, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	query := dst.Rebind(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		name, strings.Join(columns, ", "), placeholders))
+
+	for rows.Next() {
+		row, err := rows.SliceScan()
+		if err != nil {
+			return err
+		}
+
+		for i, value := range row {
+			if data, ok := value.([]byte); ok {
+				row[i] = string(data)
+			}
+		}
+
+		if _, err := dst.Exec(query, row...); err != nil {
+			return fmt.Errorf("failed to copy table %q: %w", name, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return resetSequence(dst, name)
+}
+
+// resetSequence обновляет последовательность идентификаторов таблицы name после
+// вставки строк с явными идентификаторами в режиме "postgres". В режиме "sqlite"
+// счетчик AUTOINCREMENT обновляется автоматически.
+func resetSequence(tx *sqlx.Tx, name string) error {
+	if tx.DriverName() != "pgx" {
+		return nil
+	}
+
+	query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s`, name)
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("failed to reset sequence of table %q: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ErrListenUnsupported возвращается при попытке подписаться на сообщения не в режиме "postgres".
+var ErrListenUnsupported = errors.New("LISTEN is supported only in the postgres mode")
+
+// PublishEvent отправляет сообщение payload слушателям канала channel командой NOTIFY.
+func (s *Storage) PublishEvent(channel string, payload []byte) error {
+	if _, err := s.db.Exec(`SELECT pg_notify($1, $2)`, channel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// ListenEvents подписывается на канал channel командой LISTEN на отдельном соединении
+// и передает полученные сообщения в handle до отмены контекста ctx или разрыва
+// соединения. После успешной подписки вызывается onListen.
+func (s *Storage) ListenEvents(ctx context.Context, channel string, onListen func(), handle func(payload []byte)) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return ErrListenUnsupported
+		}
+
+		c := pgConn.Conn()
+
+		if _, err := c.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to listen for events: %w", err)
+		}
+
+		onListen()
+
+		var waitErr error
+		for {
+			notification, err := c.WaitForNotification(ctx)
+			if err != nil {
+				waitErr = err
+				break
+			}
+
+			handle([]byte(notification.Payload))
+		}
+
+		// Соединение возвращается в пул только после отмены подписки,
+		// иначе сообщения продолжат накапливаться в нем.
+		unlistenCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if _, err := c.Exec(unlistenCtx, "UNLISTEN *"); err != nil {
+			return errors.Join(waitErr, driver.ErrBadConn)
+		}
+
+		return waitErr
+	})
+}
@@ -5238,7 +5238,7 @@ var app = (function (t) {
     function v(t) {
       u.confirm(s.qdelete, () => {
         var e, n, l, i;
-        (e = "task?id=" + t.id),
+        (e = "task?id=" + t.id + "&version=" + t.version),
           (n = {}),
           (l = (t) => {
             h();